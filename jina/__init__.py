@@ -80,7 +80,7 @@ __version__ = '3.27.5'
 
 # do not change this line manually
 # this is managed by proto/build-proto.sh and updated on every execution
-__proto_version__ = '0.1.27'
+__proto_version__ = '0.1.28'
 
 try:
     __docarray_version__ = _docarray.__version__
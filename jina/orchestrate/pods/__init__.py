@@ -13,7 +13,7 @@ from jina.jaml import JAML
 from jina.logging.logger import JinaLogger
 from jina.orchestrate.pods.helper import ConditionalEvent, _get_event
 from jina.parsers.helper import _update_gateway_args
-from jina.serve.executors.run import run, run_raft
+from jina.serve.executors.run import _raft_executor_socket, run, run_raft
 from jina.constants import RAFT_TO_EXECUTOR_PORT
 
 __all__ = ['BasePod', 'Pod']
@@ -285,6 +285,7 @@ class Pod(BasePod):
                 daemon=True,
             )
             cargs = copy.deepcopy(cargs_stateful)
+            cargs.executor_socket = _raft_executor_socket(cargs_stateful)
 
             if isinstance(cargs.port, int):
                 cargs.port += RAFT_TO_EXECUTOR_PORT
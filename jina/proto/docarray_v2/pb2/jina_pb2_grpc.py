@@ -732,6 +732,89 @@ class JinaExecutorSnapshotProgress(object):
         )
 
 
+class JinaExecutorSnapshotReadStub(object):
+    """*
+    jina gRPC service to read the file of a snapshot taken by the Executor Runtime, so that the file does not need to
+    be shared with the caller.
+    """
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.read_snapshot = channel.unary_stream(
+            '/jina.JinaExecutorSnapshotRead/read_snapshot',
+            request_serializer=jina__pb2.SnapshotId.SerializeToString,
+            response_deserializer=jina__pb2.SnapshotChunkProto.FromString,
+        )
+
+
+class JinaExecutorSnapshotReadServicer(object):
+    """*
+    jina gRPC service to read the file of a snapshot taken by the Executor Runtime, so that the file does not need to
+    be shared with the caller.
+    """
+
+    def read_snapshot(self, request, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_JinaExecutorSnapshotReadServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+        'read_snapshot': grpc.unary_stream_rpc_method_handler(
+            servicer.read_snapshot,
+            request_deserializer=jina__pb2.SnapshotId.FromString,
+            response_serializer=jina__pb2.SnapshotChunkProto.SerializeToString,
+        ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+        'jina.JinaExecutorSnapshotRead', rpc_method_handlers
+    )
+    server.add_generic_rpc_handlers((generic_handler,))
+
+
+# This class is part of an EXPERIMENTAL API.
+class JinaExecutorSnapshotRead(object):
+    """*
+    jina gRPC service to read the file of a snapshot taken by the Executor Runtime, so that the file does not need to
+    be shared with the caller.
+    """
+
+    @staticmethod
+    def read_snapshot(
+        request,
+        target,
+        options=(),
+        channel_credentials=None,
+        call_credentials=None,
+        insecure=False,
+        compression=None,
+        wait_for_ready=None,
+        timeout=None,
+        metadata=None,
+    ):
+        return grpc.experimental.unary_stream(
+            request,
+            target,
+            '/jina.JinaExecutorSnapshotRead/read_snapshot',
+            jina__pb2.SnapshotId.SerializeToString,
+            jina__pb2.SnapshotChunkProto.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+        )
+
+
 class JinaExecutorRestoreStub(object):
     """*
     jina gRPC service to trigger a restore at the Executor Runtime.
@@ -812,6 +895,89 @@ class JinaExecutorRestore(object):
         )
 
 
+class JinaExecutorRestoreStreamStub(object):
+    """*
+    jina gRPC service to restore the Executor Runtime from a snapshot streamed in chunks, so that the snapshot file does
+    not need to be shared with the caller. The Executor sends the response headers as soon as it accepts the stream.
+    """
+
+    def __init__(self, channel):
+        """Constructor.
+
+        Args:
+            channel: A grpc.Channel.
+        """
+        self.restore_stream = channel.stream_unary(
+            '/jina.JinaExecutorRestoreStream/restore_stream',
+            request_serializer=jina__pb2.SnapshotChunkProto.SerializeToString,
+            response_deserializer=jina__pb2.RestoreSnapshotStatusProto.FromString,
+        )
+
+
+class JinaExecutorRestoreStreamServicer(object):
+    """*
+    jina gRPC service to restore the Executor Runtime from a snapshot streamed in chunks, so that the snapshot file does
+    not need to be shared with the caller. The Executor sends the response headers as soon as it accepts the stream.
+    """
+
+    def restore_stream(self, request_iterator, context):
+        """Missing associated documentation comment in .proto file."""
+        context.set_code(grpc.StatusCode.UNIMPLEMENTED)
+        context.set_details('Method not implemented!')
+        raise NotImplementedError('Method not implemented!')
+
+
+def add_JinaExecutorRestoreStreamServicer_to_server(servicer, server):
+    rpc_method_handlers = {
+        'restore_stream': grpc.stream_unary_rpc_method_handler(
+            servicer.restore_stream,
+            request_deserializer=jina__pb2.SnapshotChunkProto.FromString,
+            response_serializer=jina__pb2.RestoreSnapshotStatusProto.SerializeToString,
+        ),
+    }
+    generic_handler = grpc.method_handlers_generic_handler(
+        'jina.JinaExecutorRestoreStream', rpc_method_handlers
+    )
+    server.add_generic_rpc_handlers((generic_handler,))
+
+
+# This class is part of an EXPERIMENTAL API.
+class JinaExecutorRestoreStream(object):
+    """*
+    jina gRPC service to restore the Executor Runtime from a snapshot streamed in chunks, so that the snapshot file does
+    not need to be shared with the caller. The Executor sends the response headers as soon as it accepts the stream.
+    """
+
+    @staticmethod
+    def restore_stream(
+        request_iterator,
+        target,
+        options=(),
+        channel_credentials=None,
+        call_credentials=None,
+        insecure=False,
+        compression=None,
+        wait_for_ready=None,
+        timeout=None,
+        metadata=None,
+    ):
+        return grpc.experimental.stream_unary(
+            request_iterator,
+            target,
+            '/jina.JinaExecutorRestoreStream/restore_stream',
+            jina__pb2.SnapshotChunkProto.SerializeToString,
+            jina__pb2.RestoreSnapshotStatusProto.FromString,
+            options,
+            channel_credentials,
+            insecure,
+            call_credentials,
+            compression,
+            wait_for_ready,
+            timeout,
+            metadata,
+        )
+
+
 class JinaExecutorRestoreProgressStub(object):
     """*
     jina gRPC service to trigger a snapshot at the Executor Runtime.
@@ -206,6 +206,31 @@ class SnapshotStatusProto:
         return sp
 
 
+class SnapshotChunkProto:
+    """Placeholder that delegates the serialization and deserialization to the internal protobuf"""
+
+    @staticmethod
+    def SerializeToString(x):
+        """
+        # noqa: DAR101
+        # noqa: DAR102
+        # noqa: DAR201
+        """
+        return x.SerializeToString()
+
+    @staticmethod
+    def FromString(x: bytes):
+        """
+        # noqa: DAR101
+        # noqa: DAR102
+        # noqa: DAR201
+        """
+        sc = jina_pb2.SnapshotChunkProto()
+        sc.ParseFromString(x)
+
+        return sc
+
+
 class RestoreSnapshotCommand:
     """Placeholder that delegates the serialization and deserialization to the internal protobuf"""
 
@@ -2,14 +2,15 @@ package main
 
 import (
     "context"
+    "fmt"
     "os"
-    "errors"
 
     pb "github.com/Jille/raftadmin/proto"
     "google.golang.org/grpc"
     "google.golang.org/protobuf/encoding/prototext"
     "google.golang.org/protobuf/reflect/protoreflect"
     hclog "github.com/hashicorp/go-hclog"
+    jinaraft "jraft/jina_raft"
 )
 
 func AddVoter(target string, id string, voter_address string) error {
@@ -38,9 +39,18 @@ func AddVoter(target string, id string, voter_address string) error {
                   PreviousIndex: 0,
               }
 
-    // Connect and send the RPC.
-    var o grpc.DialOption = grpc.EmptyDialOption{}
-    conn, err := grpc.Dial(target, grpc.WithInsecure(), grpc.WithBlock(), o)
+    // Connect and send the RPC, with the RAFT TLS and token settings of the JINA_RAFT_* environment variables
+    opts, err := jinaraft.LoadOptions("", nil)
+    if err != nil {
+        add_voter_logger.Error("Error loading the RAFT TLS settings:", "error", err)
+        return err
+    }
+    o, err := opts.AdminDialOptions()
+    if err != nil {
+        add_voter_logger.Error("Error loading the RAFT TLS settings:", "error", err)
+        return err
+    }
+    conn, err := grpc.Dial(target, append(o, grpc.WithBlock())...)
     if err != nil {
         add_voter_logger.Error("Error dialing:", "error", err)
         return err
@@ -60,20 +70,20 @@ func AddVoter(target string, id string, voter_address string) error {
         c := pb.NewRaftAdminClient(conn)
         add_voter_logger.Debug("Awaiting for response")
         resp, err := c.Await(ctx, f)
-        add_voter_logger.Debug("Response from AddVoter:", "Response", prototext.Format(resp))
-
-        if resp.Error != "" {
-            // handle error
-            add_voter_logger.Error("Error in AddVoter Response:", "error", resp.Error)
-            return errors.New("Error in AddVoter Response: target is not the leader")
-        }
         if err != nil {
+            // resp is nil when Await fails
             add_voter_logger.Error("Error from AddVoter:", "error", err)
             return err
         }
+        add_voter_logger.Debug("Response from AddVoter:", "Response", prototext.Format(resp))
+        // the future is done, free it on the server even when AddVoter failed
         if _, err := c.Forget(ctx, f); err != nil {
-            add_voter_logger.Error("Returning error", "error", err)
-            return err
+            add_voter_logger.Warn("Error forgetting the AddVoter future", "error", err)
+        }
+        if resp.Error != "" {
+            // e.g. the target is not the leader, or the configuration changed since PreviousIndex
+            add_voter_logger.Error("Error in AddVoter Response:", "error", resp.Error)
+            return fmt.Errorf("error in AddVoter response: %s", resp.Error)
         }
     }
     return nil
@@ -1,11 +1,61 @@
+import os
+
 import grpc
 from jina.serve.consensus.add_voter.add_voter_pb2_grpc import RaftAdminStub
 from jina.serve.consensus.add_voter.add_voter_pb2 import AddVoterRequest
 
 
+def _read_file(path):
+    if not path:
+        return None
+    with open(path, 'rb') as f:
+        return f.read()
+
+
+def _channel_credentials():
+    """Return the credentials to reach the RAFT members, following the node settings of the
+    JINA_RAFT_* environment variables, or None for an insecure channel.
+
+    :return: the channel credentials or None
+    """
+    prefix = (
+        'JINA_RAFT_RAFT_TLS_' if os.getenv('JINA_RAFT_RAFT_ADDRESS') else 'JINA_RAFT_TLS_'
+    )
+    cert_file = os.getenv(prefix + 'CERT_FILE')
+    key_file = os.getenv(prefix + 'KEY_FILE')
+    ca_file = os.getenv(prefix + 'CA_FILE')
+    if not (cert_file or key_file or ca_file):
+        return None
+    return grpc.ssl_channel_credentials(
+        root_certificates=_read_file(ca_file),
+        private_key=_read_file(key_file),
+        certificate_chain=_read_file(cert_file),
+    )
+
+
+def _metadata():
+    """Return the bearer token of JINA_RAFT_AUTH_TOKEN_FILE as call metadata, if set.
+
+    :return: the call metadata or None
+    """
+    token = _read_file(os.getenv('JINA_RAFT_AUTH_TOKEN_FILE'))
+    if not token:
+        return None
+    return (('authorization', f'Bearer {token.decode().strip()}'),)
+
+
+def _channel(target, aio=False):
+    credentials = _channel_credentials()
+    channel_module = grpc.aio if aio else grpc
+    if credentials is None:
+        return channel_module.insecure_channel(target)
+    return channel_module.secure_channel(target, credentials)
+
+
 def call_add_voter(target, replica_id, voter_address):
-    with grpc.insecure_channel(target) as channel:
+    with _channel(target) as channel:
         stub = RaftAdminStub(channel)
+        metadata = _metadata()
 
         req = AddVoterRequest(
             id=replica_id,
@@ -14,9 +64,9 @@ def call_add_voter(target, replica_id, voter_address):
         )
 
         try:
-            future = stub.AddVoter(req)
-            add_voter_result = stub.Await(future)
-            _ = stub.Forget(future)
+            future = stub.AddVoter(req, metadata=metadata)
+            add_voter_result = stub.Await(future, metadata=metadata)
+            _ = stub.Forget(future, metadata=metadata)
             if not add_voter_result.error:
                 return True
             else:
@@ -26,8 +76,9 @@ def call_add_voter(target, replica_id, voter_address):
 
 
 async def async_call_add_voter(target, replica_id, voter_address):
-    async with grpc.aio.insecure_channel(target) as channel:
+    async with _channel(target, aio=True) as channel:
         stub = RaftAdminStub(channel)
+        metadata = _metadata()
 
         req = AddVoterRequest(
             id=replica_id,
@@ -36,9 +87,9 @@ async def async_call_add_voter(target, replica_id, voter_address):
         )
 
         try:
-            future = await stub.AddVoter(req)
-            add_voter_result = await stub.Await(future)
-            _ = await stub.Forget(future)
+            future = await stub.AddVoter(req, metadata=metadata)
+            add_voter_result = await stub.Await(future, metadata=metadata)
+            _ = await stub.Forget(future, metadata=metadata)
             if not add_voter_result.error:
                 return True
             else:
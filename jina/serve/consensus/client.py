@@ -0,0 +1,220 @@
+import asyncio
+import json
+import os
+import socket
+from typing import List, Optional
+
+import grpc
+from google.rpc import error_details_pb2
+from grpc_status import rpc_status
+
+from jina.proto import jina_pb2, jina_pb2_grpc
+from jina.serve.consensus.add_voter.call_add_voter import _read_file
+
+# health services reported by the RAFT nodes, see jina_raft/health.go
+LEADER_SERVICE = 'quis.RaftLeader'
+FOLLOWER_SERVICE = 'raft-follower'
+
+# reasons of the writes that failed before being appended to the RAFT log, see jina_raft/errors.go
+_ERROR_DOMAIN = 'jraft.jina.ai'
+_RETRIABLE_REASONS = frozenset(
+    {'NOT_LEADER', 'NO_LEADER', 'DRAINING', 'SHUTDOWN', 'ENQUEUE_TIMEOUT'}
+)
+
+
+def _retry_delay(err):
+    """Whether the node reported that a write failed before being appended to its log, and when to retry it.
+    Other errors, such as a transport failure after the request was sent or a leadership lost before the
+    write was committed, may hide an applied write and are not retried.
+
+    :param err: the error of the write
+    :return: a tuple of True if the write can be sent again, and the seconds of the RetryInfo or None
+    """
+    try:
+        status = rpc_status.from_call(err)
+    except ValueError:
+        return False, None
+    if status is None:
+        return False, None
+    retriable, delay = False, None
+    for detail in status.details:
+        if detail.Is(error_details_pb2.ErrorInfo.DESCRIPTOR):
+            info = error_details_pb2.ErrorInfo()
+            detail.Unpack(info)
+            retriable = (
+                info.domain == _ERROR_DOMAIN and info.reason in _RETRIABLE_REASONS
+            )
+        elif detail.Is(error_details_pb2.RetryInfo.DESCRIPTOR):
+            retry = error_details_pb2.RetryInfo()
+            detail.Unpack(retry)
+            delay = retry.retry_delay.ToTimedelta().total_seconds()
+    return retriable, delay
+
+
+def _service_config(service_name):
+    """Balance the calls across the replicas reporting `service_name` as SERVING.
+
+    :param service_name: the health service checked on every replica
+    :return: the gRPC service config as JSON
+    """
+    return json.dumps(
+        {
+            'healthCheckConfig': {'serviceName': service_name},
+            'loadBalancingConfig': [{'round_robin': {}}],
+        }
+    )
+
+
+def _resolve_targets(targets):
+    """Resolve the host+port `targets` into a single gRPC target listing every replica.
+
+    :param targets: the host+port addresses of the replicas
+    :return: an `ipv4:` or `ipv6:` target with the resolved addresses
+    """
+    addresses = []
+    families = set()
+    for target in targets:
+        host, _, port = target.rpartition(':')
+        host = host.strip('[]')
+        family, _, _, _, sockaddr = socket.getaddrinfo(
+            host, int(port), type=socket.SOCK_STREAM
+        )[0]
+        families.add(family)
+        if family == socket.AF_INET6:
+            addresses.append(f'[{sockaddr[0]}]:{port}')
+        else:
+            addresses.append(f'{sockaddr[0]}:{port}')
+    if len(families) > 1:
+        raise ValueError(f'cannot mix IPv4 and IPv6 replicas: {targets}')
+    scheme = 'ipv6' if families == {socket.AF_INET6} else 'ipv4'
+    return f'{scheme}:{",".join(addresses)}'
+
+
+def _client_credentials():
+    """Return the credentials to reach the client address of the RAFT nodes, following the
+    JINA_RAFT_TLS_* environment variables, or None for an insecure channel.
+
+    :return: the channel credentials or None
+    """
+    cert_file = os.getenv('JINA_RAFT_TLS_CERT_FILE')
+    key_file = os.getenv('JINA_RAFT_TLS_KEY_FILE')
+    ca_file = os.getenv('JINA_RAFT_TLS_CA_FILE')
+    if not (cert_file or key_file or ca_file):
+        return None
+    return grpc.ssl_channel_credentials(
+        root_certificates=_read_file(ca_file),
+        private_key=_read_file(key_file),
+        certificate_chain=_read_file(cert_file),
+    )
+
+
+def _channel(target, service_name):
+    options = [('grpc.service_config', _service_config(service_name))]
+    credentials = _client_credentials()
+    if credentials is None:
+        return grpc.aio.insecure_channel(target, options=options)
+    return grpc.aio.secure_channel(target, credentials, options=options)
+
+
+class RaftClient:
+    """Send data requests to every replica of a stateful Deployment. Writes go to the RAFT leader and are
+    retried while a new leader is elected, reads are spread across the followers that caught up with the
+    leader, or sent to the leader when none is. Both are chosen by client-side gRPC health checks.
+    """
+
+    def __init__(
+        self,
+        targets: List[str],
+        write_endpoints: Optional[List[str]] = None,
+        write_attempts: int = 5,
+        write_backoff: float = 0.1,
+    ):
+        """
+        :param targets: the host+port addresses of the replicas
+        :param write_endpoints: the endpoints replicated through the RAFT log, discovered from the leader if not given
+        :param write_attempts: the number of times a write is sent while no leader is reachable
+        :param write_backoff: the seconds between the first two attempts of a write, doubled after each one
+        """
+        target = _resolve_targets(targets)
+        self._leader_channel = _channel(target, LEADER_SERVICE)
+        self._followers_channel = _channel(target, FOLLOWER_SERVICE)
+        self._leader = jina_pb2_grpc.JinaSingleDataRequestRPCStub(self._leader_channel)
+        self._followers = jina_pb2_grpc.JinaSingleDataRequestRPCStub(
+            self._followers_channel
+        )
+        self._write_endpoints = (
+            set(write_endpoints) if write_endpoints is not None else None
+        )
+        self._write_attempts = write_attempts
+        self._write_backoff = write_backoff
+
+    async def _is_write(self, endpoint):
+        if self._write_endpoints is None:
+            stub = jina_pb2_grpc.JinaDiscoverEndpointsRPCStub(self._leader_channel)
+            endpoints = await stub.endpoint_discovery(
+                jina_pb2.google_dot_protobuf_dot_empty__pb2.Empty(),
+                wait_for_ready=True,
+            )
+            self._write_endpoints = set(endpoints.write_endpoints)
+        return endpoint in self._write_endpoints
+
+    async def process_single_data(self, request, timeout: Optional[float] = None):
+        """Send `request` to the leader if its endpoint is a write endpoint, to a follower otherwise.
+
+        :param request: the DataRequestProto to send
+        :param timeout: the deadline of the call in seconds, including the retries
+        :return: the DataRequestProto returned by the replica
+        """
+        if await self._is_write(request.header.exec_endpoint):
+            return await self._write(request, timeout)
+        return await self._read(request, timeout)
+
+    async def _write(self, request, timeout):
+        loop = asyncio.get_running_loop()
+        # the attempts share the deadline of the call
+        deadline = None if timeout is None else loop.time() + timeout
+        backoff = self._write_backoff
+        for attempt in range(1, self._write_attempts + 1):
+            remaining = None if deadline is None else max(deadline - loop.time(), 0)
+            try:
+                return await self._leader.process_single_data(
+                    request, timeout=remaining, wait_for_ready=True
+                )
+            except grpc.aio.AioRpcError as err:
+                # not the leader, no leader, draining or pushing back: nothing was applied
+                retriable, delay = _retry_delay(err)
+                if not retriable or attempt == self._write_attempts:
+                    raise
+                if delay is None:
+                    delay = backoff
+                if deadline is not None and loop.time() + delay >= deadline:
+                    raise
+            await asyncio.sleep(delay)
+            backoff *= 2
+
+    async def _read(self, request, timeout):
+        loop = asyncio.get_running_loop()
+        deadline = None if timeout is None else loop.time() + timeout
+        try:
+            # fail fast when no follower is ready, the leader serves the read instead
+            return await self._followers.process_single_data(
+                request, timeout=timeout, wait_for_ready=False
+            )
+        except grpc.aio.AioRpcError as err:
+            if err.code() != grpc.StatusCode.UNAVAILABLE:
+                raise
+        remaining = None if deadline is None else max(deadline - loop.time(), 0)
+        return await self._leader.process_single_data(
+            request, timeout=remaining, wait_for_ready=True
+        )
+
+    async def close(self):
+        """Close the channels to the replicas."""
+        await self._leader_channel.close()
+        await self._followers_channel.close()
+
+    async def __aenter__(self):
+        return self
+
+    async def __aexit__(self, exc_type, exc_val, exc_tb):
+        await self.close()
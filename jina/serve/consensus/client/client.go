@@ -0,0 +1,189 @@
+// Package client connects to every replica of a Jina consensus cluster and routes the data requests:
+// writes to the Raft leader, reads across the followers that caught up with it. Both are chosen by
+// client-side gRPC health checks against the services reported by the nodes.
+package client
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    _ "google.golang.org/grpc/health"
+    "google.golang.org/grpc/resolver"
+    "google.golang.org/grpc/resolver/manual"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/emptypb"
+    pb "jraft/jina-go-proto"
+    jinaraft "jraft/jina_raft"
+)
+
+// scheme of the resolver listing the replicas, local to each connection.
+const scheme = "jraft"
+
+// DefaultWriteAttempts is the number of times a write is sent before giving up, while a new leader is elected.
+const DefaultWriteAttempts = 5
+
+// serviceConfig balances the calls across the replicas reporting serviceName as SERVING.
+func serviceConfig(serviceName string) string {
+    return fmt.Sprintf(`{"healthCheckConfig": {"serviceName": %q}, "loadBalancingConfig": [{"round_robin": {}}]}`, serviceName)
+}
+
+// Client sends data requests to a Jina consensus cluster.
+type Client struct {
+    leader         *grpc.ClientConn
+    followers      *grpc.ClientConn
+    writeEndpoints map[string]bool
+    // WriteAttempts is the number of times a write is sent while no leader is reachable, defaults to DefaultWriteAttempts
+    WriteAttempts  int
+    // WriteBackoff is the time between two attempts of a write, doubled after each one
+    WriteBackoff   time.Duration
+}
+
+// dial connects to the replicas reporting serviceName as SERVING.
+func dial(ctx context.Context, targets []string, serviceName string, opts []grpc.DialOption) (*grpc.ClientConn, error) {
+    r := manual.NewBuilderWithScheme(scheme)
+    addresses := make([]resolver.Address, len(targets))
+    for i, target := range targets {
+        addresses[i] = resolver.Address{Addr: target}
+    }
+    r.InitialState(resolver.State{Addresses: addresses})
+    opts = append([]grpc.DialOption{
+        grpc.WithResolvers(r),
+        grpc.WithDefaultServiceConfig(serviceConfig(serviceName)),
+    }, opts...)
+    return grpc.DialContext(ctx, scheme+":///replicas", opts...)
+}
+
+// Dial connects to the replicas at targets, their host+port address, and discovers the write endpoints of
+// the Executor from the leader. opts secure the connections, e.g. with the result of Options.RaftDialOption.
+func Dial(ctx context.Context, targets []string, opts ...grpc.DialOption) (*Client, error) {
+    if len(targets) == 0 {
+        return nil, errors.New("no replica to connect to")
+    }
+    leader, err := dial(ctx, targets, jinaraft.HealthQuisRaftLeader, opts)
+    if err != nil {
+        return nil, err
+    }
+    followers, err := dial(ctx, targets, jinaraft.HealthRaftFollower, opts)
+    if err != nil {
+        leader.Close()
+        return nil, err
+    }
+    c := &Client{
+        leader:        leader,
+        followers:     followers,
+        WriteAttempts: DefaultWriteAttempts,
+        WriteBackoff:  100 * time.Millisecond,
+    }
+    endpoints, err := pb.NewJinaDiscoverEndpointsRPCClient(leader).EndpointDiscovery(ctx, &emptypb.Empty{}, grpc.WaitForReady(true))
+    if err != nil {
+        c.Close()
+        return nil, fmt.Errorf("discovering the write endpoints: %v", err)
+    }
+    c.writeEndpoints = map[string]bool{}
+    for _, endpoint := range endpoints.WriteEndpoints {
+        c.writeEndpoints[endpoint] = true
+    }
+    return c, nil
+}
+
+// Leader is the connection to the current leader, for the calls that have to be handled by it.
+func (c *Client) Leader() *grpc.ClientConn {
+    return c.leader
+}
+
+// Followers is the connection balanced across the followers that caught up with the leader.
+func (c *Client) Followers() *grpc.ClientConn {
+    return c.followers
+}
+
+// IsWrite reports whether requests to endpoint are replicated through the Raft log.
+func (c *Client) IsWrite(endpoint string) bool {
+    return c.writeEndpoints[endpoint]
+}
+
+// ProcessSingleData sends a write request to the leader, retrying while a leader is elected or pushes back, and a read
+// request to a follower, or to the leader when no follower is ready.
+func (c *Client) ProcessSingleData(ctx context.Context, req *pb.DataRequestProto, opts ...grpc.CallOption) (*pb.DataRequestProto, error) {
+    if c.IsWrite(req.GetHeader().GetExecEndpoint()) {
+        return c.write(ctx, req, opts)
+    }
+    return c.read(ctx, req, opts)
+}
+
+func (c *Client) write(ctx context.Context, req *pb.DataRequestProto, opts []grpc.CallOption) (*pb.DataRequestProto, error) {
+    client := pb.NewJinaSingleDataRequestRPCClient(c.leader)
+    opts = append([]grpc.CallOption{grpc.WaitForReady(true)}, opts...)
+    backoff := c.WriteBackoff
+    var err error
+    for attempt := 1; ; attempt++ {
+        var resp *pb.DataRequestProto
+        resp, err = client.ProcessSingleData(ctx, req, opts...)
+        if !retriable(err) || attempt >= c.WriteAttempts {
+            return resp, err
+        }
+        delay := backoff
+        backoff *= 2
+        if retryAfter, ok := retryDelay(err); ok {
+            delay = retryAfter
+        }
+        select {
+        case <-time.After(delay):
+        case <-ctx.Done():
+            return nil, err
+        }
+    }
+}
+
+// retriable reports whether the node reported that a write failed before being appended to its log: it is not the
+// leader, no leader is known, it is draining or shut down, or its log is full. Other errors, such as a transport
+// failure after the request was sent or a leadership lost before the write was committed, may hide an applied write.
+func retriable(err error) bool {
+    switch errorReason(err) {
+    case jinaraft.ReasonNotLeader, jinaraft.ReasonNoLeader, jinaraft.ReasonDraining, jinaraft.ReasonShutdown, jinaraft.ReasonBackpressure:
+        return true
+    }
+    return false
+}
+
+// errorReason returns the reason of the ErrorInfo details attached by the node, if any.
+func errorReason(err error) string {
+    for _, detail := range status.Convert(err).Details() {
+        if info, ok := detail.(*errdetails.ErrorInfo); ok && info.Domain == jinaraft.ErrorDomain {
+            return info.Reason
+        }
+    }
+    return ""
+}
+
+// retryDelay returns the delay of the RetryInfo details attached by the node, if any.
+func retryDelay(err error) (time.Duration, bool) {
+    for _, detail := range status.Convert(err).Details() {
+        if info, ok := detail.(*errdetails.RetryInfo); ok && info.RetryDelay != nil {
+            return info.RetryDelay.AsDuration(), true
+        }
+    }
+    return 0, false
+}
+
+func (c *Client) read(ctx context.Context, req *pb.DataRequestProto, opts []grpc.CallOption) (*pb.DataRequestProto, error) {
+    // fail fast when no follower is ready, the leader serves the read instead
+    resp, err := pb.NewJinaSingleDataRequestRPCClient(c.followers).ProcessSingleData(ctx, req, append([]grpc.CallOption{grpc.WaitForReady(false)}, opts...)...)
+    if status.Code(err) != codes.Unavailable {
+        return resp, err
+    }
+    return pb.NewJinaSingleDataRequestRPCClient(c.leader).ProcessSingleData(ctx, req, append([]grpc.CallOption{grpc.WaitForReady(true)}, opts...)...)
+}
+
+// Close closes the connections to the replicas.
+func (c *Client) Close() error {
+    err := c.leader.Close()
+    if followersErr := c.followers.Close(); err == nil {
+        err = followersErr
+    }
+    return err
+}
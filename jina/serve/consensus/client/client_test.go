@@ -0,0 +1,62 @@
+package client
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/durationpb"
+    jinaraft "jraft/jina_raft"
+)
+
+// nodeError returns an error of the node with code, the ErrorInfo of reason and, if retryAfter is not negative, a
+// RetryInfo.
+func nodeError(t *testing.T, code codes.Code, domain string, reason string, retryAfter time.Duration) error {
+    t.Helper()
+    st := status.New(code, reason)
+    var err error
+    if retryAfter >= 0 {
+        st, err = st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: domain}, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+    } else {
+        st, err = st.WithDetails(&errdetails.ErrorInfo{Reason: reason, Domain: domain})
+    }
+    if err != nil {
+        t.Fatal(err)
+    }
+    return st.Err()
+}
+
+func TestRetriable(t *testing.T) {
+    tests := []struct {
+        name       string
+        err        error
+        retriable  bool
+        retryAfter time.Duration
+        hasDelay   bool
+    }{
+        {name: "not leader", err: nodeError(t, codes.FailedPrecondition, jinaraft.ErrorDomain, jinaraft.ReasonNotLeader, 0), retriable: true, hasDelay: true},
+        {name: "no leader", err: nodeError(t, codes.Unavailable, jinaraft.ErrorDomain, jinaraft.ReasonNoLeader, time.Second), retriable: true, retryAfter: time.Second, hasDelay: true},
+        {name: "draining", err: nodeError(t, codes.Unavailable, jinaraft.ErrorDomain, jinaraft.ReasonDraining, 100*time.Millisecond), retriable: true, retryAfter: 100 * time.Millisecond, hasDelay: true},
+        {name: "shutdown", err: nodeError(t, codes.Unavailable, jinaraft.ErrorDomain, jinaraft.ReasonShutdown, -1), retriable: true},
+        {name: "backpressure", err: nodeError(t, codes.ResourceExhausted, jinaraft.ErrorDomain, jinaraft.ReasonBackpressure, 100*time.Millisecond), retriable: true, retryAfter: 100 * time.Millisecond, hasDelay: true},
+        {name: "leadership lost", err: nodeError(t, codes.Aborted, jinaraft.ErrorDomain, jinaraft.ReasonLeadershipLost, -1)},
+        {name: "reason of another domain", err: nodeError(t, codes.Unavailable, "example.com", jinaraft.ReasonNoLeader, -1)},
+        {name: "transport failure", err: status.Error(codes.Unavailable, "connection reset")},
+        {name: "deadline", err: status.Error(codes.DeadlineExceeded, "deadline exceeded")},
+        {name: "executor error", err: status.Error(codes.Internal, "executor failed")},
+        {name: "not a status", err: errors.New("boom")},
+        {name: "success", err: nil},
+    }
+    for _, test := range tests {
+        if got := retriable(test.err); got != test.retriable {
+            t.Errorf("%s: retriable() = %v, want %v", test.name, got, test.retriable)
+        }
+        delay, ok := retryDelay(test.err)
+        if ok != test.hasDelay || delay != test.retryAfter {
+            t.Errorf("%s: retryDelay() = %v, %v, want %v, %v", test.name, delay, ok, test.retryAfter, test.hasDelay)
+        }
+    }
+}
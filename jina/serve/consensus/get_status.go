@@ -0,0 +1,55 @@
+package main
+
+import (
+    "context"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/protobuf/encoding/protojson"
+    "google.golang.org/protobuf/proto"
+    jinaraft "jraft/jina_raft"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+// getStatusTimeout bounds the status call, including the status calls the target makes to the other members.
+const getStatusTimeout = 10 * time.Second
+
+// dialAdmin connects to the node at target with the RAFT TLS and token settings of the JINA_RAFT_* environment
+// variables.
+func dialAdmin(ctx context.Context, target string) (*grpc.ClientConn, error) {
+    opts, err := jinaraft.LoadOptions("", nil)
+    if err != nil {
+        return nil, err
+    }
+    dialOptions, err := opts.AdminDialOptions()
+    if err != nil {
+        return nil, err
+    }
+    return grpc.DialContext(ctx, target, dialOptions...)
+}
+
+// marshalJSON returns message as JSON with the field names of jraft.proto.
+func marshalJSON(message proto.Message) (string, error) {
+    content, err := protojson.MarshalOptions{UseProtoNames: true, EmitUnpopulated: true}.Marshal(message)
+    if err != nil {
+        return "", err
+    }
+    return string(content), nil
+}
+
+// GetStatus asks the node at target for its status and the progress of the cluster, and returns it as JSON
+// with the field names of jraft.proto.
+func GetStatus(target string) (string, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), getStatusTimeout)
+    defer cancel()
+    conn, err := dialAdmin(ctx, target)
+    if err != nil {
+        return "", err
+    }
+    defer conn.Close()
+    status, err := jraftpb.NewJinaRaftStatusClient(conn).GetStatus(ctx, &jraftpb.StatusRequest{}, grpc.WaitForReady(true))
+    if err != nil {
+        return "", err
+    }
+    return marshalJSON(status)
+}
@@ -185,7 +185,7 @@ func (x RestoreSnapshotStatusProto_Status) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use RestoreSnapshotStatusProto_Status.Descriptor instead.
 func (RestoreSnapshotStatusProto_Status) EnumDescriptor() ([]byte, []int) {
-	return file_jina_proto_rawDescGZIP(), []int{13, 0}
+	return file_jina_proto_rawDescGZIP(), []int{14, 0}
 }
 
 // *
@@ -1036,6 +1036,84 @@ func (x *SnapshotStatusProto) GetSnapshotFile() string {
 	return ""
 }
 
+// *
+// A chunk of the file of a Snapshot. The chunks are streamed in order, the last one holds no data and carries the
+// digest of the whole file.
+type SnapshotChunkProto struct {
+	state         protoimpl.MessageState
+	sizeCache     protoimpl.SizeCache
+	unknownFields protoimpl.UnknownFields
+
+	// the bytes of the chunk
+	Data []byte `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// the position of the chunk in the snapshot file
+	Offset uint64 `protobuf:"varint,2,opt,name=offset,proto3" json:"offset,omitempty"`
+	// the CRC-32 (IEEE) checksum of data
+	Crc32 uint32 `protobuf:"fixed32,3,opt,name=crc32,proto3" json:"crc32,omitempty"`
+	// the SHA-256 digest of the snapshot file, only set on the last chunk
+	Sha256 []byte `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`
+}
+
+func (x *SnapshotChunkProto) Reset() {
+	*x = SnapshotChunkProto{}
+	if protoimpl.UnsafeEnabled {
+		mi := &file_jina_proto_msgTypes[13]
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		ms.StoreMessageInfo(mi)
+	}
+}
+
+func (x *SnapshotChunkProto) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SnapshotChunkProto) ProtoMessage() {}
+
+func (x *SnapshotChunkProto) ProtoReflect() protoreflect.Message {
+	mi := &file_jina_proto_msgTypes[13]
+	if protoimpl.UnsafeEnabled && x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SnapshotChunkProto.ProtoReflect.Descriptor instead.
+func (*SnapshotChunkProto) Descriptor() ([]byte, []int) {
+	return file_jina_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *SnapshotChunkProto) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *SnapshotChunkProto) GetOffset() uint64 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SnapshotChunkProto) GetCrc32() uint32 {
+	if x != nil {
+		return x.Crc32
+	}
+	return 0
+}
+
+func (x *SnapshotChunkProto) GetSha256() []byte {
+	if x != nil {
+		return x.Sha256
+	}
+	return nil
+}
+
 // *
 // Represents the status of a Restore.
 type RestoreSnapshotStatusProto struct {
@@ -1051,7 +1129,7 @@ type RestoreSnapshotStatusProto struct {
 func (x *RestoreSnapshotStatusProto) Reset() {
 	*x = RestoreSnapshotStatusProto{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_jina_proto_msgTypes[13]
+		mi := &file_jina_proto_msgTypes[14]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1064,7 +1142,7 @@ func (x *RestoreSnapshotStatusProto) String() string {
 func (*RestoreSnapshotStatusProto) ProtoMessage() {}
 
 func (x *RestoreSnapshotStatusProto) ProtoReflect() protoreflect.Message {
-	mi := &file_jina_proto_msgTypes[13]
+	mi := &file_jina_proto_msgTypes[14]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1077,7 +1155,7 @@ func (x *RestoreSnapshotStatusProto) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestoreSnapshotStatusProto.ProtoReflect.Descriptor instead.
 func (*RestoreSnapshotStatusProto) Descriptor() ([]byte, []int) {
-	return file_jina_proto_rawDescGZIP(), []int{13}
+	return file_jina_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *RestoreSnapshotStatusProto) GetId() *RestoreId {
@@ -1108,7 +1186,7 @@ type RestoreSnapshotCommand struct {
 func (x *RestoreSnapshotCommand) Reset() {
 	*x = RestoreSnapshotCommand{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_jina_proto_msgTypes[14]
+		mi := &file_jina_proto_msgTypes[15]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1121,7 +1199,7 @@ func (x *RestoreSnapshotCommand) String() string {
 func (*RestoreSnapshotCommand) ProtoMessage() {}
 
 func (x *RestoreSnapshotCommand) ProtoReflect() protoreflect.Message {
-	mi := &file_jina_proto_msgTypes[14]
+	mi := &file_jina_proto_msgTypes[15]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1134,7 +1212,7 @@ func (x *RestoreSnapshotCommand) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use RestoreSnapshotCommand.ProtoReflect.Descriptor instead.
 func (*RestoreSnapshotCommand) Descriptor() ([]byte, []int) {
-	return file_jina_proto_rawDescGZIP(), []int{14}
+	return file_jina_proto_rawDescGZIP(), []int{15}
 }
 
 func (x *RestoreSnapshotCommand) GetSnapshotFile() string {
@@ -1162,7 +1240,7 @@ type StatusProto_ExceptionProto struct {
 func (x *StatusProto_ExceptionProto) Reset() {
 	*x = StatusProto_ExceptionProto{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_jina_proto_msgTypes[17]
+		mi := &file_jina_proto_msgTypes[18]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1175,7 +1253,7 @@ func (x *StatusProto_ExceptionProto) String() string {
 func (*StatusProto_ExceptionProto) ProtoMessage() {}
 
 func (x *StatusProto_ExceptionProto) ProtoReflect() protoreflect.Message {
-	mi := &file_jina_proto_msgTypes[17]
+	mi := &file_jina_proto_msgTypes[18]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1225,7 +1303,6 @@ type DataRequestProto_DataContentProto struct {
 	unknownFields protoimpl.UnknownFields
 
 	// Types that are assignable to Documents:
-	//
 	//	*DataRequestProto_DataContentProto_Docs
 	//	*DataRequestProto_DataContentProto_DocsBytes
 	Documents isDataRequestProto_DataContentProto_Documents `protobuf_oneof:"documents"`
@@ -1234,7 +1311,7 @@ type DataRequestProto_DataContentProto struct {
 func (x *DataRequestProto_DataContentProto) Reset() {
 	*x = DataRequestProto_DataContentProto{}
 	if protoimpl.UnsafeEnabled {
-		mi := &file_jina_proto_msgTypes[18]
+		mi := &file_jina_proto_msgTypes[19]
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		ms.StoreMessageInfo(mi)
 	}
@@ -1247,7 +1324,7 @@ func (x *DataRequestProto_DataContentProto) String() string {
 func (*DataRequestProto_DataContentProto) ProtoMessage() {}
 
 func (x *DataRequestProto_DataContentProto) ProtoReflect() protoreflect.Message {
-	mi := &file_jina_proto_msgTypes[18]
+	mi := &file_jina_proto_msgTypes[19]
 	if protoimpl.UnsafeEnabled && x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1461,7 +1538,14 @@ var file_jina_proto_rawDesc = []byte{
 	0x10, 0x02, 0x12, 0x0b, 0x0a, 0x07, 0x52, 0x55, 0x4e, 0x4e, 0x49, 0x4e, 0x47, 0x10, 0x03, 0x12,
 	0x0d, 0x0a, 0x09, 0x53, 0x55, 0x43, 0x43, 0x45, 0x45, 0x44, 0x45, 0x44, 0x10, 0x04, 0x12, 0x0a,
 	0x0a, 0x06, 0x46, 0x41, 0x49, 0x4c, 0x45, 0x44, 0x10, 0x05, 0x12, 0x0d, 0x0a, 0x09, 0x4e, 0x4f,
-	0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x06, 0x22, 0xd6, 0x01, 0x0a, 0x1a, 0x52, 0x65,
+	0x54, 0x5f, 0x46, 0x4f, 0x55, 0x4e, 0x44, 0x10, 0x06, 0x22, 0x6e, 0x0a, 0x12, 0x53, 0x6e, 0x61,
+	0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x12,
+	0x12, 0x0a, 0x04, 0x64, 0x61, 0x74, 0x61, 0x18, 0x01, 0x20, 0x01, 0x28, 0x0c, 0x52, 0x04, 0x64,
+	0x61, 0x74, 0x61, 0x12, 0x16, 0x0a, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x18, 0x02, 0x20,
+	0x01, 0x28, 0x04, 0x52, 0x06, 0x6f, 0x66, 0x66, 0x73, 0x65, 0x74, 0x12, 0x14, 0x0a, 0x05, 0x63,
+	0x72, 0x63, 0x33, 0x32, 0x18, 0x03, 0x20, 0x01, 0x28, 0x07, 0x52, 0x05, 0x63, 0x72, 0x63, 0x33,
+	0x32, 0x12, 0x16, 0x0a, 0x06, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x18, 0x04, 0x20, 0x01, 0x28,
+	0x0c, 0x52, 0x06, 0x73, 0x68, 0x61, 0x32, 0x35, 0x36, 0x22, 0xd6, 0x01, 0x0a, 0x1a, 0x52, 0x65,
 	0x73, 0x74, 0x6f, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x53, 0x74, 0x61,
 	0x74, 0x75, 0x73, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x12, 0x1f, 0x0a, 0x02, 0x69, 0x64, 0x18, 0x01,
 	0x20, 0x01, 0x28, 0x0b, 0x32, 0x0f, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x52, 0x65, 0x73, 0x74,
@@ -1530,21 +1614,34 @@ var file_jina_proto_rawDesc = []byte{
 	0x74, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x10, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e,
 	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x49, 0x64, 0x1a, 0x19, 0x2e, 0x6a, 0x69, 0x6e,
 	0x61, 0x2e, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73,
-	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x00, 0x32, 0x62, 0x0a, 0x13, 0x4a, 0x69, 0x6e, 0x61, 0x45,
-	0x78, 0x65, 0x63, 0x75, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x4b,
-	0x0a, 0x07, 0x72, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x1c, 0x2e, 0x6a, 0x69, 0x6e, 0x61,
+	0x50, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x00, 0x32, 0x5b, 0x0a, 0x18, 0x4a, 0x69, 0x6e, 0x61, 0x45,
+	0x78, 0x65, 0x63, 0x75, 0x74, 0x6f, 0x72, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x52,
+	0x65, 0x61, 0x64, 0x12, 0x3f, 0x0a, 0x0d, 0x72, 0x65, 0x61, 0x64, 0x5f, 0x73, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x12, 0x10, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x53, 0x6e, 0x61, 0x70,
+	0x73, 0x68, 0x6f, 0x74, 0x49, 0x64, 0x1a, 0x18, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x53, 0x6e,
+	0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x50, 0x72, 0x6f, 0x74, 0x6f,
+	0x22, 0x00, 0x30, 0x01, 0x32, 0x62, 0x0a, 0x13, 0x4a, 0x69, 0x6e, 0x61, 0x45, 0x78, 0x65, 0x63,
+	0x75, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x4b, 0x0a, 0x07, 0x72,
+	0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x12, 0x1c, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x52, 0x65,
+	0x73, 0x74, 0x6f, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x6f, 0x6d,
+	0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x20, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x52, 0x65, 0x73, 0x74,
+	0x6f, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75,
+	0x73, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x00, 0x32, 0x6d, 0x0a, 0x19, 0x4a, 0x69, 0x6e, 0x61,
+	0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x53,
+	0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x50, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65,
+	0x5f, 0x73, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x12, 0x18, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x53,
+	0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x43, 0x68, 0x75, 0x6e, 0x6b, 0x50, 0x72, 0x6f, 0x74,
+	0x6f, 0x1a, 0x20, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65,
+	0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x50, 0x72,
+	0x6f, 0x74, 0x6f, 0x22, 0x00, 0x28, 0x01, 0x32, 0x64, 0x0a, 0x1b, 0x4a, 0x69, 0x6e, 0x61, 0x45,
+	0x78, 0x65, 0x63, 0x75, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x50, 0x72,
+	0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x45, 0x0a, 0x0e, 0x72, 0x65, 0x73, 0x74, 0x6f, 0x72,
+	0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0f, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e,
+	0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x49, 0x64, 0x1a, 0x20, 0x2e, 0x6a, 0x69, 0x6e, 0x61,
 	0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74,
-	0x43, 0x6f, 0x6d, 0x6d, 0x61, 0x6e, 0x64, 0x1a, 0x20, 0x2e, 0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x52,
-	0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70, 0x73, 0x68, 0x6f, 0x74, 0x53, 0x74,
-	0x61, 0x74, 0x75, 0x73, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x00, 0x32, 0x64, 0x0a, 0x1b, 0x4a,
-	0x69, 0x6e, 0x61, 0x45, 0x78, 0x65, 0x63, 0x75, 0x74, 0x6f, 0x72, 0x52, 0x65, 0x73, 0x74, 0x6f,
-	0x72, 0x65, 0x50, 0x72, 0x6f, 0x67, 0x72, 0x65, 0x73, 0x73, 0x12, 0x45, 0x0a, 0x0e, 0x72, 0x65,
-	0x73, 0x74, 0x6f, 0x72, 0x65, 0x5f, 0x73, 0x74, 0x61, 0x74, 0x75, 0x73, 0x12, 0x0f, 0x2e, 0x6a,
-	0x69, 0x6e, 0x61, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x49, 0x64, 0x1a, 0x20, 0x2e,
-	0x6a, 0x69, 0x6e, 0x61, 0x2e, 0x52, 0x65, 0x73, 0x74, 0x6f, 0x72, 0x65, 0x53, 0x6e, 0x61, 0x70,
-	0x73, 0x68, 0x6f, 0x74, 0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x22,
-	0x00, 0x42, 0x15, 0x5a, 0x13, 0x6a, 0x72, 0x61, 0x66, 0x74, 0x2f, 0x6a, 0x69, 0x6e, 0x61, 0x2d,
-	0x67, 0x6f, 0x2d, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x53, 0x74, 0x61, 0x74, 0x75, 0x73, 0x50, 0x72, 0x6f, 0x74, 0x6f, 0x22, 0x00, 0x42, 0x15, 0x5a,
+	0x13, 0x6a, 0x72, 0x61, 0x66, 0x74, 0x2f, 0x6a, 0x69, 0x6e, 0x61, 0x2d, 0x67, 0x6f, 0x2d, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var (
@@ -1560,7 +1657,7 @@ func file_jina_proto_rawDescGZIP() []byte {
 }
 
 var file_jina_proto_enumTypes = make([]protoimpl.EnumInfo, 3)
-var file_jina_proto_msgTypes = make([]protoimpl.MessageInfo, 19)
+var file_jina_proto_msgTypes = make([]protoimpl.MessageInfo, 20)
 var file_jina_proto_goTypes = []interface{}{
 	(StatusProto_StatusCode)(0),               // 0: jina.StatusProto.StatusCode
 	(SnapshotStatusProto_Status)(0),           // 1: jina.SnapshotStatusProto.Status
@@ -1578,69 +1675,74 @@ var file_jina_proto_goTypes = []interface{}{
 	(*SnapshotId)(nil),                        // 13: jina.SnapshotId
 	(*RestoreId)(nil),                         // 14: jina.RestoreId
 	(*SnapshotStatusProto)(nil),               // 15: jina.SnapshotStatusProto
-	(*RestoreSnapshotStatusProto)(nil),        // 16: jina.RestoreSnapshotStatusProto
-	(*RestoreSnapshotCommand)(nil),            // 17: jina.RestoreSnapshotCommand
-	nil,                                       // 18: jina.JinaInfoProto.JinaEntry
-	nil,                                       // 19: jina.JinaInfoProto.EnvsEntry
-	(*StatusProto_ExceptionProto)(nil),        // 20: jina.StatusProto.ExceptionProto
-	(*DataRequestProto_DataContentProto)(nil), // 21: jina.DataRequestProto.DataContentProto
-	(*timestamppb.Timestamp)(nil),             // 22: google.protobuf.Timestamp
-	(*structpb.Struct)(nil),                   // 23: google.protobuf.Struct
-	(*docarray_go_proto.DocProto)(nil),        // 24: docarray.DocProto
-	(*docarray_go_proto.DocListProto)(nil),    // 25: docarray.DocListProto
-	(*emptypb.Empty)(nil),                     // 26: google.protobuf.Empty
+	(*SnapshotChunkProto)(nil),                // 16: jina.SnapshotChunkProto
+	(*RestoreSnapshotStatusProto)(nil),        // 17: jina.RestoreSnapshotStatusProto
+	(*RestoreSnapshotCommand)(nil),            // 18: jina.RestoreSnapshotCommand
+	nil,                                       // 19: jina.JinaInfoProto.JinaEntry
+	nil,                                       // 20: jina.JinaInfoProto.EnvsEntry
+	(*StatusProto_ExceptionProto)(nil),        // 21: jina.StatusProto.ExceptionProto
+	(*DataRequestProto_DataContentProto)(nil), // 22: jina.DataRequestProto.DataContentProto
+	(*timestamppb.Timestamp)(nil),             // 23: google.protobuf.Timestamp
+	(*structpb.Struct)(nil),                   // 24: google.protobuf.Struct
+	(*docarray_go_proto.DocProto)(nil),        // 25: docarray.DocProto
+	(*docarray_go_proto.DocListProto)(nil),    // 26: docarray.DocListProto
+	(*emptypb.Empty)(nil),                     // 27: google.protobuf.Empty
 }
 var file_jina_proto_depIdxs = []int32{
-	22, // 0: jina.RouteProto.start_time:type_name -> google.protobuf.Timestamp
-	22, // 1: jina.RouteProto.end_time:type_name -> google.protobuf.Timestamp
+	23, // 0: jina.RouteProto.start_time:type_name -> google.protobuf.Timestamp
+	23, // 1: jina.RouteProto.end_time:type_name -> google.protobuf.Timestamp
 	7,  // 2: jina.RouteProto.status:type_name -> jina.StatusProto
-	18, // 3: jina.JinaInfoProto.jina:type_name -> jina.JinaInfoProto.JinaEntry
-	19, // 4: jina.JinaInfoProto.envs:type_name -> jina.JinaInfoProto.EnvsEntry
+	19, // 3: jina.JinaInfoProto.jina:type_name -> jina.JinaInfoProto.JinaEntry
+	20, // 4: jina.JinaInfoProto.envs:type_name -> jina.JinaInfoProto.EnvsEntry
 	7,  // 5: jina.HeaderProto.status:type_name -> jina.StatusProto
-	23, // 6: jina.EndpointsProto.schemas:type_name -> google.protobuf.Struct
+	24, // 6: jina.EndpointsProto.schemas:type_name -> google.protobuf.Struct
 	0,  // 7: jina.StatusProto.code:type_name -> jina.StatusProto.StatusCode
-	20, // 8: jina.StatusProto.exception:type_name -> jina.StatusProto.ExceptionProto
+	21, // 8: jina.StatusProto.exception:type_name -> jina.StatusProto.ExceptionProto
 	5,  // 9: jina.DataRequestProto.header:type_name -> jina.HeaderProto
-	23, // 10: jina.DataRequestProto.parameters:type_name -> google.protobuf.Struct
+	24, // 10: jina.DataRequestProto.parameters:type_name -> google.protobuf.Struct
 	3,  // 11: jina.DataRequestProto.routes:type_name -> jina.RouteProto
-	21, // 12: jina.DataRequestProto.data:type_name -> jina.DataRequestProto.DataContentProto
+	22, // 12: jina.DataRequestProto.data:type_name -> jina.DataRequestProto.DataContentProto
 	5,  // 13: jina.SingleDocumentRequestProto.header:type_name -> jina.HeaderProto
-	23, // 14: jina.SingleDocumentRequestProto.parameters:type_name -> google.protobuf.Struct
+	24, // 14: jina.SingleDocumentRequestProto.parameters:type_name -> google.protobuf.Struct
 	3,  // 15: jina.SingleDocumentRequestProto.routes:type_name -> jina.RouteProto
-	24, // 16: jina.SingleDocumentRequestProto.document:type_name -> docarray.DocProto
+	25, // 16: jina.SingleDocumentRequestProto.document:type_name -> docarray.DocProto
 	5,  // 17: jina.DataRequestProtoWoData.header:type_name -> jina.HeaderProto
-	23, // 18: jina.DataRequestProtoWoData.parameters:type_name -> google.protobuf.Struct
+	24, // 18: jina.DataRequestProtoWoData.parameters:type_name -> google.protobuf.Struct
 	3,  // 19: jina.DataRequestProtoWoData.routes:type_name -> jina.RouteProto
 	9,  // 20: jina.DataRequestListProto.requests:type_name -> jina.DataRequestProto
 	13, // 21: jina.SnapshotStatusProto.id:type_name -> jina.SnapshotId
 	1,  // 22: jina.SnapshotStatusProto.status:type_name -> jina.SnapshotStatusProto.Status
 	14, // 23: jina.RestoreSnapshotStatusProto.id:type_name -> jina.RestoreId
 	2,  // 24: jina.RestoreSnapshotStatusProto.status:type_name -> jina.RestoreSnapshotStatusProto.Status
-	25, // 25: jina.DataRequestProto.DataContentProto.docs:type_name -> docarray.DocListProto
+	26, // 25: jina.DataRequestProto.DataContentProto.docs:type_name -> docarray.DocListProto
 	12, // 26: jina.JinaDataRequestRPC.process_data:input_type -> jina.DataRequestListProto
 	9,  // 27: jina.JinaSingleDataRequestRPC.process_single_data:input_type -> jina.DataRequestProto
 	10, // 28: jina.JinaSingleDocumentRequestRPC.stream_doc:input_type -> jina.SingleDocumentRequestProto
 	9,  // 29: jina.JinaRPC.Call:input_type -> jina.DataRequestProto
-	26, // 30: jina.JinaDiscoverEndpointsRPC.endpoint_discovery:input_type -> google.protobuf.Empty
-	26, // 31: jina.JinaGatewayDryRunRPC.dry_run:input_type -> google.protobuf.Empty
-	26, // 32: jina.JinaInfoRPC._status:input_type -> google.protobuf.Empty
-	26, // 33: jina.JinaExecutorSnapshot.snapshot:input_type -> google.protobuf.Empty
+	27, // 30: jina.JinaDiscoverEndpointsRPC.endpoint_discovery:input_type -> google.protobuf.Empty
+	27, // 31: jina.JinaGatewayDryRunRPC.dry_run:input_type -> google.protobuf.Empty
+	27, // 32: jina.JinaInfoRPC._status:input_type -> google.protobuf.Empty
+	27, // 33: jina.JinaExecutorSnapshot.snapshot:input_type -> google.protobuf.Empty
 	13, // 34: jina.JinaExecutorSnapshotProgress.snapshot_status:input_type -> jina.SnapshotId
-	17, // 35: jina.JinaExecutorRestore.restore:input_type -> jina.RestoreSnapshotCommand
-	14, // 36: jina.JinaExecutorRestoreProgress.restore_status:input_type -> jina.RestoreId
-	9,  // 37: jina.JinaDataRequestRPC.process_data:output_type -> jina.DataRequestProto
-	9,  // 38: jina.JinaSingleDataRequestRPC.process_single_data:output_type -> jina.DataRequestProto
-	10, // 39: jina.JinaSingleDocumentRequestRPC.stream_doc:output_type -> jina.SingleDocumentRequestProto
-	9,  // 40: jina.JinaRPC.Call:output_type -> jina.DataRequestProto
-	6,  // 41: jina.JinaDiscoverEndpointsRPC.endpoint_discovery:output_type -> jina.EndpointsProto
-	7,  // 42: jina.JinaGatewayDryRunRPC.dry_run:output_type -> jina.StatusProto
-	4,  // 43: jina.JinaInfoRPC._status:output_type -> jina.JinaInfoProto
-	15, // 44: jina.JinaExecutorSnapshot.snapshot:output_type -> jina.SnapshotStatusProto
-	15, // 45: jina.JinaExecutorSnapshotProgress.snapshot_status:output_type -> jina.SnapshotStatusProto
-	16, // 46: jina.JinaExecutorRestore.restore:output_type -> jina.RestoreSnapshotStatusProto
-	16, // 47: jina.JinaExecutorRestoreProgress.restore_status:output_type -> jina.RestoreSnapshotStatusProto
-	37, // [37:48] is the sub-list for method output_type
-	26, // [26:37] is the sub-list for method input_type
+	13, // 35: jina.JinaExecutorSnapshotRead.read_snapshot:input_type -> jina.SnapshotId
+	18, // 36: jina.JinaExecutorRestore.restore:input_type -> jina.RestoreSnapshotCommand
+	16, // 37: jina.JinaExecutorRestoreStream.restore_stream:input_type -> jina.SnapshotChunkProto
+	14, // 38: jina.JinaExecutorRestoreProgress.restore_status:input_type -> jina.RestoreId
+	9,  // 39: jina.JinaDataRequestRPC.process_data:output_type -> jina.DataRequestProto
+	9,  // 40: jina.JinaSingleDataRequestRPC.process_single_data:output_type -> jina.DataRequestProto
+	10, // 41: jina.JinaSingleDocumentRequestRPC.stream_doc:output_type -> jina.SingleDocumentRequestProto
+	9,  // 42: jina.JinaRPC.Call:output_type -> jina.DataRequestProto
+	6,  // 43: jina.JinaDiscoverEndpointsRPC.endpoint_discovery:output_type -> jina.EndpointsProto
+	7,  // 44: jina.JinaGatewayDryRunRPC.dry_run:output_type -> jina.StatusProto
+	4,  // 45: jina.JinaInfoRPC._status:output_type -> jina.JinaInfoProto
+	15, // 46: jina.JinaExecutorSnapshot.snapshot:output_type -> jina.SnapshotStatusProto
+	15, // 47: jina.JinaExecutorSnapshotProgress.snapshot_status:output_type -> jina.SnapshotStatusProto
+	16, // 48: jina.JinaExecutorSnapshotRead.read_snapshot:output_type -> jina.SnapshotChunkProto
+	17, // 49: jina.JinaExecutorRestore.restore:output_type -> jina.RestoreSnapshotStatusProto
+	17, // 50: jina.JinaExecutorRestoreStream.restore_stream:output_type -> jina.RestoreSnapshotStatusProto
+	17, // 51: jina.JinaExecutorRestoreProgress.restore_status:output_type -> jina.RestoreSnapshotStatusProto
+	39, // [39:52] is the sub-list for method output_type
+	26, // [26:39] is the sub-list for method input_type
 	26, // [26:26] is the sub-list for extension type_name
 	26, // [26:26] is the sub-list for extension extendee
 	0,  // [0:26] is the sub-list for field type_name
@@ -1809,7 +1911,7 @@ func file_jina_proto_init() {
 			}
 		}
 		file_jina_proto_msgTypes[13].Exporter = func(v interface{}, i int) interface{} {
-			switch v := v.(*RestoreSnapshotStatusProto); i {
+			switch v := v.(*SnapshotChunkProto); i {
 			case 0:
 				return &v.state
 			case 1:
@@ -1821,6 +1923,18 @@ func file_jina_proto_init() {
 			}
 		}
 		file_jina_proto_msgTypes[14].Exporter = func(v interface{}, i int) interface{} {
+			switch v := v.(*RestoreSnapshotStatusProto); i {
+			case 0:
+				return &v.state
+			case 1:
+				return &v.sizeCache
+			case 2:
+				return &v.unknownFields
+			default:
+				return nil
+			}
+		}
+		file_jina_proto_msgTypes[15].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*RestoreSnapshotCommand); i {
 			case 0:
 				return &v.state
@@ -1832,7 +1946,7 @@ func file_jina_proto_init() {
 				return nil
 			}
 		}
-		file_jina_proto_msgTypes[17].Exporter = func(v interface{}, i int) interface{} {
+		file_jina_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*StatusProto_ExceptionProto); i {
 			case 0:
 				return &v.state
@@ -1844,7 +1958,7 @@ func file_jina_proto_init() {
 				return nil
 			}
 		}
-		file_jina_proto_msgTypes[18].Exporter = func(v interface{}, i int) interface{} {
+		file_jina_proto_msgTypes[19].Exporter = func(v interface{}, i int) interface{} {
 			switch v := v.(*DataRequestProto_DataContentProto); i {
 			case 0:
 				return &v.state
@@ -1859,7 +1973,7 @@ func file_jina_proto_init() {
 	}
 	file_jina_proto_msgTypes[2].OneofWrappers = []interface{}{}
 	file_jina_proto_msgTypes[5].OneofWrappers = []interface{}{}
-	file_jina_proto_msgTypes[18].OneofWrappers = []interface{}{
+	file_jina_proto_msgTypes[19].OneofWrappers = []interface{}{
 		(*DataRequestProto_DataContentProto_Docs)(nil),
 		(*DataRequestProto_DataContentProto_DocsBytes)(nil),
 	}
@@ -1869,9 +1983,9 @@ func file_jina_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: file_jina_proto_rawDesc,
 			NumEnums:      3,
-			NumMessages:   19,
+			NumMessages:   20,
 			NumExtensions: 0,
-			NumServices:   11,
+			NumServices:   13,
 		},
 		GoTypes:           file_jina_proto_goTypes,
 		DependencyIndexes: file_jina_proto_depIdxs,
@@ -902,6 +902,124 @@ var JinaExecutorSnapshotProgress_ServiceDesc = grpc.ServiceDesc{
 	Metadata: "jina.proto",
 }
 
+const (
+	JinaExecutorSnapshotRead_ReadSnapshot_FullMethodName = "/jina.JinaExecutorSnapshotRead/read_snapshot"
+)
+
+// JinaExecutorSnapshotReadClient is the client API for JinaExecutorSnapshotRead service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type JinaExecutorSnapshotReadClient interface {
+	ReadSnapshot(ctx context.Context, in *SnapshotId, opts ...grpc.CallOption) (JinaExecutorSnapshotRead_ReadSnapshotClient, error)
+}
+
+type jinaExecutorSnapshotReadClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJinaExecutorSnapshotReadClient(cc grpc.ClientConnInterface) JinaExecutorSnapshotReadClient {
+	return &jinaExecutorSnapshotReadClient{cc}
+}
+
+func (c *jinaExecutorSnapshotReadClient) ReadSnapshot(ctx context.Context, in *SnapshotId, opts ...grpc.CallOption) (JinaExecutorSnapshotRead_ReadSnapshotClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JinaExecutorSnapshotRead_ServiceDesc.Streams[0], JinaExecutorSnapshotRead_ReadSnapshot_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jinaExecutorSnapshotReadReadSnapshotClient{stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+type JinaExecutorSnapshotRead_ReadSnapshotClient interface {
+	Recv() (*SnapshotChunkProto, error)
+	grpc.ClientStream
+}
+
+type jinaExecutorSnapshotReadReadSnapshotClient struct {
+	grpc.ClientStream
+}
+
+func (x *jinaExecutorSnapshotReadReadSnapshotClient) Recv() (*SnapshotChunkProto, error) {
+	m := new(SnapshotChunkProto)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// JinaExecutorSnapshotReadServer is the server API for JinaExecutorSnapshotRead service.
+// All implementations must embed UnimplementedJinaExecutorSnapshotReadServer
+// for forward compatibility
+type JinaExecutorSnapshotReadServer interface {
+	ReadSnapshot(*SnapshotId, JinaExecutorSnapshotRead_ReadSnapshotServer) error
+	mustEmbedUnimplementedJinaExecutorSnapshotReadServer()
+}
+
+// UnimplementedJinaExecutorSnapshotReadServer must be embedded to have forward compatible implementations.
+type UnimplementedJinaExecutorSnapshotReadServer struct {
+}
+
+func (UnimplementedJinaExecutorSnapshotReadServer) ReadSnapshot(*SnapshotId, JinaExecutorSnapshotRead_ReadSnapshotServer) error {
+	return status.Errorf(codes.Unimplemented, "method ReadSnapshot not implemented")
+}
+func (UnimplementedJinaExecutorSnapshotReadServer) mustEmbedUnimplementedJinaExecutorSnapshotReadServer() {
+}
+
+// UnsafeJinaExecutorSnapshotReadServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JinaExecutorSnapshotReadServer will
+// result in compilation errors.
+type UnsafeJinaExecutorSnapshotReadServer interface {
+	mustEmbedUnimplementedJinaExecutorSnapshotReadServer()
+}
+
+func RegisterJinaExecutorSnapshotReadServer(s grpc.ServiceRegistrar, srv JinaExecutorSnapshotReadServer) {
+	s.RegisterService(&JinaExecutorSnapshotRead_ServiceDesc, srv)
+}
+
+func _JinaExecutorSnapshotRead_ReadSnapshot_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SnapshotId)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(JinaExecutorSnapshotReadServer).ReadSnapshot(m, &jinaExecutorSnapshotReadReadSnapshotServer{stream})
+}
+
+type JinaExecutorSnapshotRead_ReadSnapshotServer interface {
+	Send(*SnapshotChunkProto) error
+	grpc.ServerStream
+}
+
+type jinaExecutorSnapshotReadReadSnapshotServer struct {
+	grpc.ServerStream
+}
+
+func (x *jinaExecutorSnapshotReadReadSnapshotServer) Send(m *SnapshotChunkProto) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+// JinaExecutorSnapshotRead_ServiceDesc is the grpc.ServiceDesc for JinaExecutorSnapshotRead service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JinaExecutorSnapshotRead_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jina.JinaExecutorSnapshotRead",
+	HandlerType: (*JinaExecutorSnapshotReadServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "read_snapshot",
+			Handler:       _JinaExecutorSnapshotRead_ReadSnapshot_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "jina.proto",
+}
+
 const (
 	JinaExecutorRestore_Restore_FullMethodName = "/jina.JinaExecutorRestore/restore"
 )
@@ -992,6 +1110,131 @@ var JinaExecutorRestore_ServiceDesc = grpc.ServiceDesc{
 	Metadata: "jina.proto",
 }
 
+const (
+	JinaExecutorRestoreStream_RestoreStream_FullMethodName = "/jina.JinaExecutorRestoreStream/restore_stream"
+)
+
+// JinaExecutorRestoreStreamClient is the client API for JinaExecutorRestoreStream service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type JinaExecutorRestoreStreamClient interface {
+	RestoreStream(ctx context.Context, opts ...grpc.CallOption) (JinaExecutorRestoreStream_RestoreStreamClient, error)
+}
+
+type jinaExecutorRestoreStreamClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJinaExecutorRestoreStreamClient(cc grpc.ClientConnInterface) JinaExecutorRestoreStreamClient {
+	return &jinaExecutorRestoreStreamClient{cc}
+}
+
+func (c *jinaExecutorRestoreStreamClient) RestoreStream(ctx context.Context, opts ...grpc.CallOption) (JinaExecutorRestoreStream_RestoreStreamClient, error) {
+	stream, err := c.cc.NewStream(ctx, &JinaExecutorRestoreStream_ServiceDesc.Streams[0], JinaExecutorRestoreStream_RestoreStream_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &jinaExecutorRestoreStreamRestoreStreamClient{stream}
+	return x, nil
+}
+
+type JinaExecutorRestoreStream_RestoreStreamClient interface {
+	Send(*SnapshotChunkProto) error
+	CloseAndRecv() (*RestoreSnapshotStatusProto, error)
+	grpc.ClientStream
+}
+
+type jinaExecutorRestoreStreamRestoreStreamClient struct {
+	grpc.ClientStream
+}
+
+func (x *jinaExecutorRestoreStreamRestoreStreamClient) Send(m *SnapshotChunkProto) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *jinaExecutorRestoreStreamRestoreStreamClient) CloseAndRecv() (*RestoreSnapshotStatusProto, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(RestoreSnapshotStatusProto)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// JinaExecutorRestoreStreamServer is the server API for JinaExecutorRestoreStream service.
+// All implementations must embed UnimplementedJinaExecutorRestoreStreamServer
+// for forward compatibility
+type JinaExecutorRestoreStreamServer interface {
+	RestoreStream(JinaExecutorRestoreStream_RestoreStreamServer) error
+	mustEmbedUnimplementedJinaExecutorRestoreStreamServer()
+}
+
+// UnimplementedJinaExecutorRestoreStreamServer must be embedded to have forward compatible implementations.
+type UnimplementedJinaExecutorRestoreStreamServer struct {
+}
+
+func (UnimplementedJinaExecutorRestoreStreamServer) RestoreStream(JinaExecutorRestoreStream_RestoreStreamServer) error {
+	return status.Errorf(codes.Unimplemented, "method RestoreStream not implemented")
+}
+func (UnimplementedJinaExecutorRestoreStreamServer) mustEmbedUnimplementedJinaExecutorRestoreStreamServer() {
+}
+
+// UnsafeJinaExecutorRestoreStreamServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JinaExecutorRestoreStreamServer will
+// result in compilation errors.
+type UnsafeJinaExecutorRestoreStreamServer interface {
+	mustEmbedUnimplementedJinaExecutorRestoreStreamServer()
+}
+
+func RegisterJinaExecutorRestoreStreamServer(s grpc.ServiceRegistrar, srv JinaExecutorRestoreStreamServer) {
+	s.RegisterService(&JinaExecutorRestoreStream_ServiceDesc, srv)
+}
+
+func _JinaExecutorRestoreStream_RestoreStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(JinaExecutorRestoreStreamServer).RestoreStream(&jinaExecutorRestoreStreamRestoreStreamServer{stream})
+}
+
+type JinaExecutorRestoreStream_RestoreStreamServer interface {
+	SendAndClose(*RestoreSnapshotStatusProto) error
+	Recv() (*SnapshotChunkProto, error)
+	grpc.ServerStream
+}
+
+type jinaExecutorRestoreStreamRestoreStreamServer struct {
+	grpc.ServerStream
+}
+
+func (x *jinaExecutorRestoreStreamRestoreStreamServer) SendAndClose(m *RestoreSnapshotStatusProto) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *jinaExecutorRestoreStreamRestoreStreamServer) Recv() (*SnapshotChunkProto, error) {
+	m := new(SnapshotChunkProto)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// JinaExecutorRestoreStream_ServiceDesc is the grpc.ServiceDesc for JinaExecutorRestoreStream service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JinaExecutorRestoreStream_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jina.JinaExecutorRestoreStream",
+	HandlerType: (*JinaExecutorRestoreStreamServer)(nil),
+	Methods:     []grpc.MethodDesc{},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "restore_stream",
+			Handler:       _JinaExecutorRestoreStream_RestoreStream_Handler,
+			ClientStreams: true,
+		},
+	},
+	Metadata: "jina.proto",
+}
+
 const (
 	JinaExecutorRestoreProgress_RestoreStatus_FullMethodName = "/jina.JinaExecutorRestoreProgress/restore_status"
 )
@@ -0,0 +1,140 @@
+package server
+
+import (
+    "context"
+    "errors"
+
+    empty "github.com/golang/protobuf/ptypes/empty"
+    "github.com/hashicorp/raft"
+    jraftpb "jraft/jraft-go-proto"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/durationpb"
+)
+
+// jinaRaftAdminServer implements the JinaRaftAdmin service on top of a Node.
+type jinaRaftAdminServer struct {
+    node *Node
+    jraftpb.UnimplementedJinaRaftAdminServer
+}
+
+// Decommission is the admin RPC counterpart of Node.Decommission.
+func (s *jinaRaftAdminServer) Decommission(ctx context.Context, req *jraftpb.DecommissionRequest) (*jraftpb.DecommissionResponse, error) {
+    timeout := s.node.opts.DrainTimeout
+    if req.Timeout != nil {
+        timeout = req.Timeout.AsDuration()
+    }
+    ctx, cancel := context.WithTimeout(ctx, timeout)
+    defer cancel()
+    result, err := s.node.Decommission(ctx, req.LeaveCluster)
+    if err != nil {
+        return nil, err
+    }
+    return &jraftpb.DecommissionResponse{
+        WasLeader:     result.WasLeader,
+        LeaderId:      string(result.LeaderID),
+        LeaderAddress: string(result.LeaderAddress),
+        LeftCluster:   result.LeftCluster,
+    }, nil
+}
+
+// ReloadConfig is the admin RPC counterpart of Node.Reload. Settings absent from the request keep their value.
+func (s *jinaRaftAdminServer) ReloadConfig(ctx context.Context, req *jraftpb.ReloadConfigRequest) (*jraftpb.ReloadableConfigProto, error) {
+    ro := s.node.ReloadableOptions()
+    if req.TrailingLogs != nil {
+        ro.TrailingLogs = req.GetTrailingLogs()
+    }
+    if req.SnapshotInterval != nil {
+        ro.SnapshotInterval = req.SnapshotInterval.AsDuration()
+    }
+    if req.SnapshotThreshold != nil {
+        ro.SnapshotThreshold = req.GetSnapshotThreshold()
+    }
+    if req.HeartbeatTimeout != nil {
+        ro.HeartbeatTimeout = req.HeartbeatTimeout.AsDuration()
+    }
+    if req.ElectionTimeout != nil {
+        ro.ElectionTimeout = req.ElectionTimeout.AsDuration()
+    }
+    if req.LogLevel != nil {
+        ro.LogLevel = req.GetLogLevel()
+    }
+    effective, err := s.node.Reload(ro)
+    if err != nil {
+        return nil, err
+    }
+    return reloadableConfigProto(effective), nil
+}
+
+// GetReloadableConfig returns the effective reloadable settings of the Node.
+func (s *jinaRaftAdminServer) GetReloadableConfig(ctx context.Context, _ *empty.Empty) (*jraftpb.ReloadableConfigProto, error) {
+    return reloadableConfigProto(s.node.ReloadableOptions()), nil
+}
+
+func reloadableConfigProto(ro ReloadableOptions) *jraftpb.ReloadableConfigProto {
+    return &jraftpb.ReloadableConfigProto{
+        TrailingLogs:      ro.TrailingLogs,
+        SnapshotInterval:  durationpb.New(ro.SnapshotInterval),
+        SnapshotThreshold: ro.SnapshotThreshold,
+        HeartbeatTimeout:  durationpb.New(ro.HeartbeatTimeout),
+        ElectionTimeout:   durationpb.New(ro.ElectionTimeout),
+        LogLevel:          ro.LogLevel,
+    }
+}
+
+// TakeSnapshot is the admin RPC counterpart of Node.TakeSnapshot.
+func (s *jinaRaftAdminServer) TakeSnapshot(ctx context.Context, req *jraftpb.TakeSnapshotRequest) (*jraftpb.SnapshotInfo, error) {
+    if req.Timeout != nil {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, req.Timeout.AsDuration())
+        defer cancel()
+    }
+    info, err := s.node.TakeSnapshot(ctx)
+    return info, snapshotError(err)
+}
+
+// ListSnapshots is the admin RPC counterpart of Node.Snapshots.
+func (s *jinaRaftAdminServer) ListSnapshots(ctx context.Context, _ *empty.Empty) (*jraftpb.ListSnapshotsResponse, error) {
+    snapshots, err := s.node.Snapshots()
+    if err != nil {
+        return nil, err
+    }
+    return &jraftpb.ListSnapshotsResponse{Snapshots: snapshots}, nil
+}
+
+// GetSnapshot is the admin RPC counterpart of Node.SnapshotInfo.
+func (s *jinaRaftAdminServer) GetSnapshot(ctx context.Context, req *jraftpb.SnapshotRequest) (*jraftpb.SnapshotInfo, error) {
+    info, err := s.node.SnapshotInfo(req.Id)
+    return info, snapshotError(err)
+}
+
+// DeleteSnapshot is the admin RPC counterpart of Node.DeleteSnapshot.
+func (s *jinaRaftAdminServer) DeleteSnapshot(ctx context.Context, req *jraftpb.SnapshotRequest) (*empty.Empty, error) {
+    if err := s.node.DeleteSnapshot(req.Id); err != nil {
+        return nil, snapshotError(err)
+    }
+    return &empty.Empty{}, nil
+}
+
+// PinSnapshot is the admin RPC counterpart of Node.PinSnapshot.
+func (s *jinaRaftAdminServer) PinSnapshot(ctx context.Context, req *jraftpb.PinSnapshotRequest) (*jraftpb.SnapshotInfo, error) {
+    info, err := s.node.PinSnapshot(req.Id, req.Pinned)
+    return info, snapshotError(err)
+}
+
+// snapshotError maps the errors of the snapshot admin RPCs to gRPC statuses.
+func snapshotError(err error) error {
+    switch {
+    case err == nil:
+        return nil
+    case errors.Is(err, ErrSnapshotNotFound):
+        return status.Error(codes.NotFound, err.Error())
+    case errors.Is(err, ErrSnapshotPinned), errors.Is(err, ErrLatestSnapshot), errors.Is(err, ErrSnapshotInUse), errors.Is(err, raft.ErrNothingNewToSnapshot):
+        return status.Error(codes.FailedPrecondition, err.Error())
+    case errors.Is(err, context.DeadlineExceeded):
+        return status.Error(codes.DeadlineExceeded, err.Error())
+    case errors.Is(err, context.Canceled):
+        return status.Error(codes.Canceled, err.Error())
+    }
+    return err
+}
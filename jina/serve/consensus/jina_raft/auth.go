@@ -0,0 +1,322 @@
+package server
+
+import (
+    "context"
+    "crypto/subtle"
+    "errors"
+    "fmt"
+    "os"
+    "strings"
+    "sync"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/peer"
+    "google.golang.org/grpc/status"
+    "gopkg.in/yaml.v3"
+)
+
+// Planes group the gRPC methods served by a Node. They can be used instead of method patterns in an AuthPolicy.
+const (
+    // DataPlane are the Jina services and the health service used by the gateway and the clients
+    DataPlane  = "@data"
+    // AdminPlane are raftadmin, the JinaRaftAdmin service, reflection and any method not known by the Node
+    AdminPlane = "@admin"
+    // RaftPlane is the Raft transport used by the other members
+    RaftPlane  = "@raft"
+)
+
+const (
+    // AnyPrincipal matches every caller, authenticated or not
+    AnyPrincipal           = "*"
+    // AuthenticatedPrincipal matches every caller with a valid token or a verified client certificate
+    AuthenticatedPrincipal = "authenticated"
+)
+
+// methodPlane returns the plane of a full gRPC method name such as "/RaftAdmin/AddVoter".
+func methodPlane(fullMethod string) string {
+    switch {
+    case strings.HasPrefix(fullMethod, "/RaftTransport/"):
+        return RaftPlane
+    case strings.HasPrefix(fullMethod, "/jina."), strings.HasPrefix(fullMethod, "/grpc.health.v1.Health/"):
+        return DataPlane
+    default:
+        return AdminPlane
+    }
+}
+
+// AuthToken is a bearer token accepted by the Node. Callers presenting it are identified as "token:<name>".
+type AuthToken struct {
+    Name      string `yaml:"name"`
+    Token     string `yaml:"token"`
+    // TokenFile is read instead of Token, so that the secret is not stored in the policy
+    TokenFile string `yaml:"token_file"`
+}
+
+// AuthRule allows the principals to call the methods. Methods are full method names, patterns ending
+// with "*" such as "/RaftAdmin/*", or planes. Principals are "token:<name>", "cert:<identity>" where the
+// identity is the common name or a DNS or URI subject alternative name of a verified client certificate,
+// AuthenticatedPrincipal or AnyPrincipal.
+type AuthRule struct {
+    Methods []string `yaml:"methods"`
+    Allow   []string `yaml:"allow"`
+}
+
+// AuthPolicy is the content of the auth policy file. The first rule matching a method decides who can call it,
+// methods without a matching rule are denied. Without rules, DefaultAuthRules apply to Admins.
+type AuthPolicy struct {
+    Tokens []AuthToken `yaml:"tokens"`
+    Rules  []AuthRule  `yaml:"rules"`
+    // Admins are the named principals allowed on the admin plane by DefaultAuthRules, including the other members
+    // which call raftadmin and JinaRaftStatus. They are required without rules
+    Admins []string    `yaml:"admins"`
+}
+
+// DefaultAuthRules keep the data plane open, require the Raft transport callers to be authenticated and allow
+// only admins on the admin plane, so that a data plane caller cannot manage the cluster. The peers are
+// authenticated by their certificate, so the Node only starts with these rules when it verifies them on its own
+// Raft listener (RaftTLSVerifyPeers).
+func DefaultAuthRules(admins []string) []AuthRule {
+    return []AuthRule{
+        {Methods: []string{DataPlane}, Allow: []string{AnyPrincipal}},
+        {Methods: []string{RaftPlane}, Allow: []string{AuthenticatedPrincipal}},
+        {Methods: []string{AdminPlane}, Allow: admins},
+    }
+}
+
+// authorizer enforces the AuthPolicy of a file, which is loaded again when it changes on disk.
+type authorizer struct {
+    path string
+
+    mtx     sync.Mutex
+    checked time.Time
+    modTime time.Time
+    tokens  map[string]string
+    rules   []AuthRule
+
+    // defaults is set when the policy has no rules and DefaultAuthRules apply
+    defaults bool
+}
+
+func newAuthorizer(path string) (*authorizer, error) {
+    a := &authorizer{path: path}
+    if err := a.reload(); err != nil {
+        return nil, err
+    }
+    a.checked = time.Now()
+    return a, nil
+}
+
+func (a *authorizer) reload() error {
+    info, err := os.Stat(a.path)
+    if err != nil {
+        return fmt.Errorf("auth policy: %v", err)
+    }
+    if info.ModTime() == a.modTime && a.rules != nil {
+        return nil
+    }
+    content, err := os.ReadFile(a.path)
+    if err != nil {
+        return fmt.Errorf("auth policy: %v", err)
+    }
+    policy := AuthPolicy{}
+    if err := yaml.Unmarshal(content, &policy); err != nil {
+        return fmt.Errorf("parsing auth policy %q: %v", a.path, err)
+    }
+    tokens := map[string]string{}
+    for _, token := range policy.Tokens {
+        secret := token.Token
+        if token.TokenFile != "" {
+            secret, err = readToken(token.TokenFile)
+            if err != nil {
+                return fmt.Errorf("auth policy %q: token %q: %v", a.path, token.Name, err)
+            }
+        }
+        if token.Name == "" || secret == "" {
+            return fmt.Errorf("auth policy %q: tokens need a name and a token or token_file", a.path)
+        }
+        tokens[token.Name] = secret
+    }
+    rules := policy.Rules
+    if len(rules) == 0 {
+        if len(policy.Admins) == 0 {
+            return fmt.Errorf("auth policy %q has no rules: list the principals allowed on %s in admins, or add rules", a.path, AdminPlane)
+        }
+        for _, admin := range policy.Admins {
+            if !strings.HasPrefix(admin, "token:") && !strings.HasPrefix(admin, "cert:") {
+                return fmt.Errorf("auth policy %q: admins must be token:<name> or cert:<identity> principals, got %q", a.path, admin)
+            }
+        }
+        rules = DefaultAuthRules(policy.Admins)
+    }
+    a.tokens = tokens
+    a.rules = rules
+    a.defaults = len(policy.Rules) == 0
+    a.modTime = info.ModTime()
+    return nil
+}
+
+// current returns the tokens and rules, checking the policy file for changes at most once per
+// tlsReloadInterval. An invalid new policy is ignored and the previous one kept.
+func (a *authorizer) current() (map[string]string, []AuthRule, error) {
+    a.mtx.Lock()
+    defer a.mtx.Unlock()
+    if time.Since(a.checked) >= tlsReloadInterval {
+        a.checked = time.Now()
+        err := a.reload()
+        if err != nil {
+            return a.tokens, a.rules, err
+        }
+    }
+    return a.tokens, a.rules, nil
+}
+
+// principals identifies the caller of ctx from its bearer token and its verified client certificate.
+func principals(ctx context.Context, tokens map[string]string) ([]string, error) {
+    identities := []string{}
+    if md, ok := metadata.FromIncomingContext(ctx); ok {
+        for _, value := range md.Get("authorization") {
+            const prefix = "bearer "
+            if len(value) < len(prefix) || !strings.EqualFold(value[:len(prefix)], prefix) {
+                return nil, status.Error(codes.Unauthenticated, "unsupported authorization scheme")
+            }
+            name, ok := matchToken(tokens, value[len(prefix):])
+            if !ok {
+                return nil, status.Error(codes.Unauthenticated, "invalid bearer token")
+            }
+            identities = append(identities, "token:"+name)
+        }
+    }
+    if p, ok := peer.FromContext(ctx); ok {
+        if tlsInfo, ok := p.AuthInfo.(credentials.TLSInfo); ok && len(tlsInfo.State.VerifiedChains) > 0 && len(tlsInfo.State.VerifiedChains[0]) > 0 {
+            cert := tlsInfo.State.VerifiedChains[0][0]
+            if cert.Subject.CommonName != "" {
+                identities = append(identities, "cert:"+cert.Subject.CommonName)
+            }
+            for _, name := range cert.DNSNames {
+                identities = append(identities, "cert:"+name)
+            }
+            for _, uri := range cert.URIs {
+                identities = append(identities, "cert:"+uri.String())
+            }
+        }
+    }
+    return identities, nil
+}
+
+func matchToken(tokens map[string]string, token string) (string, bool) {
+    found := ""
+    for name, secret := range tokens {
+        // compare every token in constant time, so that the timing does not reveal which one matched
+        if subtle.ConstantTimeCompare([]byte(secret), []byte(token)) == 1 {
+            found = name
+        }
+    }
+    return found, found != ""
+}
+
+func methodMatches(pattern string, fullMethod string) bool {
+    if strings.HasPrefix(pattern, "@") {
+        return pattern == methodPlane(fullMethod)
+    }
+    if strings.HasSuffix(pattern, "*") {
+        return strings.HasPrefix(fullMethod, strings.TrimSuffix(pattern, "*"))
+    }
+    return pattern == fullMethod
+}
+
+// authorize returns nil if the caller of ctx is allowed to call fullMethod.
+func (a *authorizer) authorize(ctx context.Context, fullMethod string) error {
+    tokens, rules, _ := a.current()
+    identities, err := principals(ctx, tokens)
+    if err != nil {
+        return err
+    }
+    for _, rule := range rules {
+        matched := false
+        for _, pattern := range rule.Methods {
+            if methodMatches(pattern, fullMethod) {
+                matched = true
+                break
+            }
+        }
+        if !matched {
+            continue
+        }
+        for _, allowed := range rule.Allow {
+            if allowed == AnyPrincipal || (allowed == AuthenticatedPrincipal && len(identities) > 0) {
+                return nil
+            }
+            for _, identity := range identities {
+                if allowed == identity {
+                    return nil
+                }
+            }
+        }
+        break
+    }
+    if len(identities) == 0 {
+        return status.Errorf(codes.Unauthenticated, "%s requires authentication", fullMethod)
+    }
+    return status.Errorf(codes.PermissionDenied, "%v are not allowed to call %s", identities, fullMethod)
+}
+
+func (a *authorizer) unaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+    if err := a.authorize(ctx, info.FullMethod); err != nil {
+        return nil, err
+    }
+    return handler(ctx, req)
+}
+
+func (a *authorizer) streamInterceptor(srv interface{}, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+    if err := a.authorize(ss.Context(), info.FullMethod); err != nil {
+        return err
+    }
+    return handler(srv, ss)
+}
+
+func readToken(path string) (string, error) {
+    content, err := os.ReadFile(path)
+    if err != nil {
+        return "", err
+    }
+    return strings.TrimSpace(string(content)), nil
+}
+
+// tokenCredentials present a bearer token read from a file on every call, so that it can be rotated.
+// The token is only sent over TLS.
+type tokenCredentials struct {
+    path string
+}
+
+func (c tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+    token, err := readToken(c.path)
+    if err != nil {
+        return nil, err
+    }
+    if token == "" {
+        return nil, errors.New("empty bearer token")
+    }
+    return map[string]string{"authorization": "Bearer " + token}, nil
+}
+
+func (c tokenCredentials) RequireTransportSecurity() bool {
+    return true
+}
+
+// AdminDialOptions are the dial options to call the raftadmin service of the cluster members,
+// presenting the bearer token of AuthTokenFile if set.
+func (opts Options) AdminDialOptions() ([]grpc.DialOption, error) {
+    dialOption, err := opts.RaftDialOption()
+    if err != nil {
+        return nil, err
+    }
+    dialOptions := []grpc.DialOption{dialOption}
+    if opts.AuthTokenFile != "" {
+        dialOptions = append(dialOptions, grpc.WithPerRPCCredentials(tokenCredentials{path: opts.AuthTokenFile}))
+    }
+    return dialOptions, nil
+}
@@ -0,0 +1,217 @@
+package server
+
+import (
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "crypto/x509/pkix"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials"
+    "google.golang.org/grpc/metadata"
+    "google.golang.org/grpc/peer"
+    "google.golang.org/grpc/status"
+)
+
+// writePolicy writes the auth policy content to path, with a modification time after the previous one so that it
+// is reloaded.
+func writePolicy(t *testing.T, path string, content string) {
+    t.Helper()
+    modTime := time.Now()
+    if info, err := os.Stat(path); err == nil {
+        modTime = info.ModTime().Add(time.Second)
+    }
+    if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.Chtimes(path, modTime, modTime); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func newTestAuthorizer(t *testing.T, content string) (*authorizer, string) {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), "policy.yml")
+    writePolicy(t, path, content)
+    a, err := newAuthorizer(path)
+    if err != nil {
+        t.Fatalf("newAuthorizer() = %v", err)
+    }
+    return a, path
+}
+
+// callerContext is the context of a call presenting the bearer token, if any, and a verified client certificate
+// of commonName, if any.
+func callerContext(token string, commonName string) context.Context {
+    ctx := context.Background()
+    if token != "" {
+        ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("authorization", token))
+    }
+    if commonName != "" {
+        cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}, DNSNames: []string{commonName + ".example.com"}}
+        ctx = peer.NewContext(ctx, &peer.Peer{AuthInfo: credentials.TLSInfo{State: tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}}})
+    }
+    return ctx
+}
+
+func TestMethodPlane(t *testing.T) {
+    tests := []struct {
+        method string
+        plane  string
+    }{
+        {method: "/RaftTransport/AppendEntries", plane: RaftPlane},
+        {method: "/RaftTransport/InstallSnapshot", plane: RaftPlane},
+        {method: "/jina.JinaSingleDataRequestRPC/process_single_data", plane: DataPlane},
+        {method: "/jina.JinaDiscoverEndpointsRPC/endpoint_discovery", plane: DataPlane},
+        {method: "/grpc.health.v1.Health/Check", plane: DataPlane},
+        {method: "/RaftAdmin/AddVoter", plane: AdminPlane},
+        {method: "/jraft.JinaRaftAdmin/DeleteSnapshot", plane: AdminPlane},
+        {method: "/jraft.JinaRaftStatus/GetStatus", plane: AdminPlane},
+        {method: "/grpc.reflection.v1alpha.ServerReflection/ServerReflectionInfo", plane: AdminPlane},
+        {method: "/unknown.Service/Method", plane: AdminPlane},
+    }
+    for _, test := range tests {
+        if plane := methodPlane(test.method); plane != test.plane {
+            t.Errorf("methodPlane(%q) = %q, want %q", test.method, plane, test.plane)
+        }
+    }
+}
+
+const testPolicy = `
+tokens:
+  - name: ops
+    token: ops-secret
+  - name: app
+    token: app-secret
+rules:
+  - methods: ["/RaftAdmin/Shutdown"]
+    allow: ["cert:executor-0"]
+  - methods: ["/RaftAdmin/*", "/jraft.JinaRaftStatus/GetStatus"]
+    allow: ["token:ops", "cert:executor-0", "cert:executor-1.example.com"]
+  - methods: ["@raft"]
+    allow: ["authenticated"]
+  - methods: ["@data"]
+    allow: ["*"]
+  - methods: ["@data"]
+    allow: ["token:ops"]
+`
+
+func TestAuthorize(t *testing.T) {
+    a, _ := newTestAuthorizer(t, testPolicy)
+    tests := []struct {
+        name   string
+        ctx    context.Context
+        method string
+        code   codes.Code
+    }{
+        {name: "anyone on the data plane", ctx: callerContext("", ""), method: "/jina.JinaSingleDataRequestRPC/process_single_data", code: codes.OK},
+        {name: "pattern", ctx: callerContext("Bearer ops-secret", ""), method: "/RaftAdmin/AddVoter", code: codes.OK},
+        {name: "case-insensitive scheme", ctx: callerContext("bearer ops-secret", ""), method: "/RaftAdmin/AddVoter", code: codes.OK},
+        {name: "full method name", ctx: callerContext("Bearer ops-secret", ""), method: "/jraft.JinaRaftStatus/GetStatus", code: codes.OK},
+        {name: "certificate common name", ctx: callerContext("", "executor-0"), method: "/RaftAdmin/AddVoter", code: codes.OK},
+        {name: "certificate DNS name", ctx: callerContext("", "executor-1"), method: "/RaftAdmin/AddVoter", code: codes.OK},
+        {name: "first matching rule decides", ctx: callerContext("Bearer ops-secret", ""), method: "/RaftAdmin/Shutdown", code: codes.PermissionDenied},
+        {name: "first matching rule allows", ctx: callerContext("", "executor-0"), method: "/RaftAdmin/Shutdown", code: codes.OK},
+        {name: "token not allowed", ctx: callerContext("Bearer app-secret", ""), method: "/RaftAdmin/AddVoter", code: codes.PermissionDenied},
+        {name: "certificate not allowed", ctx: callerContext("", "executor-2"), method: "/RaftAdmin/AddVoter", code: codes.PermissionDenied},
+        {name: "unauthenticated", ctx: callerContext("", ""), method: "/RaftAdmin/AddVoter", code: codes.Unauthenticated},
+        {name: "invalid token", ctx: callerContext("Bearer wrong", ""), method: "/jina.JinaSingleDataRequestRPC/process_single_data", code: codes.Unauthenticated},
+        {name: "token prefix", ctx: callerContext("Bearer ops", ""), method: "/RaftAdmin/AddVoter", code: codes.Unauthenticated},
+        {name: "unsupported scheme", ctx: callerContext("Basic b3BzOnNlY3JldA==", ""), method: "/RaftAdmin/AddVoter", code: codes.Unauthenticated},
+        {name: "authenticated by a token", ctx: callerContext("Bearer app-secret", ""), method: "/RaftTransport/AppendEntries", code: codes.OK},
+        {name: "authenticated by a certificate", ctx: callerContext("", "executor-2"), method: "/RaftTransport/AppendEntries", code: codes.OK},
+        {name: "not authenticated", ctx: callerContext("", ""), method: "/RaftTransport/AppendEntries", code: codes.Unauthenticated},
+        {name: "no matching rule", ctx: callerContext("Bearer ops-secret", ""), method: "/jraft.JinaRaftAdmin/DeleteSnapshot", code: codes.PermissionDenied},
+        {name: "no matching rule unauthenticated", ctx: callerContext("", ""), method: "/jraft.JinaRaftAdmin/DeleteSnapshot", code: codes.Unauthenticated},
+    }
+    for _, test := range tests {
+        if code := status.Code(a.authorize(test.ctx, test.method)); code != test.code {
+            t.Errorf("%s: authorize(%s) = %v, want %v", test.name, test.method, code, test.code)
+        }
+    }
+}
+
+func TestDefaultAuthRules(t *testing.T) {
+    a, _ := newTestAuthorizer(t, `
+tokens:
+  - name: ops
+    token: ops-secret
+  - name: app
+    token: app-secret
+admins: ["token:ops", "cert:executor-0"]
+`)
+    if !a.defaults {
+        t.Fatal("the default rules do not apply to a policy without rules")
+    }
+    tests := []struct {
+        name   string
+        ctx    context.Context
+        method string
+        code   codes.Code
+    }{
+        {name: "data plane", ctx: callerContext("", ""), method: "/jina.JinaSingleDataRequestRPC/process_single_data", code: codes.OK},
+        {name: "raft plane", ctx: callerContext("", "executor-2"), method: "/RaftTransport/AppendEntries", code: codes.OK},
+        {name: "raft plane unauthenticated", ctx: callerContext("", ""), method: "/RaftTransport/AppendEntries", code: codes.Unauthenticated},
+        {name: "admin token", ctx: callerContext("Bearer ops-secret", ""), method: "/RaftAdmin/RemoveServer", code: codes.OK},
+        {name: "admin certificate", ctx: callerContext("", "executor-0"), method: "/jraft.JinaRaftAdmin/DeleteSnapshot", code: codes.OK},
+        {name: "data plane token", ctx: callerContext("Bearer app-secret", ""), method: "/RaftAdmin/Shutdown", code: codes.PermissionDenied},
+        {name: "other certificate", ctx: callerContext("", "executor-2"), method: "/RaftAdmin/RemoveServer", code: codes.PermissionDenied},
+        {name: "unauthenticated", ctx: callerContext("", ""), method: "/RaftAdmin/RemoveServer", code: codes.Unauthenticated},
+    }
+    for _, test := range tests {
+        if code := status.Code(a.authorize(test.ctx, test.method)); code != test.code {
+            t.Errorf("%s: authorize(%s) = %v, want %v", test.name, test.method, code, test.code)
+        }
+    }
+}
+
+func TestAuthPolicyErrors(t *testing.T) {
+    tests := []struct {
+        name    string
+        content string
+    }{
+        {name: "no rules nor admins", content: "tokens:\n  - name: ops\n    token: secret\n"},
+        {name: "any principal as admin", content: "admins: [\"*\"]\n"},
+        {name: "authenticated as admin", content: "admins: [authenticated]\n"},
+        {name: "token without name", content: "tokens:\n  - token: secret\nadmins: [token:ops]\n"},
+        {name: "token without secret", content: "tokens:\n  - name: ops\nadmins: [token:ops]\n"},
+        {name: "missing token file", content: "tokens:\n  - name: ops\n    token_file: /nonexistent\nadmins: [token:ops]\n"},
+        {name: "invalid YAML", content: "rules: [\n"},
+    }
+    for _, test := range tests {
+        path := filepath.Join(t.TempDir(), "policy.yml")
+        writePolicy(t, path, test.content)
+        if _, err := newAuthorizer(path); err == nil {
+            t.Errorf("%s: newAuthorizer() succeeded", test.name)
+        }
+    }
+}
+
+func TestAuthPolicyReload(t *testing.T) {
+    a, path := newTestAuthorizer(t, testPolicy)
+    ops := callerContext("Bearer ops-secret", "")
+    if err := a.authorize(ops, "/RaftAdmin/AddVoter"); err != nil {
+        t.Fatalf("authorize() = %v", err)
+    }
+
+    // the rotated token is used once the policy is checked again
+    writePolicy(t, path, "tokens:\n  - name: ops\n    token: rotated-secret\nadmins: [token:ops]\n")
+    a.checked = time.Time{}
+    if code := status.Code(a.authorize(ops, "/RaftAdmin/AddVoter")); code != codes.Unauthenticated {
+        t.Fatalf("authorize() with the previous token = %v, want %v", code, codes.Unauthenticated)
+    }
+    if err := a.authorize(callerContext("Bearer rotated-secret", ""), "/RaftAdmin/AddVoter"); err != nil {
+        t.Fatalf("authorize() with the rotated token = %v", err)
+    }
+
+    // an invalid policy is ignored, the previous one is kept
+    writePolicy(t, path, "rules: [\n")
+    a.checked = time.Time{}
+    if err := a.authorize(callerContext("Bearer rotated-secret", ""), "/RaftAdmin/AddVoter"); err != nil {
+        t.Fatalf("authorize() after an invalid policy = %v", err)
+    }
+}
@@ -0,0 +1,342 @@
+package server
+
+import (
+    "errors"
+    "flag"
+    "fmt"
+    "net"
+    "os"
+    "strconv"
+    "strings"
+    "time"
+
+    "github.com/hashicorp/raft"
+    hclog "github.com/hashicorp/go-hclog"
+    "gopkg.in/yaml.v3"
+)
+
+// EnvPrefix is prepended to the upper-cased setting name to build its environment variable,
+// e.g. JINA_RAFT_HEARTBEAT_TIMEOUT.
+const EnvPrefix = "JINA_RAFT_"
+
+// Setting is a single Node setting. Its name is used as CLI flag, configuration file key and,
+// upper-cased with EnvPrefix, as environment variable.
+type Setting struct {
+    Name  string
+    Usage string
+    Value flag.Value
+}
+
+// Settings binds every configurable field of opts to a Setting. It is the single source of truth
+// for the CLI flags, the configuration file, the environment overrides and the Python binding.
+func (opts *Options) Settings() []Setting {
+    return []Setting{
+        {"name", "name to identify in the logger the Node", (*stringValue)(&opts.Name)},
+        {"address", "TCP host+port for this node", (*stringValue)(&opts.Address)},
+        {"advertise_address", "host+port clients use to reach this node, defaults to address", (*stringValue)(&opts.AdvertiseAddress)},
+        {"raft_address", "TCP host+port for the RAFT transport, defaults to sharing address", (*stringValue)(&opts.RaftAddress)},
+        {"raft_advertise_address", "host+port the other RAFT nodes use to reach this node, defaults to raft_address or advertise_address", (*stringValue)(&opts.RaftAdvertiseAddress)},
+        {"raft_id", "Node id used by Raft", (*stringValue)(&opts.RaftID)},
+        {"raft_data_dir", "Raft data dir", (*stringValue)(&opts.RaftDir)},
+        {"executor_target", "underlying executor host+port, or unix:///path of its unix socket", (*stringValue)(&opts.ExecutorTarget)},
+        {"executor_socket", "Path of the unix socket of the executor, overrides executor_target", (*stringValue)(&opts.ExecutorSocket)},
+        {"admin_address", "TCP host+port for the admin services (raftadmin, JinaRaftAdmin, reflection), defaults to sharing address, requires raft_address", (*stringValue)(&opts.AdminAddress)},
+        {"metrics_address", "TCP host+port where the Prometheus metrics are served over HTTP at /metrics, disabled if empty", (*stringValue)(&opts.MetricsAddress)},
+        {"tracing_endpoint", "host+port of the OTLP/gRPC collector receiving the spans of the node, tracing is disabled if empty", (*stringValue)(&opts.TracingEndpoint)},
+        {"tracing_insecure", "Export the spans to tracing_endpoint without TLS", (*boolValue)(&opts.TracingInsecure)},
+        {"tracing_service_name", "Service name of the exported spans, defaults to RAFT-<name>", (*stringValue)(&opts.TracingServiceName)},
+        {"auth_policy_file", "YAML or JSON policy of the tokens and principals allowed to call each gRPC method, reloaded when it changes", (*stringValue)(&opts.AuthPolicyFile)},
+        {"auth_token_file", "Bearer token presented in the raftadmin calls to the other members", (*stringValue)(&opts.AuthTokenFile)},
+        {"tls_cert_file", "PEM certificate to serve TLS on address, reloaded when it changes", (*stringValue)(&opts.TLSCertFile)},
+        {"tls_key_file", "PEM private key of tls_cert_file", (*stringValue)(&opts.TLSKeyFile)},
+        {"tls_ca_file", "PEM CA verifying the client certificates, and the RAFT peers when they share address", (*stringValue)(&opts.TLSCAFile)},
+        {"tls_client_auth", "Require the clients of address to present a certificate signed by tls_ca_file", (*boolValue)(&opts.TLSClientAuth)},
+        {"raft_tls_cert_file", "PEM certificate of the RAFT transport on raft_address, also presented to the peers", (*stringValue)(&opts.RaftTLSCertFile)},
+        {"raft_tls_key_file", "PEM private key of raft_tls_cert_file", (*stringValue)(&opts.RaftTLSKeyFile)},
+        {"raft_tls_ca_file", "PEM CA verifying the RAFT peers", (*stringValue)(&opts.RaftTLSCAFile)},
+        {"raft_tls_verify_peers", "Require the RAFT peers to present a certificate signed by raft_tls_ca_file", (*boolValue)(&opts.RaftTLSVerifyPeers)},
+        {"executor_tls_cert_file", "PEM client certificate presented to the executor", (*stringValue)(&opts.ExecutorTLSCertFile)},
+        {"executor_tls_key_file", "PEM private key of executor_tls_cert_file", (*stringValue)(&opts.ExecutorTLSKeyFile)},
+        {"executor_tls_ca_file", "PEM CA verifying the executor certificate, enables TLS to the executor", (*stringValue)(&opts.ExecutorTLSCAFile)},
+        {"executor_tls_server_name", "Name verified in the executor certificate, defaults to the host of executor_target", (*stringValue)(&opts.ExecutorTLSServerName)},
+        {"executor_keepalive_time", "Time without activity after which the connection to the executor is pinged", &durationValue{&opts.ExecutorKeepaliveTime, time.Millisecond}},
+        {"executor_startup_timeout", "Maximum time to wait for the executor to answer when the node starts", &durationValue{&opts.ExecutorStartupTimeout, time.Millisecond}},
+        {"executor_call_attempts", "Number of times an idempotent call is sent while the executor is unavailable", (*intValue)(&opts.ExecutorCallAttempts)},
+        {"executor_breaker_failures", "Consecutive failed calls to the executor opening the circuit breaker, 0 disables it", (*intValue)(&opts.ExecutorBreakerFailures)},
+        {"executor_breaker_cooldown", "Time the executor circuit breaker stays open before letting a call through", &durationValue{&opts.ExecutorBreakerCooldown, time.Millisecond}},
+        {"executor_poll_interval", "Time before the first check of the status of an executor snapshot or restore, doubled after each check", &durationValue{&opts.ExecutorPollInterval, time.Millisecond}},
+        {"executor_poll_max_interval", "Maximum time between two checks of the status of an executor snapshot or restore", &durationValue{&opts.ExecutorPollMaxInterval, time.Millisecond}},
+        {"executor_apply_timeout", "Maximum time for the executor to apply a write, waiting for it to be reachable included", &durationValue{&opts.ExecutorApplyTimeout, time.Millisecond}},
+        {"executor_snapshot_timeout", "Maximum time for the executor to finish a snapshot, which fails once it elapsed", &durationValue{&opts.ExecutorSnapshotTimeout, time.Millisecond}},
+        {"executor_restore_timeout", "Maximum time for the executor to finish a restore, which fails once it elapsed", &durationValue{&opts.ExecutorRestoreTimeout, time.Millisecond}},
+        {"heartbeat_timeout", "HeartbeatTimeout for the RAFT node", &durationValue{&opts.HeartbeatTimeout, time.Millisecond}},
+        {"election_timeout", "ElectionTimeout for the RAFT node", &durationValue{&opts.ElectionTimeout, time.Millisecond}},
+        {"commit_timeout", "CommitTimeout for the RAFT node", &durationValue{&opts.CommitTimeout, time.Millisecond}},
+        {"max_append_entries", "MaxAppendEntries for the RAFT node", (*intValue)(&opts.MaxAppendEntries)},
+        {"batch_applych", "BatchApplyCh for the RAFT node", (*boolValue)(&opts.BatchApplyCh)},
+        {"shutdown_on_remove", "ShutdownOnRemove for the RAFT node", (*boolValue)(&opts.ShutdownOnRemove)},
+        {"trailing_logs", "TrailingLogs for the RAFT node", (*uint64Value)(&opts.TrailingLogs)},
+        {"snapshot_interval", "SnapshotInterval for the RAFT node", &durationValue{&opts.SnapshotInterval, time.Second}},
+        {"snapshot_threshold", "SnapshotThreshold for the RAFT node", (*uint64Value)(&opts.SnapshotThreshold)},
+        {"snapshot_retain", "Number of most recent snapshots kept in the snapshot store, besides the pinned ones", (*intValue)(&opts.SnapshotRetain)},
+        {"snapshot_compression", "Compression of the executor snapshots: none, gzip or zstd", (*stringValue)(&opts.SnapshotCompression)},
+        {"snapshot_key_file", "YAML or JSON keys encrypting the snapshots, reloaded when it changes", (*stringValue)(&opts.SnapshotKeyFile)},
+        {"snapshot_allow_plaintext", "Restore the unencrypted snapshots with snapshot_key_file", (*boolValue)(&opts.SnapshotAllowPlaintext)},
+        {"leader_lease_timeout", "LeaderLeaseTimeout for the RAFT node", &durationValue{&opts.LeaderLeaseTimeout, time.Millisecond}},
+        {"log_level", "LogLevel for the RAFT node", (*stringValue)(&opts.LogLevel)},
+        {"no_snapshot_restore_on_start", "NoSnapshotRestoreOnStart for the RAFT node", (*boolValue)(&opts.NoSnapshotRestoreOnStart)},
+        {"drain_timeout", "Time to drain writes and hand over leadership when decommissioning", &durationValue{&opts.DrainTimeout, time.Millisecond}},
+        {"decommission_on_sigterm", "Drain writes and transfer leadership before shutting down on SIGTERM", (*boolValue)(&opts.DecommissionOnSigterm)},
+        {"leave_cluster_on_decommission", "Remove the node from the RAFT configuration when decommissioning on SIGTERM", (*boolValue)(&opts.LeaveClusterOnDecommission)},
+    }
+}
+
+// RegisterFlags defines a flag for every Setting of opts in fs.
+func (opts *Options) RegisterFlags(fs *flag.FlagSet) {
+    for _, setting := range opts.Settings() {
+        fs.Var(setting.Value, setting.Name, setting.Usage)
+    }
+}
+
+// Set assigns the setting called name. Names are matched ignoring case and underscores,
+// so that both `heartbeat_timeout` and the Pascal-cased `HeartbeatTimeout` are accepted.
+func (opts *Options) Set(name string, value string) error {
+    key := normalizeSettingName(name)
+    for _, setting := range opts.Settings() {
+        if normalizeSettingName(setting.Name) == key {
+            if err := setting.Value.Set(value); err != nil {
+                return fmt.Errorf("invalid value %q for setting %q: %v", value, name, err)
+            }
+            return nil
+        }
+    }
+    return fmt.Errorf("unknown setting %q", name)
+}
+
+func normalizeSettingName(name string) string {
+    return strings.ToLower(strings.ReplaceAll(name, "_", ""))
+}
+
+// LoadOptions builds the Options of a Node. The defaults are overridden, in order, by the YAML or JSON
+// configuration file at path (if any), by the JINA_RAFT_* environment variables and by the explicit
+// overrides, e.g. the CLI flags set by the user or the keyword arguments of the Python binding.
+func LoadOptions(path string, overrides map[string]string) (Options, error) {
+    opts := DefaultOptions()
+    if path != "" {
+        if err := opts.loadFile(path); err != nil {
+            return opts, err
+        }
+    }
+    for _, setting := range opts.Settings() {
+        env := EnvPrefix + strings.ToUpper(setting.Name)
+        if value, ok := os.LookupEnv(env); ok {
+            if err := setting.Value.Set(value); err != nil {
+                return opts, fmt.Errorf("invalid value %q for environment variable %s: %v", value, env, err)
+            }
+        }
+    }
+    for name, value := range overrides {
+        if err := opts.Set(name, value); err != nil {
+            return opts, err
+        }
+    }
+    return opts, nil
+}
+
+// loadFile reads a YAML configuration file, JSON being accepted as a subset of YAML.
+// Durations are given as strings such as "500ms" or "2m".
+func (opts *Options) loadFile(path string) error {
+    content, err := os.ReadFile(path)
+    if err != nil {
+        return fmt.Errorf("reading configuration file %q: %v", path, err)
+    }
+    settings := map[string]interface{}{}
+    if err := yaml.Unmarshal(content, &settings); err != nil {
+        return fmt.Errorf("parsing configuration file %q: %v", path, err)
+    }
+    for name, value := range settings {
+        switch value.(type) {
+        case map[string]interface{}, []interface{}, nil:
+            return fmt.Errorf("configuration file %q: setting %q must be a scalar", path, name)
+        }
+        if err := opts.Set(name, fmt.Sprint(value)); err != nil {
+            return fmt.Errorf("configuration file %q: %v", path, err)
+        }
+    }
+    return nil
+}
+
+// Validate checks that the Options can be used to create a Node, including the validation of the
+// resulting Raft configuration through raft.ValidateConfig.
+func (opts Options) Validate() error {
+    if opts.RaftID == "" {
+        return errors.New("raft_id is required")
+    }
+    if _, _, err := net.SplitHostPort(opts.Address); err != nil {
+        return fmt.Errorf("invalid address %q: %v", opts.Address, err)
+    }
+    optional := map[string]string{
+        "advertise_address":      opts.AdvertiseAddress,
+        "raft_address":           opts.RaftAddress,
+        "raft_advertise_address": opts.RaftAdvertiseAddress,
+        "admin_address":          opts.AdminAddress,
+        "metrics_address":        opts.MetricsAddress,
+        "tracing_endpoint":       opts.TracingEndpoint,
+    }
+    for name, address := range optional {
+        if address == "" {
+            continue
+        }
+        if _, _, err := net.SplitHostPort(address); err != nil {
+            return fmt.Errorf("invalid %s %q: %v", name, address, err)
+        }
+    }
+    if opts.AdminAddress != "" && opts.RaftAddress == "" {
+        // the members call raftadmin and JinaRaftStatus at the Raft address, which would otherwise be address
+        return errors.New("admin_address requires raft_address, otherwise the admin services stay on address")
+    }
+    if opts.ExecutorTarget == "" && opts.ExecutorSocket == "" {
+        return errors.New("executor_target is required")
+    }
+    if opts.ExecutorSocket == "" && !strings.HasPrefix(opts.ExecutorTarget, "unix:") {
+        if _, _, err := net.SplitHostPort(opts.ExecutorTarget); err != nil {
+            return fmt.Errorf("invalid executor_target %q: %v", opts.ExecutorTarget, err)
+        }
+    }
+    if opts.RaftAddress == "" && (opts.RaftTLSCertFile != "" || opts.RaftTLSKeyFile != "" || opts.RaftTLSCAFile != "" || opts.RaftTLSVerifyPeers) {
+        return errors.New("raft_tls_* settings require raft_address, the RAFT transport otherwise shares the tls_* settings of address")
+    }
+    if opts.AuthTokenFile != "" {
+        certFile, caFile := opts.TLSCertFile, opts.TLSCAFile
+        if opts.RaftAddress != "" {
+            certFile, caFile = opts.RaftTLSCertFile, opts.RaftTLSCAFile
+        }
+        if certFile == "" && caFile == "" {
+            return errors.New("auth_token_file requires TLS to the other members, bearer tokens are not sent in plaintext")
+        }
+    }
+    if opts.DrainTimeout <= 0 {
+        return fmt.Errorf("drain_timeout must be positive, got %v", opts.DrainTimeout)
+    }
+    if opts.ExecutorKeepaliveTime < 10*time.Second {
+        return fmt.Errorf("executor_keepalive_time must be at least 10s, got %v", opts.ExecutorKeepaliveTime)
+    }
+    if opts.ExecutorCallAttempts < 1 {
+        return fmt.Errorf("executor_call_attempts must be at least 1, got %d", opts.ExecutorCallAttempts)
+    }
+    if opts.ExecutorBreakerFailures < 0 {
+        return fmt.Errorf("executor_breaker_failures cannot be negative, got %d", opts.ExecutorBreakerFailures)
+    }
+    if opts.SnapshotRetain < 1 {
+        return fmt.Errorf("snapshot_retain must be at least 1, got %d", opts.SnapshotRetain)
+    }
+    if _, err := parseSnapshotCodec(opts.SnapshotCompression); err != nil {
+        return err
+    }
+    if opts.ExecutorPollInterval <= 0 {
+        return fmt.Errorf("executor_poll_interval must be positive, got %v", opts.ExecutorPollInterval)
+    }
+    if opts.ExecutorPollMaxInterval < opts.ExecutorPollInterval {
+        return fmt.Errorf("executor_poll_max_interval must be at least executor_poll_interval (%v), got %v", opts.ExecutorPollInterval, opts.ExecutorPollMaxInterval)
+    }
+    if opts.ExecutorApplyTimeout <= 0 {
+        return fmt.Errorf("executor_apply_timeout must be positive, got %v", opts.ExecutorApplyTimeout)
+    }
+    if opts.ExecutorSnapshotTimeout <= 0 {
+        return fmt.Errorf("executor_snapshot_timeout must be positive, got %v", opts.ExecutorSnapshotTimeout)
+    }
+    if opts.ExecutorRestoreTimeout <= 0 {
+        return fmt.Errorf("executor_restore_timeout must be positive, got %v", opts.ExecutorRestoreTimeout)
+    }
+    if err := raft.ValidateConfig(opts.raftConfig(hclog.NewNullLogger())); err != nil {
+        return fmt.Errorf("invalid RAFT configuration: %v", err)
+    }
+    return nil
+}
+
+type stringValue string
+
+func (v *stringValue) Set(s string) error {
+    *v = stringValue(s)
+    return nil
+}
+
+func (v *stringValue) String() string {
+    return string(*v)
+}
+
+type boolValue bool
+
+func (v *boolValue) Set(s string) error {
+    b, err := strconv.ParseBool(s)
+    if err != nil {
+        return err
+    }
+    *v = boolValue(b)
+    return nil
+}
+
+func (v *boolValue) String() string {
+    return strconv.FormatBool(bool(*v))
+}
+
+func (v *boolValue) IsBoolFlag() bool {
+    return true
+}
+
+type intValue int
+
+func (v *intValue) Set(s string) error {
+    i, err := strconv.Atoi(s)
+    if err != nil {
+        return err
+    }
+    *v = intValue(i)
+    return nil
+}
+
+func (v *intValue) String() string {
+    return strconv.Itoa(int(*v))
+}
+
+type uint64Value uint64
+
+func (v *uint64Value) Set(s string) error {
+    u, err := strconv.ParseUint(s, 10, 64)
+    if err != nil {
+        return err
+    }
+    *v = uint64Value(u)
+    return nil
+}
+
+func (v *uint64Value) String() string {
+    return strconv.FormatUint(uint64(*v), 10)
+}
+
+// durationValue accepts duration strings such as "1.5s". For backwards compatibility, a bare
+// integer is interpreted in the unit the setting historically used (milliseconds or seconds).
+type durationValue struct {
+    duration *time.Duration
+    unit     time.Duration
+}
+
+func (v *durationValue) Set(s string) error {
+    if i, err := strconv.ParseInt(s, 10, 64); err == nil {
+        *v.duration = time.Duration(i) * v.unit
+        return nil
+    }
+    d, err := time.ParseDuration(s)
+    if err != nil {
+        return err
+    }
+    *v.duration = d
+    return nil
+}
+
+func (v *durationValue) String() string {
+    if v.duration == nil {
+        return ""
+    }
+    return v.duration.String()
+}
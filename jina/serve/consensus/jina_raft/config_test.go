@@ -0,0 +1,137 @@
+package server
+
+import (
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+)
+
+func writeConfig(t *testing.T, name string, content string) string {
+    t.Helper()
+    path := filepath.Join(t.TempDir(), name)
+    if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+        t.Fatal(err)
+    }
+    return path
+}
+
+func TestLoadOptionsFile(t *testing.T) {
+    tests := []struct {
+        name    string
+        file    string
+        content string
+        check   func(opts Options) bool
+    }{
+        {
+            name:    "duration string",
+            content: "heartbeat_timeout: 500ms\nexecutor_snapshot_timeout: 2m\n",
+            check: func(opts Options) bool {
+                return opts.HeartbeatTimeout == 500*time.Millisecond && opts.ExecutorSnapshotTimeout == 2*time.Minute
+            },
+        },
+        {
+            name:    "duration in the unit of the setting",
+            content: "election_timeout: 2000\nsnapshot_interval: 30\n",
+            check: func(opts Options) bool {
+                return opts.ElectionTimeout == 2*time.Second && opts.SnapshotInterval == 30*time.Second
+            },
+        },
+        {
+            name:    "Pascal-cased names",
+            content: "HeartbeatTimeout: 1s\nSnapshotThreshold: 1024\n",
+            check: func(opts Options) bool {
+                return opts.HeartbeatTimeout == time.Second && opts.SnapshotThreshold == 1024
+            },
+        },
+        {
+            name:    "integers, booleans and strings",
+            content: "trailing_logs: 10240\nmax_append_entries: 32\nbatch_applych: true\nlog_level: DEBUG\nsnapshot_compression: zstd\n",
+            check: func(opts Options) bool {
+                return opts.TrailingLogs == 10240 && opts.MaxAppendEntries == 32 && opts.BatchApplyCh && opts.LogLevel == "DEBUG" && opts.SnapshotCompression == "zstd"
+            },
+        },
+        {
+            name:    "JSON",
+            file:    "config.json",
+            content: `{"raft_id": "2", "commit_timeout": "50ms", "snapshot_retain": 3}`,
+            check: func(opts Options) bool {
+                return opts.RaftID == "2" && opts.CommitTimeout == 50*time.Millisecond && opts.SnapshotRetain == 3
+            },
+        },
+    }
+    for _, test := range tests {
+        file := test.file
+        if file == "" {
+            file = "config.yml"
+        }
+        opts, err := LoadOptions(writeConfig(t, file, test.content), nil)
+        if err != nil {
+            t.Errorf("%s: LoadOptions() = %v", test.name, err)
+            continue
+        }
+        if !test.check(opts) {
+            t.Errorf("%s: unexpected options %+v", test.name, opts)
+        }
+    }
+}
+
+func TestLoadOptionsFileErrors(t *testing.T) {
+    tests := []struct {
+        name    string
+        content string
+    }{
+        {name: "unknown setting", content: "heartbeat: 1s\n"},
+        {name: "invalid duration", content: "heartbeat_timeout: 1 second\n"},
+        {name: "invalid integer", content: "trailing_logs: -1\n"},
+        {name: "invalid boolean", content: "batch_applych: maybe\n"},
+        {name: "nested setting", content: "heartbeat_timeout:\n  value: 1s\n"},
+        {name: "list setting", content: "address: [localhost:50051]\n"},
+        {name: "empty setting", content: "log_level:\n"},
+        {name: "invalid YAML", content: "heartbeat_timeout: [\n"},
+    }
+    for _, test := range tests {
+        if _, err := LoadOptions(writeConfig(t, "config.yml", test.content), nil); err == nil {
+            t.Errorf("%s: LoadOptions() succeeded", test.name)
+        }
+    }
+
+    if _, err := LoadOptions(filepath.Join(t.TempDir(), "missing.yml"), nil); err == nil {
+        t.Error("LoadOptions() of a missing file succeeded")
+    }
+}
+
+func TestLoadOptionsPrecedence(t *testing.T) {
+    path := writeConfig(t, "config.yml", "heartbeat_timeout: 1s\nelection_timeout: 1s\ncommit_timeout: 1s\n")
+    t.Setenv(EnvPrefix+"ELECTION_TIMEOUT", "2s")
+    t.Setenv(EnvPrefix+"COMMIT_TIMEOUT", "2s")
+    opts, err := LoadOptions(path, map[string]string{"commit_timeout": "3000"})
+    if err != nil {
+        t.Fatal(err)
+    }
+    // the environment overrides the file, and the explicit overrides the environment
+    if opts.HeartbeatTimeout != time.Second || opts.ElectionTimeout != 2*time.Second || opts.CommitTimeout != 3*time.Second {
+        t.Fatalf("heartbeat, election and commit timeouts = %v, %v, %v, want 1s, 2s, 3s", opts.HeartbeatTimeout, opts.ElectionTimeout, opts.CommitTimeout)
+    }
+
+    t.Setenv(EnvPrefix+"HEARTBEAT_TIMEOUT", "soon")
+    if _, err := LoadOptions(path, nil); err == nil {
+        t.Fatal("LoadOptions() with an invalid environment variable succeeded")
+    }
+}
+
+func TestValidateAdminAddress(t *testing.T) {
+    opts := DefaultOptions()
+    opts.RaftID = "1"
+    opts.Address = "localhost:50051"
+    opts.ExecutorTarget = "localhost:50052"
+    opts.AdminAddress = "localhost:50071"
+    // the admin services would stay on address, where the members call them
+    if err := opts.Validate(); err == nil {
+        t.Fatal("Validate() of admin_address without raft_address succeeded")
+    }
+    opts.RaftAddress = "localhost:50061"
+    if err := opts.Validate(); err != nil {
+        t.Fatalf("Validate() of admin_address with raft_address = %v", err)
+    }
+}
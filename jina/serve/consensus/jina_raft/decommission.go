@@ -0,0 +1,194 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "sync"
+    "time"
+
+    "github.com/hashicorp/raft"
+)
+
+// ErrDraining is returned to write requests received after the node started decommissioning.
+var ErrDraining = errors.New("node is decommissioning and does not accept write requests")
+
+// writeGate tracks in-flight write requests so that they can be drained before leaving the cluster.
+// Writes arriving while it drains fail fast instead of queueing behind the drain.
+type writeGate struct {
+    mtx      sync.Mutex
+    inFlight int
+    draining bool
+    // idle is closed when the last in-flight write exits while draining
+    idle     chan struct{}
+}
+
+// enter registers a new in-flight write. It returns false if the gate is draining,
+// otherwise exit must be called once the write finished.
+func (g *writeGate) enter() bool {
+    g.mtx.Lock()
+    defer g.mtx.Unlock()
+    if g.draining {
+        return false
+    }
+    g.inFlight++
+    return true
+}
+
+func (g *writeGate) exit() {
+    g.mtx.Lock()
+    defer g.mtx.Unlock()
+    g.inFlight--
+    if g.inFlight == 0 && g.idle != nil {
+        close(g.idle)
+        g.idle = nil
+    }
+}
+
+// drain stops accepting writes and waits until the in-flight ones finished or ctx expires.
+func (g *writeGate) drain(ctx context.Context) error {
+    g.mtx.Lock()
+    g.draining = true
+    if g.inFlight == 0 {
+        g.mtx.Unlock()
+        return nil
+    }
+    if g.idle == nil {
+        g.idle = make(chan struct{})
+    }
+    idle := g.idle
+    g.mtx.Unlock()
+    select {
+    case <-idle:
+        return nil
+    case <-ctx.Done():
+        return fmt.Errorf("draining in-flight writes: %w", ctx.Err())
+    }
+}
+
+// resume accepts writes again after a failed drain or decommission.
+func (g *writeGate) resume() {
+    g.mtx.Lock()
+    defer g.mtx.Unlock()
+    g.draining = false
+}
+
+// DecommissionResult describes the outcome of Node.Decommission.
+type DecommissionResult struct {
+    WasLeader     bool
+    LeaderID      raft.ServerID
+    LeaderAddress raft.ServerAddress
+    LeftCluster   bool
+}
+
+// Decommission prepares the Node to be stopped without disturbing the cluster: it stops accepting
+// new writes, drains the in-flight ones, transfers leadership if the Node is the leader and, if
+// leaveCluster is set, removes the Node from the Raft configuration.
+// The Node accepts writes again if the decommission fails.
+func (n *Node) Decommission(ctx context.Context, leaveCluster bool) (DecommissionResult, error) {
+    result, err := n.decommission(ctx, leaveCluster)
+    if err != nil {
+        n.rpc.writes.resume()
+        n.logger.Info("Decommission failed, accepting write requests again")
+    }
+    return result, err
+}
+
+func (n *Node) decommission(ctx context.Context, leaveCluster bool) (DecommissionResult, error) {
+    result := DecommissionResult{}
+    n.logger.Info("Decommissioning RAFT node", "leave cluster", leaveCluster)
+
+    if err := n.rpc.writes.drain(ctx); err != nil {
+        n.logger.Error("Error draining in-flight writes", "error", err)
+        return result, err
+    }
+    n.logger.Debug("In-flight writes drained")
+
+    result.WasLeader = n.raft.State() == raft.Leader
+    if result.WasLeader {
+        // make sure everything committed by this leader is applied before handing over
+        if err := n.raft.Barrier(timeoutFromContext(ctx, n.opts.DrainTimeout)).Error(); err != nil {
+            n.logger.Error("Error waiting for the FSM to apply committed logs", "error", err)
+            return result, err
+        }
+        servers, err := n.servers()
+        if err != nil {
+            return result, err
+        }
+        if len(servers) > 1 {
+            n.logger.Info("Transferring leadership")
+            if err := n.raft.LeadershipTransfer().Error(); err != nil {
+                n.logger.Error("Error transferring leadership", "error", err)
+                return result, err
+            }
+        } else {
+            n.logger.Warn("Node is the only member of the cluster, leadership cannot be transferred")
+        }
+    }
+
+    leaderAddress, leaderID, err := n.waitForOtherLeader(ctx)
+    if err != nil {
+        n.logger.Error("Error waiting for a new leader", "error", err)
+        return result, err
+    }
+    result.LeaderID = leaderID
+    result.LeaderAddress = leaderAddress
+
+    if leaveCluster {
+        if leaderID == n.opts.raftServerID() {
+            n.logger.Warn("Node is still the leader, it will not leave the cluster")
+            return result, nil
+        }
+        n.logger.Info("Removing node from the cluster", "leader", leaderAddress)
+        if err := removeServer(ctx, n.adminDialOptions, string(leaderAddress), n.opts.RaftID); err != nil {
+            n.logger.Error("Error removing node from the cluster", "error", err)
+            return result, err
+        }
+        result.LeftCluster = true
+    }
+    n.logger.Info("RAFT node decommissioned", "leader", leaderAddress, "left cluster", result.LeftCluster)
+    return result, nil
+}
+
+func (n *Node) servers() ([]raft.Server, error) {
+    future := n.raft.GetConfiguration()
+    if err := future.Error(); err != nil {
+        n.logger.Error("Error getting the RAFT configuration", "error", err)
+        return nil, err
+    }
+    return future.Configuration().Servers, nil
+}
+
+// waitForOtherLeader waits until a leader is known. When the Node is part of a multi-node cluster,
+// it also waits until the leader is another Node.
+func (n *Node) waitForOtherLeader(ctx context.Context) (raft.ServerAddress, raft.ServerID, error) {
+    ticker := time.NewTicker(50 * time.Millisecond)
+    defer ticker.Stop()
+    for {
+        address, id := n.raft.LeaderWithID()
+        if id != "" {
+            if id != n.opts.raftServerID() {
+                return address, id, nil
+            }
+            servers, err := n.servers()
+            if err != nil {
+                return "", "", err
+            }
+            if len(servers) <= 1 {
+                return address, id, nil
+            }
+        }
+        select {
+        case <-ticker.C:
+        case <-ctx.Done():
+            return "", "", fmt.Errorf("waiting for a new leader: %w", ctx.Err())
+        }
+    }
+}
+
+func timeoutFromContext(ctx context.Context, fallback time.Duration) time.Duration {
+    if deadline, ok := ctx.Deadline(); ok {
+        return time.Until(deadline)
+    }
+    return fallback
+}
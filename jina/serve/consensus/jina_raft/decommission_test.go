@@ -0,0 +1,74 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "testing"
+    "time"
+)
+
+func TestWriteGateDrain(t *testing.T) {
+    g := &writeGate{}
+    if !g.enter() {
+        t.Fatal("enter() = false before draining")
+    }
+
+    ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+    defer cancel()
+    drained := make(chan error, 1)
+    go func() { drained <- g.drain(ctx) }()
+
+    // writes arriving during the drain fail fast instead of waiting for it
+    deadline := time.Now().Add(time.Second)
+    for g.enter() {
+        g.exit()
+        if time.Now().After(deadline) {
+            t.Fatal("enter() still accepts writes while draining")
+        }
+        time.Sleep(time.Millisecond)
+    }
+    if err := <-drained; !errors.Is(err, context.DeadlineExceeded) {
+        t.Fatalf("drain() with an in-flight write = %v, want %v", err, context.DeadlineExceeded)
+    }
+
+    g.resume()
+    if !g.enter() {
+        t.Fatal("enter() = false after resume")
+    }
+    g.exit()
+    g.exit()
+
+    if err := g.drain(context.Background()); err != nil {
+        t.Fatalf("drain() without in-flight writes = %v", err)
+    }
+    if g.enter() {
+        t.Fatal("enter() = true after drain")
+    }
+}
+
+func TestWriteGateDrainWaitsForInFlightWrites(t *testing.T) {
+    g := &writeGate{}
+    for i := 0; i < 2; i++ {
+        if !g.enter() {
+            t.Fatal("enter() = false before draining")
+        }
+    }
+    drained := make(chan error, 1)
+    go func() { drained <- g.drain(context.Background()) }()
+
+    g.exit()
+    select {
+    case err := <-drained:
+        t.Fatalf("drain() = %v with a write still in flight", err)
+    case <-time.After(20 * time.Millisecond):
+    }
+    g.exit()
+    select {
+    case err := <-drained:
+        if err != nil {
+            t.Fatalf("drain() = %v", err)
+        }
+    case <-time.After(time.Second):
+        t.Fatal("drain() did not return after the last write exited")
+    }
+}
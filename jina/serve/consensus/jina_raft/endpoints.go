@@ -0,0 +1,152 @@
+package server
+
+import (
+    "context"
+    "sort"
+    "time"
+
+    "github.com/hashicorp/raft"
+    "google.golang.org/grpc"
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/types/known/emptypb"
+    pb "jraft/jina-go-proto"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+const (
+    // endpointsRefreshInterval is the time between two discoveries of the write endpoints by the leader
+    endpointsRefreshInterval = 10 * time.Second
+    // endpointsTimeout bounds the discovery of the write endpoints and the commit of a new set
+    endpointsTimeout         = 5 * time.Second
+)
+
+// discoverWriteEndpoints asks the Executor for the endpoints whose requests have to be replicated.
+func (fsm *executorFSM) discoverWriteEndpoints(ctx context.Context) ([]string, error) {
+    var response *pb.EndpointsProto
+    err := fsm.executor.invoke(ctx, "EndpointDiscovery", func(ctx context.Context, conn *grpc.ClientConn) (err error) {
+        response, err = pb.NewJinaDiscoverEndpointsRPCClient(conn).EndpointDiscovery(ctx, &emptypb.Empty{})
+        return err
+    })
+    if err != nil {
+        return nil, err
+    }
+    return response.WriteEndpoints, nil
+}
+
+// writeEndpoints returns the write endpoints committed through the log. Until the leader commits a set, the
+// endpoints discovered from the local Executor at startup are used.
+func (fsm *executorFSM) writeEndpoints() []string {
+    if replicated := fsm.replicatedEndpoints.Load(); replicated != nil {
+        return replicated.Endpoints
+    }
+    return fsm.write_endpoints
+}
+
+func (fsm *executorFSM) isWriteEndpoint(endpoint string) bool {
+    for _, s := range fsm.writeEndpoints() {
+        if s == endpoint {
+            return true
+        }
+    }
+    return false
+}
+
+// otherEndpoint is the endpoint label of the requests to an endpoint the Executor does not expose, so that the
+// endpoints sent by the clients cannot grow the metrics without bound.
+const otherEndpoint = "other"
+
+// endpointLabel returns the label of endpoint in the request metrics: the endpoint itself if it is a write
+// endpoint or one discovered from the Executor, otherEndpoint otherwise.
+func (fsm *executorFSM) endpointLabel(endpoint string) string {
+    if fsm.isWriteEndpoint(endpoint) {
+        return endpoint
+    }
+    if endpoints := fsm.endpoints.Load(); endpoints != nil {
+        for _, s := range *endpoints {
+            if s == endpoint {
+                return endpoint
+            }
+        }
+    }
+    return otherEndpoint
+}
+
+// applyConfiguration applies a COMMAND_CONFIGURATION entry.
+func (fsm *executorFSM) applyConfiguration(entry *jraftpb.LogEntry) *applyResult {
+    command := &jraftpb.ConfigurationCommand{}
+    if err := proto.Unmarshal(entry.Payload, command); err != nil {
+        fsm.logger.Error("Error while unmarshalling a configuration command", "error", err)
+        return &applyResult{err: err}
+    }
+    if command.WriteEndpoints != nil {
+        fsm.logger.Info("Applying replicated write endpoints", "endpoints", command.WriteEndpoints.Endpoints)
+        fsm.replicatedEndpoints.Store(command.WriteEndpoints)
+    }
+    return &applyResult{}
+}
+
+// watchWriteEndpoints commits the write endpoints discovered from the Executor of the leader whenever they differ
+// from the replicated set: when the Node becomes the leader, then every endpointsRefreshInterval, so that a
+// redeployed Executor exposing other endpoints is picked up. It returns when stopCh is closed.
+func (n *Node) watchWriteEndpoints(stopCh <-chan struct{}) {
+    observations := make(chan raft.Observation, 1)
+    observer := raft.NewObserver(observations, false, func(o *raft.Observation) bool {
+        state, ok := o.Data.(raft.RaftState)
+        return ok && state == raft.Leader
+    })
+    n.raft.RegisterObserver(observer)
+    defer n.raft.DeregisterObserver(observer)
+    ticker := time.NewTicker(endpointsRefreshInterval)
+    defer ticker.Stop()
+    for {
+        select {
+        case <-observations:
+        case <-ticker.C:
+        case <-stopCh:
+            return
+        }
+        if n.raft.State() == raft.Leader {
+            n.refreshWriteEndpoints()
+        }
+    }
+}
+
+func (n *Node) refreshWriteEndpoints() {
+    ctx, cancel := context.WithTimeout(context.Background(), endpointsTimeout)
+    defer cancel()
+    endpoints, err := n.fsm.discoverWriteEndpoints(ctx)
+    if err != nil {
+        n.logger.Warn("Could not discover the write endpoints of the Executor", "error", err)
+        return
+    }
+    sort.Strings(endpoints)
+    if replicated := n.fsm.replicatedEndpoints.Load(); replicated != nil && equalEndpoints(replicated.Endpoints, endpoints) {
+        return
+    }
+    payload, err := proto.Marshal(&jraftpb.ConfigurationCommand{WriteEndpoints: &jraftpb.WriteEndpoints{Endpoints: endpoints}})
+    if err != nil {
+        n.logger.Error("Error marshalling the write endpoints", "error", err)
+        return
+    }
+    data, err := encodeEntry(newEntry(jraftpb.CommandType_COMMAND_CONFIGURATION, payload))
+    if err != nil {
+        n.logger.Error("Error encoding log entry", "error", err)
+        return
+    }
+    n.logger.Info("Replicating the write endpoints of the Executor", "endpoints", endpoints)
+    if err := n.raft.Apply(data, endpointsTimeout).Error(); err != nil {
+        n.logger.Warn("Could not replicate the write endpoints", "error", err)
+    }
+}
+
+func equalEndpoints(a, b []string) bool {
+    if len(a) != len(b) {
+        return false
+    }
+    for i := range a {
+        if a[i] != b[i] {
+            return false
+        }
+    }
+    return true
+}
@@ -0,0 +1,54 @@
+package server
+
+import (
+    "fmt"
+    "time"
+
+    "google.golang.org/protobuf/proto"
+    "google.golang.org/protobuf/types/known/timestamppb"
+
+    jraftpb "jraft/jraft-go-proto"
+)
+
+// entryPrefix marks the log entries holding a LogEntry. A serialized DataRequestProto never starts with 0x00,
+// as field number 0 is invalid, so the entries written as a bare DataRequestProto are told apart.
+const entryPrefix byte = 0x00
+
+// entryVersion is the version of the LogEntry envelope written by this node, and the latest it can decode.
+// Version 0 is the envelope holding only a data request and its trace context.
+const entryVersion uint32 = 1
+
+// newEntry returns the envelope of a command of type commandType, appended by the leader now.
+func newEntry(commandType jraftpb.CommandType, payload []byte) *jraftpb.LogEntry {
+    return &jraftpb.LogEntry{
+        Version:    entryVersion,
+        Type:       commandType,
+        Payload:    payload,
+        LeaderTime: timestamppb.New(time.Now()),
+    }
+}
+
+// encodeEntry returns the data of the log entry replicating entry.
+func encodeEntry(entry *jraftpb.LogEntry) ([]byte, error) {
+    data, err := proto.Marshal(entry)
+    if err != nil {
+        return nil, err
+    }
+    return append([]byte{entryPrefix}, data...), nil
+}
+
+// decodeEntry parses the data of a log entry. A bare DataRequestProto, written before the envelope existed, is
+// returned as a data request command of version 0.
+func decodeEntry(data []byte) (*jraftpb.LogEntry, error) {
+    if len(data) == 0 || data[0] != entryPrefix {
+        return &jraftpb.LogEntry{Type: jraftpb.CommandType_COMMAND_DATA_REQUEST, Payload: data}, nil
+    }
+    entry := &jraftpb.LogEntry{}
+    if err := proto.Unmarshal(data[1:], entry); err != nil {
+        return nil, err
+    }
+    if entry.Version > entryVersion {
+        return nil, fmt.Errorf("log entry version %d is not supported, this node supports up to version %d", entry.Version, entryVersion)
+    }
+    return entry, nil
+}
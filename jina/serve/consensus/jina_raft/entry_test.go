@@ -0,0 +1,98 @@
+package server
+
+import (
+    "testing"
+
+    "google.golang.org/protobuf/proto"
+
+    pb "jraft/jina-go-proto"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+func mustMarshal(t *testing.T, message proto.Message) []byte {
+    t.Helper()
+    data, err := proto.Marshal(message)
+    if err != nil {
+        t.Fatal(err)
+    }
+    return data
+}
+
+func TestDecodeEntry(t *testing.T) {
+    endpoint := "/index"
+    request := mustMarshal(t, &pb.DataRequestProto{Header: &pb.HeaderProto{RequestId: "r1", ExecEndpoint: &endpoint}})
+    if len(request) == 0 || request[0] == entryPrefix {
+        t.Fatalf("the data request starts with the entry prefix: %x", request)
+    }
+    envelope := func(entry *jraftpb.LogEntry) []byte {
+        return append([]byte{entryPrefix}, mustMarshal(t, entry)...)
+    }
+
+    tests := []struct {
+        name    string
+        data    []byte
+        want    *jraftpb.LogEntry
+        wantErr bool
+    }{
+        {
+            name: "bare data request",
+            data: request,
+            want: &jraftpb.LogEntry{Type: jraftpb.CommandType_COMMAND_DATA_REQUEST, Payload: request},
+        },
+        {
+            name: "empty entry",
+            data: nil,
+            want: &jraftpb.LogEntry{Type: jraftpb.CommandType_COMMAND_DATA_REQUEST},
+        },
+        {
+            name: "version 0 envelope",
+            data: envelope(&jraftpb.LogEntry{Payload: request, TraceContext: map[string]string{"traceparent": "00-01-02-01"}}),
+            want: &jraftpb.LogEntry{Type: jraftpb.CommandType_COMMAND_DATA_REQUEST, Payload: request, TraceContext: map[string]string{"traceparent": "00-01-02-01"}},
+        },
+        {
+            name: "current envelope",
+            data: envelope(&jraftpb.LogEntry{Version: entryVersion, Type: jraftpb.CommandType_COMMAND_NOOP, RequestId: "r2"}),
+            want: &jraftpb.LogEntry{Version: entryVersion, Type: jraftpb.CommandType_COMMAND_NOOP, RequestId: "r2"},
+        },
+        {
+            name:    "later envelope version",
+            data:    envelope(&jraftpb.LogEntry{Version: entryVersion + 1, Type: jraftpb.CommandType_COMMAND_NOOP}),
+            wantErr: true,
+        },
+        {
+            name:    "invalid envelope",
+            data:    []byte{entryPrefix, 0xff, 0xff},
+            wantErr: true,
+        },
+    }
+    for _, test := range tests {
+        got, err := decodeEntry(test.data)
+        if (err != nil) != test.wantErr {
+            t.Errorf("%s: decodeEntry() error = %v, want error %v", test.name, err, test.wantErr)
+            continue
+        }
+        if !test.wantErr && !proto.Equal(got, test.want) {
+            t.Errorf("%s: decodeEntry() = %v, want %v", test.name, got, test.want)
+        }
+    }
+}
+
+func TestEncodeEntryRoundTrip(t *testing.T) {
+    entry := newEntry(jraftpb.CommandType_COMMAND_CONFIGURATION, []byte("payload"))
+    entry.RequestId = "r1"
+    entry.Session = &jraftpb.ClientSession{ClientId: "c1", Sequence: 3}
+    data, err := encodeEntry(entry)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if data[0] != entryPrefix {
+        t.Fatalf("encodeEntry() starts with %x, want the entry prefix", data[0])
+    }
+    decoded, err := decodeEntry(data)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if !proto.Equal(decoded, entry) {
+        t.Fatalf("decodeEntry(encodeEntry()) = %v, want %v", decoded, entry)
+    }
+}
@@ -0,0 +1,122 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "time"
+
+    "github.com/hashicorp/raft"
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrorDomain is the domain of the ErrorInfo details attached to the errors of the data requests.
+const ErrorDomain = "jraft.jina.ai"
+
+// Reasons of the ErrorInfo details, the leader_id and leader_address metadata hold the known leader if any.
+const (
+    // ReasonNotLeader is returned with FAILED_PRECONDITION when a write reaches a follower, send it to the leader
+    ReasonNotLeader      = "NOT_LEADER"
+    // ReasonNoLeader is returned with UNAVAILABLE while no leader is known, retry once one is elected
+    ReasonNoLeader       = "NO_LEADER"
+    // ReasonDraining is returned with UNAVAILABLE when the node is decommissioning
+    ReasonDraining       = "DRAINING"
+    // ReasonShutdown is returned with UNAVAILABLE when Raft is shut down
+    ReasonShutdown       = "SHUTDOWN"
+    // ReasonBackpressure is returned with RESOURCE_EXHAUSTED when the write could not be enqueued in time
+    ReasonBackpressure   = "ENQUEUE_TIMEOUT"
+    // ReasonLeadershipLost is returned with ABORTED when the leader lost its leadership before the write was
+    // committed. The write may still be committed by the next leader, so it is not safe to retry blindly
+    ReasonLeadershipLost = "LEADERSHIP_LOST"
+)
+
+// Delays of the RetryInfo details.
+const (
+    retryAfterNoLeader     = time.Second
+    retryAfterBackpressure = 100 * time.Millisecond
+    retryAfterDraining     = 100 * time.Millisecond
+)
+
+// leaderWithID returns the known leader. Followers only learn the leader address from the Raft transport,
+// its ID is then looked up in the configuration.
+func leaderWithID(r *raft.Raft) (raft.ServerAddress, raft.ServerID) {
+    address, id := r.LeaderWithID()
+    if id != "" || address == "" {
+        return address, id
+    }
+    future := r.GetConfiguration()
+    if future.Error() != nil {
+        return address, id
+    }
+    for _, server := range future.Configuration().Servers {
+        if server.Address == address {
+            return address, server.ID
+        }
+    }
+    return address, id
+}
+
+// withDetails returns a status error with an ErrorInfo of reason, holding the known leader, and a RetryInfo
+// when retryAfter is not negative.
+func (rpc *RpcInterface) withDetails(code codes.Code, reason string, err error, retryAfter time.Duration) error {
+    st := status.New(code, err.Error())
+    info := &errdetails.ErrorInfo{Reason: reason, Domain: ErrorDomain}
+    if address, id := leaderWithID(rpc.Raft); address != "" {
+        info.Metadata = map[string]string{"leader_id": string(id), "leader_address": string(address)}
+    }
+    var withDetails *status.Status
+    var detailsErr error
+    if retryAfter >= 0 {
+        withDetails, detailsErr = st.WithDetails(info, &errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)})
+    } else {
+        withDetails, detailsErr = st.WithDetails(info)
+    }
+    if detailsErr != nil {
+        rpc.Logger.Error("Error attaching details to a gRPC status", "error", detailsErr)
+        return st.Err()
+    }
+    return withDetails.Err()
+}
+
+// writeError maps an error of a write request to a gRPC status: the errors returned by the Executor keep their
+// status, the Raft errors get the code and details telling the client where and when to retry.
+func (rpc *RpcInterface) writeError(err error) error {
+    if _, ok := status.FromError(err); ok {
+        return err
+    }
+    switch {
+    case errors.Is(err, ErrDraining):
+        return rpc.withDetails(codes.Unavailable, ReasonDraining, err, retryAfterDraining)
+    case errors.Is(err, raft.ErrEnqueueTimeout):
+        return rpc.withDetails(codes.ResourceExhausted, ReasonBackpressure, err, retryAfterBackpressure)
+    case errors.Is(err, raft.ErrRaftShutdown):
+        return rpc.withDetails(codes.Unavailable, ReasonShutdown, err, -1)
+    case errors.Is(err, raft.ErrLeadershipLost):
+        return rpc.withDetails(codes.Aborted, ReasonLeadershipLost, err, -1)
+    case errors.Is(err, raft.ErrNotLeader), errors.Is(err, raft.ErrLeadershipTransferInProgress):
+        if address, _ := rpc.Raft.LeaderWithID(); address != "" && rpc.getRaftState() != raft.Leader {
+            return rpc.withDetails(codes.FailedPrecondition, ReasonNotLeader, err, 0)
+        }
+        return rpc.withDetails(codes.Unavailable, ReasonNoLeader, err, retryAfterNoLeader)
+    case errors.Is(err, context.DeadlineExceeded):
+        return status.Error(codes.DeadlineExceeded, err.Error())
+    case errors.Is(err, context.Canceled):
+        return status.Error(codes.Canceled, err.Error())
+    }
+    return status.Error(codes.Unknown, err.Error())
+}
+
+// joinErrors returns nil without errors, else an error matching the first one and listing the others.
+func joinErrors(errs ...error) error {
+    if len(errs) == 0 {
+        return nil
+    }
+    err := errs[0]
+    for _, other := range errs[1:] {
+        err = fmt.Errorf("%w; %v", err, other)
+    }
+    return err
+}
@@ -0,0 +1,168 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "testing"
+    "time"
+
+    hclog "github.com/hashicorp/go-hclog"
+    "github.com/hashicorp/raft"
+    "google.golang.org/genproto/googleapis/rpc/errdetails"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+// newTestCluster starts a cluster of size in-memory Raft nodes, and returns them once they all know the leader.
+func newTestCluster(t *testing.T, size int) []*raft.Raft {
+    t.Helper()
+    transports := make([]*raft.InmemTransport, size)
+    configuration := raft.Configuration{}
+    for i := range transports {
+        _, transports[i] = raft.NewInmemTransport("")
+        id := raft.ServerID(fmt.Sprint(i + 1))
+        configuration.Servers = append(configuration.Servers, raft.Server{ID: id, Address: transports[i].LocalAddr()})
+    }
+    for _, a := range transports {
+        for _, b := range transports {
+            a.Connect(b.LocalAddr(), b)
+        }
+    }
+    nodes := make([]*raft.Raft, size)
+    for i, transport := range transports {
+        config := raft.DefaultConfig()
+        config.LocalID = configuration.Servers[i].ID
+        config.HeartbeatTimeout = 50 * time.Millisecond
+        config.ElectionTimeout = 50 * time.Millisecond
+        config.LeaderLeaseTimeout = 50 * time.Millisecond
+        config.Logger = hclog.NewNullLogger()
+        store := raft.NewInmemStore()
+        snapshots := raft.NewInmemSnapshotStore()
+        if err := raft.BootstrapCluster(config, store, store, snapshots, transport, configuration); err != nil {
+            t.Fatal(err)
+        }
+        r, err := raft.NewRaft(config, &raft.MockFSM{}, store, store, snapshots, transport)
+        if err != nil {
+            t.Fatal(err)
+        }
+        nodes[i] = r
+        t.Cleanup(func() { r.Shutdown().Error() })
+    }
+    deadline := time.Now().Add(5 * time.Second)
+    for time.Now().Before(deadline) {
+        leaderKnown := true
+        for _, r := range nodes {
+            if address, _ := r.LeaderWithID(); address == "" {
+                leaderKnown = false
+            }
+        }
+        if leaderKnown {
+            return nodes
+        }
+        time.Sleep(10 * time.Millisecond)
+    }
+    t.Fatal("no leader elected")
+    return nil
+}
+
+func TestWriteError(t *testing.T) {
+    nodes := newTestCluster(t, 2)
+    leader, follower := nodes[0], nodes[1]
+    if follower.State() == raft.Leader {
+        leader, follower = follower, leader
+    }
+    leaderAddress, leaderID := leader.LeaderWithID()
+
+    const noRetry = time.Duration(-1)
+    tests := []struct {
+        name       string
+        raft       *raft.Raft
+        err        error
+        code       codes.Code
+        reason     string
+        retryAfter time.Duration
+    }{
+        {name: "draining", raft: leader, err: ErrDraining, code: codes.Unavailable, reason: ReasonDraining, retryAfter: retryAfterDraining},
+        {name: "enqueue timeout", raft: leader, err: raft.ErrEnqueueTimeout, code: codes.ResourceExhausted, reason: ReasonBackpressure, retryAfter: retryAfterBackpressure},
+        {name: "shutdown", raft: leader, err: raft.ErrRaftShutdown, code: codes.Unavailable, reason: ReasonShutdown, retryAfter: noRetry},
+        {name: "leadership lost", raft: leader, err: raft.ErrLeadershipLost, code: codes.Aborted, reason: ReasonLeadershipLost, retryAfter: noRetry},
+        {name: "wrapped leadership lost", raft: leader, err: fmt.Errorf("applying: %w", raft.ErrLeadershipLost), code: codes.Aborted, reason: ReasonLeadershipLost, retryAfter: noRetry},
+        {name: "not leader on a follower", raft: follower, err: raft.ErrNotLeader, code: codes.FailedPrecondition, reason: ReasonNotLeader, retryAfter: 0},
+        {name: "leadership transfer on a follower", raft: follower, err: raft.ErrLeadershipTransferInProgress, code: codes.FailedPrecondition, reason: ReasonNotLeader, retryAfter: 0},
+        {name: "not leader on the leader", raft: leader, err: raft.ErrNotLeader, code: codes.Unavailable, reason: ReasonNoLeader, retryAfter: retryAfterNoLeader},
+        {name: "deadline", raft: leader, err: context.DeadlineExceeded, code: codes.DeadlineExceeded},
+        {name: "canceled", raft: leader, err: fmt.Errorf("waiting: %w", context.Canceled), code: codes.Canceled},
+        {name: "other", raft: leader, err: errors.New("boom"), code: codes.Unknown},
+        {name: "executor status", raft: leader, err: status.Error(codes.InvalidArgument, "bad request"), code: codes.InvalidArgument},
+    }
+    for _, test := range tests {
+        rpc := &RpcInterface{Raft: test.raft, Logger: hclog.NewNullLogger()}
+        st, ok := status.FromError(rpc.writeError(test.err))
+        if !ok {
+            t.Errorf("%s: writeError() is not a gRPC status", test.name)
+            continue
+        }
+        if st.Code() != test.code {
+            t.Errorf("%s: code = %v, want %v", test.name, st.Code(), test.code)
+        }
+        var info *errdetails.ErrorInfo
+        var retry *errdetails.RetryInfo
+        for _, detail := range st.Details() {
+            switch detail := detail.(type) {
+            case *errdetails.ErrorInfo:
+                info = detail
+            case *errdetails.RetryInfo:
+                retry = detail
+            }
+        }
+        if test.reason == "" {
+            if info != nil || retry != nil {
+                t.Errorf("%s: unexpected details %v", test.name, st.Details())
+            }
+            continue
+        }
+        if info == nil || info.Reason != test.reason || info.Domain != ErrorDomain {
+            t.Errorf("%s: ErrorInfo = %v, want reason %s in %s", test.name, info, test.reason, ErrorDomain)
+            continue
+        }
+        if info.Metadata["leader_id"] != string(leaderID) || info.Metadata["leader_address"] != string(leaderAddress) {
+            t.Errorf("%s: ErrorInfo metadata = %v, want leader %s at %s", test.name, info.Metadata, leaderID, leaderAddress)
+        }
+        switch {
+        case test.retryAfter < 0 && retry != nil:
+            t.Errorf("%s: RetryInfo = %v, want none", test.name, retry)
+        case test.retryAfter >= 0 && (retry == nil || retry.RetryDelay.AsDuration() != test.retryAfter):
+            t.Errorf("%s: RetryInfo = %v, want a delay of %v", test.name, retry, test.retryAfter)
+        }
+    }
+}
+
+func TestJoinErrors(t *testing.T) {
+    first := errors.New("raft shutdown failed")
+    second := errors.New("executor close failed")
+    tests := []struct {
+        name    string
+        errs    []error
+        message string
+    }{
+        {name: "no error"},
+        {name: "one error", errs: []error{first}, message: "raft shutdown failed"},
+        {name: "two errors", errs: []error{first, second}, message: "raft shutdown failed; executor close failed"},
+    }
+    for _, test := range tests {
+        err := joinErrors(test.errs...)
+        if test.errs == nil {
+            if err != nil {
+                t.Errorf("%s: joinErrors() = %v, want nil", test.name, err)
+            }
+            continue
+        }
+        if err == nil || err.Error() != test.message {
+            t.Errorf("%s: joinErrors() = %v, want %q", test.name, err, test.message)
+        }
+        if !errors.Is(err, first) {
+            t.Errorf("%s: joinErrors() = %v, does not match the first error", test.name, err)
+        }
+    }
+}
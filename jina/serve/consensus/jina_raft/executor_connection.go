@@ -1,29 +1,246 @@
 package server
 
 import (
+    "context"
+    "sync"
+    "time"
+
     "google.golang.org/grpc/credentials/insecure"
     "google.golang.org/grpc"
+    "google.golang.org/grpc/backoff"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/keepalive"
+    "google.golang.org/grpc/status"
     hclog "github.com/hashicorp/go-hclog"
+    "github.com/prometheus/client_golang/prometheus"
+    jraftpb "jraft/jraft-go-proto"
 )
 
 func defaultExecutorDialOptions() []grpc.DialOption {
     return []grpc.DialOption{
         grpc.WithTransportCredentials(insecure.NewCredentials()),
-        grpc.WithDefaultCallOptions(grpc.WaitForReady(true)),
     }
 }
 
+// executorPolicy tunes the connection to the Executor.
+type executorPolicy struct {
+    // keepaliveTime is the time without activity after which the connection is pinged, keepaliveTimeout the
+    // time to wait for the ping to be acknowledged before closing it
+    keepaliveTime    time.Duration
+    keepaliveTimeout time.Duration
+    // startupTimeout bounds the wait for the Executor to answer when the Node starts
+    startupTimeout   time.Duration
+    // callAttempts is the number of times an idempotent call is sent while the Executor is unavailable
+    callAttempts     int
+    // callBackoff is the time between the first two attempts of an idempotent call, doubled after each one
+    callBackoff      time.Duration
+    // breakerFailures is the number of consecutive failed calls opening the circuit breaker, 0 disables it
+    breakerFailures  int
+    // breakerCooldown is the time the circuit breaker stays open before letting a call through
+    breakerCooldown  time.Duration
+    // pollInterval is the time before the first check of the status of a snapshot or a restore, doubled after
+    // each check up to pollMaxInterval
+    pollInterval     time.Duration
+    pollMaxInterval  time.Duration
+    // applyTimeout bounds a write sent to the Executor, waiting for it to be reachable included
+    applyTimeout     time.Duration
+    // snapshotTimeout and restoreTimeout bound the time the Executor takes to finish a snapshot or a restore
+    snapshotTimeout  time.Duration
+    restoreTimeout   time.Duration
+    // snapshotCodec compresses the snapshots of the Executor
+    snapshotCodec    jraftpb.SnapshotCodec
+}
+
+func defaultExecutorPolicy() executorPolicy {
+    return executorPolicy{
+        // the Jina runtimes accept pings every 5 seconds, see jina/serve/helper.py
+        keepaliveTime:    10 * time.Second,
+        keepaliveTimeout: 5 * time.Second,
+        startupTimeout:   30 * time.Second,
+        callAttempts:     3,
+        callBackoff:      100 * time.Millisecond,
+        breakerFailures:  5,
+        breakerCooldown:  5 * time.Second,
+        pollInterval:     time.Second,
+        pollMaxInterval:  10 * time.Second,
+        applyTimeout:     60 * time.Second,
+        snapshotTimeout:  500 * time.Second,
+        restoreTimeout:   500 * time.Second,
+    }
+}
+
+// maxReconnectBackoff caps the time between two attempts to reach the Executor, when the Node starts and when
+// gRPC reconnects, so that a restarted Executor is used again quickly.
+const maxReconnectBackoff = 2 * time.Second
+
+// executor manages the long-lived connection to the Executor, shared by every call. gRPC reconnects it when
+// the Executor restarts.
 type executor struct {
     target             string
     connection_options []grpc.DialOption
+    policy             executorPolicy
     Logger             hclog.Logger
+
+    mtx     sync.Mutex
+    conn    *grpc.ClientConn
+    breaker circuitBreaker
+}
+
+// newExecutor returns the connection to the Executor at target of the Node raftID, which labels its metrics.
+func newExecutor(target string, raftID string, policy executorPolicy, logger hclog.Logger, connection_options []grpc.DialOption) *executor {
+    return &executor{
+        target:             target,
+        connection_options: connection_options,
+        policy:             policy,
+        Logger:             logger,
+        breaker:            circuitBreaker{failures: policy.breakerFailures, cooldown: policy.breakerCooldown, open: executorCircuitOpen.WithLabelValues(raftID)},
+    }
 }
 
-func (executor *executor) newConnection() (*grpc.ClientConn, error) {
-    conn, err := grpc.Dial(executor.target, executor.connection_options...)
+// connection returns the connection to the Executor, dialing it on first use. It must not be closed by callers.
+// The calls that cannot be retried use it with grpc.WaitForReady(true), so that they wait for a restarting
+// Executor instead of failing.
+func (executor *executor) connection() (*grpc.ClientConn, error) {
+    executor.mtx.Lock()
+    defer executor.mtx.Unlock()
+    if executor.conn != nil {
+        return executor.conn, nil
+    }
+    options := append([]grpc.DialOption{
+        grpc.WithKeepaliveParams(keepalive.ClientParameters{
+            Time:                executor.policy.keepaliveTime,
+            Timeout:             executor.policy.keepaliveTimeout,
+            PermitWithoutStream: true,
+        }),
+        grpc.WithConnectParams(grpc.ConnectParams{
+            Backoff:           backoff.Config{BaseDelay: executor.policy.callBackoff, Multiplier: 1.6, Jitter: 0.2, MaxDelay: maxReconnectBackoff},
+            MinConnectTimeout: maxReconnectBackoff,
+        }),
+    }, executor.connection_options...)
+    conn, err := grpc.Dial(executor.target, options...)
     if err != nil {
         executor.Logger.Error("Dialing failed", "error", err)
         return nil, err
     }
-    return conn, err
+    executor.conn = conn
+    return conn, nil
+}
+
+// close closes the connection to the Executor, if it was dialed.
+func (executor *executor) close() error {
+    executor.mtx.Lock()
+    defer executor.mtx.Unlock()
+    if executor.conn == nil {
+        return nil
+    }
+    err := executor.conn.Close()
+    executor.conn = nil
+    return err
+}
+
+// invoke runs the idempotent call fn, retrying it while the Executor is unavailable. Calls fail fast while the
+// circuit breaker is open, so that an Executor that is down does not hold every caller until its deadline.
+func (executor *executor) invoke(ctx context.Context, method string, fn func(ctx context.Context, conn *grpc.ClientConn) error) error {
+    conn, err := executor.connection()
+    if err != nil {
+        return err
+    }
+    backoff := executor.policy.callBackoff
+    for attempt := 1; ; attempt++ {
+        if !executor.breaker.allow() {
+            return status.Errorf(codes.Unavailable, "circuit breaker open for the Executor at %s", executor.target)
+        }
+        err = fn(ctx, conn)
+        if executor.breaker.record(err) {
+            executor.Logger.Warn("Executor unavailable, opening the circuit breaker", "target", executor.target, "cooldown", executor.policy.breakerCooldown, "error", err)
+        }
+        if status.Code(err) != codes.Unavailable || attempt >= executor.policy.callAttempts {
+            return err
+        }
+        executor.Logger.Debug("Retrying a call to the Executor", "method", method, "attempt", attempt, "error", err)
+        select {
+        case <-time.After(backoff):
+            backoff *= 2
+        case <-ctx.Done():
+            return err
+        }
+    }
+}
+
+// waitReady waits until probe succeeds, backing off between the attempts, for at most the startup timeout.
+func (executor *executor) waitReady(probe func(ctx context.Context) error) error {
+    deadline := time.Now().Add(executor.policy.startupTimeout)
+    backoff := executor.policy.callBackoff
+    for {
+        ctx, cancel := context.WithTimeout(context.Background(), maxReconnectBackoff)
+        err := probe(ctx)
+        cancel()
+        if err == nil || time.Now().Add(backoff).After(deadline) {
+            return err
+        }
+        executor.Logger.Debug("Waiting for the Executor", "target", executor.target, "error", err)
+        time.Sleep(backoff)
+        if backoff *= 2; backoff > maxReconnectBackoff {
+            backoff = maxReconnectBackoff
+        }
+    }
+}
+
+// circuitBreaker opens after a number of consecutive calls failing because the Executor is unreachable, then
+// lets a single call through once the cooldown elapsed: the breaker closes if it succeeds, or opens again.
+type circuitBreaker struct {
+    failures int
+    cooldown time.Duration
+    // open is set to 1 while the breaker is open
+    open     prometheus.Gauge
+
+    mtx       sync.Mutex
+    failed    int
+    openUntil time.Time
+    probing   bool
+}
+
+func (b *circuitBreaker) allow() bool {
+    if b.failures <= 0 {
+        return true
+    }
+    b.mtx.Lock()
+    defer b.mtx.Unlock()
+    if b.failed < b.failures {
+        return true
+    }
+    if b.probing || time.Now().Before(b.openUntil) {
+        return false
+    }
+    b.probing = true
+    return true
+}
+
+// record accounts for the outcome of an allowed call, and reports whether it opened the breaker.
+func (b *circuitBreaker) record(err error) bool {
+    if b.failures <= 0 {
+        return false
+    }
+    b.mtx.Lock()
+    defer b.mtx.Unlock()
+    b.probing = false
+    switch status.Code(err) {
+    case codes.Unavailable:
+        // gRPC reports the connection failures as Unavailable
+    case codes.Canceled, codes.DeadlineExceeded:
+        // the caller gave up or ran out of time, e.g. on a slow call, this tells nothing about the reachability of
+        // the Executor
+        return false
+    default:
+        b.failed = 0
+        b.open.Set(0)
+        return false
+    }
+    b.failed++
+    if b.failed < b.failures {
+        return false
+    }
+    b.openUntil = time.Now().Add(b.cooldown)
+    b.open.Set(1)
+    return b.failed == b.failures
 }
@@ -0,0 +1,53 @@
+package server
+
+import (
+    "errors"
+    "testing"
+    "time"
+
+    "github.com/prometheus/client_golang/prometheus/testutil"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+    unavailable := status.Error(codes.Unavailable, "connection refused")
+    tests := []struct {
+        name   string
+        errs   []error
+        open   bool
+    }{
+        {name: "unavailable", errs: []error{unavailable, unavailable, unavailable}, open: true},
+        {name: "deadline", errs: []error{status.Error(codes.DeadlineExceeded, "deadline exceeded"), unavailable, unavailable}},
+        {name: "deadline between failures", errs: []error{unavailable, status.Error(codes.DeadlineExceeded, "deadline exceeded"), unavailable, unavailable}, open: true},
+        {name: "canceled between failures", errs: []error{unavailable, status.Error(codes.Canceled, "canceled"), unavailable, unavailable}, open: true},
+        {name: "executor error between failures", errs: []error{unavailable, unavailable, status.Error(codes.Internal, "executor failed"), unavailable}},
+        {name: "success between failures", errs: []error{unavailable, unavailable, nil, unavailable, unavailable}},
+        {name: "not a status", errs: []error{errors.New("boom"), errors.New("boom"), errors.New("boom")}},
+    }
+    for _, test := range tests {
+        gauge := executorCircuitOpen.WithLabelValues("test-" + test.name)
+        b := &circuitBreaker{failures: 3, cooldown: time.Hour, open: gauge}
+        for _, err := range test.errs {
+            if !b.allow() {
+                t.Fatalf("%s: allow() = false before the breaker opened", test.name)
+            }
+            b.record(err)
+        }
+        if open := !b.allow(); open != test.open {
+            t.Errorf("%s: open = %v, want %v", test.name, open, test.open)
+        }
+        if value := testutil.ToFloat64(gauge); (value == 1) != test.open {
+            t.Errorf("%s: executor_circuit_open = %v, want open %v", test.name, value, test.open)
+        }
+    }
+
+    // each Node reports its own breaker
+    first := &circuitBreaker{failures: 1, cooldown: time.Hour, open: executorCircuitOpen.WithLabelValues("test-first")}
+    second := &circuitBreaker{failures: 1, cooldown: time.Hour, open: executorCircuitOpen.WithLabelValues("test-second")}
+    second.record(nil)
+    first.record(unavailable)
+    if testutil.ToFloat64(executorCircuitOpen.WithLabelValues("test-first")) != 1 || testutil.ToFloat64(executorCircuitOpen.WithLabelValues("test-second")) != 0 {
+        t.Error("the breaker of a Node changed the executor_circuit_open of another one")
+    }
+}
@@ -2,22 +2,28 @@ package server
 
 import (
     "context"
+    "crypto/sha256"
     "fmt"
+    "hash/crc32"
     "os"
     "io"
     "io/ioutil"
-    "log"
     "sync"
+    "sync/atomic"
     "time"
-    "errors"
 
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
     "google.golang.org/protobuf/proto"
     "google.golang.org/protobuf/types/known/emptypb"
-    healthpb "google.golang.org/grpc/health/grpc_health_v1"
     empty "github.com/golang/protobuf/ptypes/empty"
 
     "github.com/hashicorp/raft"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
     pb "jraft/jina-go-proto"
+    jraftpb "jraft/jraft-go-proto"
     hclog "github.com/hashicorp/go-hclog"
 )
 
@@ -25,39 +31,74 @@ import (
 type executorFSM struct {
     executor *executor
     mtx      sync.RWMutex
-    snapshot *snapshot
+    // write_endpoints are discovered from the Executor at startup, and used until the leader commits a set
     write_endpoints []string
+    // replicatedEndpoints is the last set of write endpoints committed through the log
+    replicatedEndpoints atomic.Pointer[jraftpb.WriteEndpoints]
+    // endpoints are all the endpoints of the Executor, discovered at startup and by every EndpointDiscovery
+    endpoints atomic.Pointer[[]string]
     RaftID   string
     logger   hclog.Logger
+    // restoring is set while the Executor restores a snapshot, restores counts the restores started
+    restoring atomic.Bool
+    restores  atomic.Uint64
+    // appliedIndex is the index of the last entry applied to the Executor, at which its snapshots are fenced
+    appliedIndex atomic.Uint64
+    // snapshotProgress and restoreProgress report the last snapshot and restore of the Executor
+    snapshotProgress *operationProgress
+    restoreProgress  *operationProgress
+    // snapshotKeys encrypt the new snapshots and decrypt the encrypted ones, nil if the Node has no keys
+    snapshotKeys     SnapshotKeyProvider
+    // snapshotAllowPlaintext restores the unencrypted snapshots even though the Node has snapshotKeys
+    snapshotAllowPlaintext bool
+    // tracerProvider starts the spans of the Node, nil unless it exports traces
+    tracerProvider   trace.TracerProvider
 }
 
 
-func NewExecutorFSM(target string, LogLevel string, name string, raftID string) *executorFSM {
+// NewExecutorFSM creates the FSM applying the replicated requests to the Executor at target.
+// The Executor is dialed with connection_options, or insecurely when none are given.
+func NewExecutorFSM(target string, LogLevel string, name string, raftID string, connection_options ...grpc.DialOption) *executorFSM {
+    return newExecutorFSM(target, defaultExecutorPolicy(), LogLevel, name, raftID, connection_options...)
+}
+
+// newExecutorFSM creates the FSM connected to the Executor following policy. It waits for the Executor to
+// answer, for at most the startup timeout of policy, to discover its write endpoints.
+func newExecutorFSM(target string, policy executorPolicy, LogLevel string, name string, raftID string, connection_options ...grpc.DialOption) *executorFSM {
     fsm_logger := hclog.New(&hclog.LoggerOptions{
                     Name:   "FSM-" + name,
                     Level:  hclog.LevelFromString(LogLevel),
                 })
-    executor := &executor{
-                target:             target,
-                connection_options: defaultExecutorDialOptions(),
-                Logger: fsm_logger,
-                }
-
-    conn, _ := executor.newConnection()
-    defer conn.Close()
-    client := pb.NewJinaDiscoverEndpointsRPCClient(conn)
-    response, err := client.EndpointDiscovery(context.Background(), &emptypb.Empty{})
-    if err != nil {
-        fsm_logger.Error("Error getting endpoints discovery", "error", err)
+    if len(connection_options) == 0 {
+        connection_options = defaultExecutorDialOptions()
     }
-    write_endpoints := response.WriteEndpoints
-    fsm_logger.Debug("List of endpoints that should trigger Raft Apply:", "endpoints", write_endpoints)
-    return &executorFSM{
-        executor: executor,
-        write_endpoints: write_endpoints,
+    fsm := &executorFSM{
+        executor: newExecutor(target, raftID, policy, fsm_logger, connection_options),
         logger: fsm_logger,
         RaftID: raftID,
+        snapshotProgress: newOperationProgress(operationSnapshot),
+        restoreProgress: newOperationProgress(operationRestore),
     }
+    var write_endpoints, endpoints []string
+    err := fsm.executor.waitReady(func(ctx context.Context) error {
+        conn, err := fsm.executor.connection()
+        if err != nil {
+            return err
+        }
+        response, err := pb.NewJinaDiscoverEndpointsRPCClient(conn).EndpointDiscovery(ctx, &emptypb.Empty{}, grpc.WaitForReady(true))
+        if err != nil {
+            return err
+        }
+        write_endpoints, endpoints = response.WriteEndpoints, response.Endpoints
+        return nil
+    })
+    if err != nil {
+        fsm_logger.Error("Error getting endpoints discovery", "error", err)
+    }
+    fsm.write_endpoints = write_endpoints
+    fsm.endpoints.Store(&endpoints)
+    fsm_logger.Debug("List of endpoints that should trigger Raft Apply:", "endpoints", write_endpoints)
+    return fsm
 }
 
 
@@ -66,199 +107,355 @@ func DummyExecutorFSM() *executorFSM {
                     Name:   "executorFSM-dummy",
                     Level:  hclog.LevelFromString("INFO"),
                 })
-    executor := &executor{
-                target:             "0.0.0.0:54321", //TODO: randomize
-                connection_options: defaultExecutorDialOptions(),
-                Logger: fsm_logger,
-            }
+    //TODO: randomize the target
+    executor := newExecutor("0.0.0.0:54321", "dummy", defaultExecutorPolicy(), fsm_logger, defaultExecutorDialOptions())
 
     return &executorFSM{
         executor: executor,
         write_endpoints: []string{},
         logger: fsm_logger,
         RaftID: "dummy",
+        snapshotProgress: newOperationProgress(operationSnapshot),
+        restoreProgress: newOperationProgress(operationRestore),
 
     }
 }
 
-func (executor *executorFSM) isSnapshotInProgress() bool {
-    if executor.snapshot != nil &&
-        *executor.snapshot.status == pb.SnapshotStatusProto_RUNNING {
-        return true
+// applyResult is the result of Apply, returned by the ApplyFuture.Response of the write.
+type applyResult struct {
+    // response is the DataRequestProto returned by the Executor. When the Executor fails, it is the request with
+    // the error in its header status, the same as a plain Executor would return.
+    response  *pb.DataRequestProto
+    // err is a failure of the consensus layer: the entry could not be applied at all
+    err       error
+    // unapplied is set when the Executor could not be called, response then holds the error
+    unapplied bool
+}
+
+// executorError sets err in the header status of dataRequestProto, the way the Executor runtime reports the
+// exceptions raised while handling a request.
+func executorError(dataRequestProto *pb.DataRequestProto, err error) *pb.DataRequestProto {
+    if dataRequestProto.Header == nil {
+        dataRequestProto.Header = &pb.HeaderProto{}
+    }
+    dataRequestProto.Header.Status = &pb.StatusProto{
+        Code:        pb.StatusProto_ERROR,
+        Description: err.Error(),
+        Exception:   &pb.StatusProto_ExceptionProto{
+            Name:     "InternalNetworkError",
+            Args:     []string{err.Error()},
+            Executor: dataRequestProto.Header.GetTargetExecutor(),
+        },
     }
-    return false
+    return dataRequestProto
 }
 
 // triggered once the followers have committed the log
 func (fsm *executorFSM) Apply(l *raft.Log) interface{} {
+    if l.Index <= fsm.appliedIndex.Load() {
+        // the restored snapshot of the Executor already holds the entry
+        fsm.logger.Warn("Skipping a log entry applied before the snapshot", "index", l.Index, "applied index", fsm.appliedIndex.Load())
+        return &applyResult{}
+    }
+    entry, err := decodeEntry(l.Data)
+    if err != nil {
+        fsm.logger.Error("Error while decoding log entry", "error", err)
+        return &applyResult{err: err}
+    }
+    ctx, span := fsm.tracer().Start(contextFromEntry(entry.TraceContext), "executorFSM.Apply", trace.WithAttributes(
+        attribute.String("raft.id", fsm.RaftID),
+        attribute.Int64("raft.index", int64(l.Index)),
+        attribute.Int64("raft.term", int64(l.Term)),
+        attribute.String("raft.command", entry.Type.String()),
+        attribute.String("jina.request_id", entry.RequestId),
+    ))
+    defer span.End()
     fsm.mtx.Lock()
     defer fsm.mtx.Unlock()
-    fsm.logger.Debug("Apply new log entry")
-    for {
-        if !fsm.isSnapshotInProgress() {
-            // we need not to return error but make it slow, wait until not anymore in progress
-            break
-        }
-        fsm.logger.Error("cannot execute Apply because a snapshot is in progress")
-        time.Sleep(1 * time.Second)
-    }
-    if fsm.isSnapshotInProgress() {
-        // we need not to return error but make it slow, wait until not anymore in progress
-        fsm.logger.Error("Cannot accept new requests when snap shotting is in progress.")
-        return fmt.Errorf("Cannot accept new requests when snap shotting is in progress.")
+    fsm.logger.Debug("Apply new log entry", "index", l.Index, "version", entry.Version, "command", entry.Type, "request id", entry.RequestId)
+    result := fsm.applyEntry(ctx, span, l, entry)
+    if result.err == nil && !result.unapplied {
+        // the fence only moves past the entries the Executor applied, so that a restore applies the others again
+        fsm.appliedIndex.Store(l.Index)
     }
-    conn, err := fsm.executor.newConnection()
-    if err != nil {
-        return err
+    return result
+}
+
+func (fsm *executorFSM) applyEntry(ctx context.Context, span trace.Span, l *raft.Log, entry *jraftpb.LogEntry) *applyResult {
+    switch entry.Type {
+    case jraftpb.CommandType_COMMAND_DATA_REQUEST:
+        return fsm.applyDataRequest(ctx, span, entry)
+    case jraftpb.CommandType_COMMAND_CONFIGURATION:
+        return fsm.applyConfiguration(entry)
+    case jraftpb.CommandType_COMMAND_NOOP:
+        return &applyResult{}
     }
-    defer conn.Close()
-    client := pb.NewJinaSingleDataRequestRPCClient(conn)
+    // commands added by a later version are skipped, the same as by the nodes that do not know them yet
+    fsm.logger.Warn("Skipping a log entry of an unsupported command", "index", l.Index, "command", entry.Type)
+    return &applyResult{err: spanError(span, fmt.Errorf("unsupported command %s", entry.Type))}
+}
+
+// applyDataRequest sends the write request of entry to the Executor.
+func (fsm *executorFSM) applyDataRequest(ctx context.Context, span trace.Span, entry *jraftpb.LogEntry) *applyResult {
     dataRequestProto := &pb.DataRequestProto{}
-    err = proto.Unmarshal(l.Data, dataRequestProto)
+    err := proto.Unmarshal(entry.Payload, dataRequestProto)
     if err != nil {
         fsm.logger.Error("Error while unmarshalling log into DataRequestProto", "error", err)
-        return err
+        return &applyResult{err: spanError(span, err)}
+    }
+    conn, err := fsm.executor.connection()
+    if err != nil {
+        return &applyResult{response: executorError(dataRequestProto, spanError(span, err)), unapplied: true}
     }
+    client := pb.NewJinaSingleDataRequestRPCClient(conn)
 
-    response, err := client.ProcessSingleData(context.Background(), dataRequestProto)
+    start := time.Now()
+    // a write cannot be retried once sent, it waits for a restarting Executor instead, for at most applyTimeout so
+    // that the next entries are not held behind it
+    ctx, cancel := context.WithTimeout(ctx, fsm.executor.policy.applyTimeout)
+    defer cancel()
+    response, err := fsm.callExecutor(ctx, client, dataRequestProto, grpc.WaitForReady(true))
+    observeSince(executorApplySeconds, start, err)
     if err != nil {
         fsm.logger.Error("Error when calling Executor", "error", err)
-        return err
+        return &applyResult{response: executorError(dataRequestProto, spanError(span, err)), unapplied: true}
     }
     fsm.logger.Debug("Return Apply Log Response")
-    return response
+    return &applyResult{response: response}
 }
 
+// callExecutor sends dataRequestProto to the Executor in a client span, propagating the trace context.
+func (fsm *executorFSM) callExecutor(ctx context.Context, client pb.JinaSingleDataRequestRPCClient, dataRequestProto *pb.DataRequestProto, opts ...grpc.CallOption) (*pb.DataRequestProto, error) {
+    ctx, span := fsm.tracer().Start(ctx, "Executor/ProcessSingleData", trace.WithSpanKind(trace.SpanKindClient), trace.WithAttributes(
+        attribute.String("jina.endpoint", dataRequestProto.GetHeader().GetExecEndpoint()),
+        attribute.String("net.peer.name", fsm.executor.target),
+    ))
+    response, err := client.ProcessSingleData(injectOutgoing(ctx), dataRequestProto, opts...)
+    endSpan(span, err)
+    return response, err
+}
+
+// Snapshot starts a snapshot of the Executor fenced at the last applied entry. RAFT calls it between two calls to
+// Apply, and the Executor answers once no later write can be part of its snapshot, so that the next entries are
+// applied while the snapshot is taken and persisted. They are applied again after a restore, from the log kept
+// after the snapshot.
 func (fsm *executorFSM) Snapshot() (raft.FSMSnapshot, error) {
-    // Make sure that any future calls to f.Apply() don't change the snapshot.
     fsm.mtx.Lock()
     defer fsm.mtx.Unlock()
-    fsm.logger.Debug("Snapshot FSM state")
-    conn, err := fsm.executor.newConnection()
+    appliedIndex := fsm.appliedIndex.Load()
+    fsm.logger.Debug("Snapshot FSM state", "applied index", appliedIndex)
+    fsm.snapshotProgress.begin()
+    conn, err := fsm.executor.connection()
     if err != nil {
         fsm.logger.Error("Error setting a new connection with Executor", "error", err)
+        fsm.snapshotProgress.finish(err)
         return nil, err
     }
-    defer conn.Close()
     client := pb.NewJinaExecutorSnapshotClient(conn)
-    response, err := client.Snapshot(context.Background(), &emptypb.Empty{})
+    // Raft applies no entry until the snapshot is started, a down Executor fails it after snapshotTimeout
+    ctx, cancel := context.WithTimeout(context.Background(), fsm.executor.policy.snapshotTimeout)
+    defer cancel()
+    response, err := client.Snapshot(ctx, &emptypb.Empty{}, grpc.WaitForReady(true))
     if err != nil {
         fsm.logger.Error("Error triggering a snapshot", "error", err)
+        fsm.snapshotProgress.finish(err)
         return nil, err
     }
+    fsm.snapshotProgress.update(response.Id.GetValue(), response.Status.String())
     snapshot := &snapshot{
         executor:          fsm.executor,
         id:                response.Id,
         status:            &response.Status,
         snapshotFile:      response.SnapshotFile,
+        header:            &jraftpb.SnapshotHeader{
+            Version:            snapshotManifestVersion,
+            WriteEndpoints:     fsm.replicatedEndpoints.Load(),
+            AppliedIndex:       appliedIndex,
+            ExecutorSnapshotId: response.Id.GetValue(),
+            Codec:              fsm.executor.policy.snapshotCodec,
+        },
+        progress:          fsm.snapshotProgress,
+        keys:              fsm.snapshotKeys,
         Logger:            fsm.logger,
     }
-    fsm.snapshot = snapshot
-    fsm.logger.Debug("Snapshot successfully attached to FSM")
+    fsm.logger.Debug("Snapshot of the Executor fenced", "ID", response.Id.GetValue(), "applied index", appliedIndex)
     return snapshot, nil
 }
 
-func (fsm *executorFSM) Restore(r io.ReadCloser) error {
+func (fsm *executorFSM) Restore(r io.ReadCloser) (err error) {
     // I think restore here is not well set
     fsm.logger.Debug("Restore FSM state")
-    bytes, err := io.ReadAll(r)
-    // write bytes to temporary file, and pass it in the request
+    fsm.restores.Add(1)
+    fsm.restoring.Store(true)
+    defer fsm.restoring.Store(false)
+    fsm.restoreProgress.begin()
+    start := time.Now()
+    defer func() {
+        fsm.restoreProgress.finish(err)
+        observeSince(restoreSeconds, start, err)
+    }()
+    header, encodedHeader, body, err := readSnapshotHeader(r)
     if err != nil {
-        fsm.logger.Error("Error reading bytes from the snapshot file", "error", err)
+        fsm.logger.Error("Error decoding the snapshot header", "error", err)
         return err
     }
-    tempDir := os.TempDir()
-    file, err := ioutil.TempFile(tempDir, "temp")
+    dataKey, err := fsm.dataKey(header)
     if err != nil {
-        log.Print(err)
-    }
-    defer os.Remove(file.Name()) // remove the file when done
-
-     fsm.logger.Debug("Temporary file name where to write state", "filename", file.Name())
-
-    // Write some data to the file
-    if _, err := file.Write(bytes); err != nil {
-        fsm.logger.Error("Error writing snapshot bytes to temporary file", "error", err)
+        fsm.logger.Error("Error decrypting the key of the snapshot", "key", header.GetEncryption().GetKeyId(), "error", err)
         return err
     }
-
-    // Close the file
-    if err := file.Close(); err != nil {
-        fsm.logger.Error("Error closing file", "error", err)
-        return err
+    if header.GetVersion() >= snapshotManifestVersion {
+        decoder, err := newSnapshotDecoder(body, header.GetCodec(), dataKey, encodedHeader)
+        if err != nil {
+            fsm.logger.Error("Error decompressing the snapshot", "codec", header.GetCodec(), "error", err)
+            return err
+        }
+        defer decoder.Close()
+        body = decoder
     }
+    // snapshots taken before the write endpoints were replicated fall back to the endpoints of the local Executor
+    fsm.replicatedEndpoints.Store(header.GetWriteEndpoints())
+    fsm.logger.Debug("Restored the replicated write endpoints", "endpoints", header.GetWriteEndpoints().GetEndpoints())
     fsm.logger.Debug("Calling Executor to request restore")
-    conn, err := fsm.executor.newConnection()
-    if err != nil {
-        fsm.logger.Error("Error setting a new connection with Executor", "error", err)
-        return err
+    restoreResponse, snapshotFile, err := fsm.restoreExecutor(fsm.restoreProgress.reader(body))
+    if snapshotFile != "" {
+        defer os.Remove(snapshotFile) // remove the file when done
     }
-    defer conn.Close()
-    client := pb.NewJinaExecutorRestoreClient(conn)
-    restoreCommandProto := &pb.RestoreSnapshotCommand{}
-    restoreCommandProto.SnapshotFile = file.Name()
-    restoreResponse, err := client.Restore(context.Background(), restoreCommandProto)
     if err != nil {
         fsm.logger.Error("Restore command to Executor failed", "error", err)
         return err
     }
+    fsm.restoreProgress.update(restoreResponse.Id.GetValue(), restoreResponse.Status.String())
     fsm.logger.Debug("Start Checking status of Restore")
-    ticker := time.NewTicker(1 * time.Second)
-    done := make(chan bool)
-    defer close(done)
-    timeout := time.NewTimer(500 * time.Second)
+    err = fsm.executor.poll(operationRestore, fsm.executor.policy.restoreTimeout, func(ctx context.Context) (bool, error) {
+        var response *pb.RestoreSnapshotStatusProto
+        err := fsm.executor.invoke(ctx, "RestoreStatus", func(ctx context.Context, conn *grpc.ClientConn) (err error) {
+            response, err = pb.NewJinaExecutorRestoreProgressClient(conn).RestoreStatus(ctx, restoreResponse.Id)
+            return err
+        })
+        if err != nil {
+            return false, err
+        }
+        fsm.restoreProgress.update(restoreResponse.Id.GetValue(), response.Status.String())
+        fsm.logger.Debug("Restore", "ID", restoreResponse.Id.GetValue(), "status", response.Status)
+        switch response.Status {
+        case pb.RestoreSnapshotStatusProto_SUCCEEDED:
+            return true, nil
+        case pb.RestoreSnapshotStatusProto_FAILED, pb.RestoreSnapshotStatusProto_NOT_FOUND:
+            return true, fmt.Errorf("restore %s of the Executor failed with status %s", restoreResponse.Id.GetValue(), response.Status)
+        }
+        return false, nil
+    })
+    if err != nil {
+        return err
+    }
+    // the entries after the fence of the snapshot are applied again from the log
+    fsm.appliedIndex.Store(header.GetAppliedIndex())
+    return nil
+}
+
+// restoreExecutor starts the restore of the Executor from the snapshot read from r. The snapshot is streamed in
+// chunks, so that it is never held in memory nor shared through the file system. Executors that cannot restore
+// from a stream are sent the path of a temporary copy of the snapshot, returned so that it is removed once restored.
+func (fsm *executorFSM) restoreExecutor(r io.Reader) (*pb.RestoreSnapshotStatusProto, string, error) {
+    conn, err := fsm.executor.connection()
+    if err != nil {
+        fsm.logger.Error("Error setting a new connection with Executor", "error", err)
+        return nil, "", err
+    }
+    ctx, cancel := context.WithCancel(context.Background())
+    defer cancel()
+    stream, err := pb.NewJinaExecutorRestoreStreamClient(conn).RestoreStream(ctx, grpc.WaitForReady(true))
+    if err != nil {
+        return nil, "", err
+    }
+    // the Executor sends the headers once it accepts the stream, older Executors answer Unimplemented instead:
+    // nothing has been read from r yet, so it can still be copied to a file
+    headers, err := stream.Header()
+    if err == nil && headers == nil {
+        // the Executor ended the stream without headers, its status is returned by CloseAndRecv
+        _, err = stream.CloseAndRecv()
+    }
+    if err != nil {
+        if status.Code(err) != codes.Unimplemented {
+            return nil, "", err
+        }
+        fsm.logger.Debug("The Executor cannot restore from a stream, sending it a snapshot file")
+        return fsm.restoreFromFile(conn, r)
+    }
+    response, err := sendSnapshot(stream, r)
+    return response, "", err
+}
+
+// snapshotChunkSize is the size of the chunks in which a snapshot is streamed to the Executor.
+const snapshotChunkSize = 1 << 20
 
-    go func(funcTicker *time.Ticker) {
-        for {
-            select {
-            case t := <-funcTicker.C:
-                fsm.logger.Debug("Checking restore status at", "time", t)
-                conn, err = fsm.executor.newConnection()
-                if err == nil {
-                    defer conn.Close()
-                    client := pb.NewJinaExecutorRestoreProgressClient(conn)
-                    response, err := client.RestoreStatus(context.Background(), restoreResponse.Id)
-                    if err != nil {
-                        fsm.logger.Error("Error fetching restore status for", "ID", restoreResponse.Id, "error", err)
-                    } else {
-                        fsm.logger.Debug("Restore", "status", response.Status, "at time", t)
-                        if response.Status == pb.RestoreSnapshotStatusProto_FAILED ||
-                            response.Status == pb.RestoreSnapshotStatusProto_SUCCEEDED {
-                            if response.Status == pb.RestoreSnapshotStatusProto_FAILED {
-                                 err = errors.New("Restoring Executor failed")
-                            }
-                            timeout.Stop()
-                            done <- true
-                            return
-                        }
-                    }
+// sendSnapshot streams the snapshot read from r in chunks carrying their CRC-32, followed by a chunk holding the
+// SHA-256 digest of the whole snapshot. Send blocks while the flow control window of the stream is full, so that
+// r is read as fast as the Executor writes the snapshot.
+func sendSnapshot(stream pb.JinaExecutorRestoreStream_RestoreStreamClient, r io.Reader) (*pb.RestoreSnapshotStatusProto, error) {
+    digest := sha256.New()
+    var offset uint64
+    for {
+        data := make([]byte, snapshotChunkSize)
+        n, err := io.ReadFull(r, data)
+        if n > 0 {
+            data = data[:n]
+            if err := stream.Send(&pb.SnapshotChunkProto{Data: data, Offset: offset, Crc32: crc32.ChecksumIEEE(data)}); err != nil {
+                if err == io.EOF {
+                    // the Executor ended the stream, its status is returned by CloseAndRecv
+                    return stream.CloseAndRecv()
                 }
-            case <-timeout.C:
-                fsm.logger.Error("Timed out waiting for restore status.")
-                timeout.Stop()
-                done <- true
-                return
+                return nil, err
             }
+            digest.Write(data)
+            offset += uint64(n)
+        }
+        if err == io.EOF || err == io.ErrUnexpectedEOF {
+            break
+        }
+        if err != nil {
+            return nil, err
         }
-    }(ticker)
-    <-done
-    ticker.Stop()
-    return err
+    }
+    if err := stream.Send(&pb.SnapshotChunkProto{Offset: offset, Sha256: digest.Sum(nil)}); err != nil && err != io.EOF {
+        return nil, err
+    }
+    return stream.CloseAndRecv()
 }
 
-func (fsm *executorFSM) Read(ctx context.Context, dataRequestProto *pb.DataRequestProto) (*pb.DataRequestProto, error) {
-    fsm.logger.Debug("Call Read Endpoint")
-    conn, err := fsm.executor.newConnection()
+// restoreFromFile copies the snapshot read from r to a temporary file, and asks the Executor to restore from it.
+func (fsm *executorFSM) restoreFromFile(conn *grpc.ClientConn, r io.Reader) (*pb.RestoreSnapshotStatusProto, string, error) {
+    file, err := ioutil.TempFile(os.TempDir(), "temp")
     if err != nil {
-        fsm.logger.Error("Error setting a new connection with Executor", "error", err)
-        return nil, err
+        fsm.logger.Error("Error creating a temporary file for the snapshot", "error", err)
+        return nil, "", err
     }
-    defer conn.Close()
-    client := pb.NewJinaSingleDataRequestRPCClient(conn)
-    response, err := client.ProcessSingleData(ctx, dataRequestProto)
+    fsm.logger.Debug("Temporary file name where to write state", "filename", file.Name())
+    if _, err := io.Copy(file, r); err != nil {
+        file.Close()
+        fsm.logger.Error("Error writing snapshot bytes to temporary file", "error", err)
+        return nil, file.Name(), err
+    }
+    if err := file.Close(); err != nil {
+        fsm.logger.Error("Error closing file", "error", err)
+        return nil, file.Name(), err
+    }
+    restoreCommandProto := &pb.RestoreSnapshotCommand{SnapshotFile: file.Name()}
+    response, err := pb.NewJinaExecutorRestoreClient(conn).Restore(context.Background(), restoreCommandProto, grpc.WaitForReady(true))
+    return response, file.Name(), err
+}
+
+func (fsm *executorFSM) Read(ctx context.Context, dataRequestProto *pb.DataRequestProto) (*pb.DataRequestProto, error) {
+    fsm.logger.Debug("Call Read Endpoint")
+    var response *pb.DataRequestProto
+    err := fsm.executor.invoke(ctx, "ProcessSingleData", func(ctx context.Context, conn *grpc.ClientConn) (err error) {
+        response, err = fsm.callExecutor(ctx, pb.NewJinaSingleDataRequestRPCClient(conn), dataRequestProto)
+        return err
+    })
     if err != nil {
         fsm.logger.Error("Error calling Read endpoint", "error", err)
-        return nil, err
+        return executorError(dataRequestProto, err), nil
     }
     fsm.logger.Debug("Return Read Endpoint Response")
     return response, err
@@ -266,18 +463,16 @@ func (fsm *executorFSM) Read(ctx context.Context, dataRequestProto *pb.DataReque
 
 func (fsm *executorFSM) EndpointDiscovery(ctx context.Context, empty *empty.Empty) (*pb.EndpointsProto, error) {
     fsm.logger.Debug("Call EndpointDiscovery")
-    conn, err := fsm.executor.newConnection()
-    if err != nil {
-        fsm.logger.Error("Error setting a new connection with Executor", "error", err)
-        return nil, err
-    }
-    defer conn.Close()
-    client := pb.NewJinaDiscoverEndpointsRPCClient(conn)
-    response, err := client.EndpointDiscovery(ctx, empty)
+    var response *pb.EndpointsProto
+    err := fsm.executor.invoke(ctx, "EndpointDiscovery", func(ctx context.Context, conn *grpc.ClientConn) (err error) {
+        response, err = pb.NewJinaDiscoverEndpointsRPCClient(conn).EndpointDiscovery(ctx, empty)
+        return err
+    })
     if err != nil {
         fsm.logger.Error("Error calling EndpointDiscovery endpoint", "error", err)
         return nil, err
     }
+    fsm.endpoints.Store(&response.Endpoints)
     fsm.logger.Debug("Return EndpointDiscovery Response")
     return response, err
 }
@@ -285,14 +480,11 @@ func (fsm *executorFSM) EndpointDiscovery(ctx context.Context, empty *empty.Empt
 
 func (fsm *executorFSM) XStatus(ctx context.Context, empty *empty.Empty) (*pb.JinaInfoProto, error) {
     fsm.logger.Debug("Call XStatus")
-    conn, err := fsm.executor.newConnection()
-    if err != nil {
-        fsm.logger.Error("Error setting a new connection with Executor", "error", err)
-        return nil, err
-    }
-    defer conn.Close()
-    client := pb.NewJinaInfoRPCClient(conn)
-    response, err := client.XStatus(ctx, empty)
+    var response *pb.JinaInfoProto
+    err := fsm.executor.invoke(ctx, "XStatus", func(ctx context.Context, conn *grpc.ClientConn) (err error) {
+        response, err = pb.NewJinaInfoRPCClient(conn).XStatus(ctx, empty)
+        return err
+    })
     if err != nil {
         fsm.logger.Error("Error calling Status endpoint", "error", err)
         return nil, err
@@ -302,20 +494,3 @@ func (fsm *executorFSM) XStatus(ctx context.Context, empty *empty.Empty) (*pb.Ji
 }
 
 
-func (fsm *executorFSM) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
-    fsm.logger.Debug("Call Check")
-    conn, err := fsm.executor.newConnection()
-    if err != nil {
-        fsm.logger.Error("Error setting a new connection with Executor", "error", err)
-        return nil, err
-    }
-    defer conn.Close()
-    resp, err := healthpb.NewHealthClient(conn).Check(ctx, req)
-    if err != nil {
-        fsm.logger.Error("Error calling Check endpoint", "error", err)
-        return nil, err
-    }
-    fsm.logger.Debug("Return Check Response")
-    return resp, err
-}
-
@@ -0,0 +1,197 @@
+package server
+
+import (
+    "context"
+    "sync"
+    "time"
+
+    "github.com/hashicorp/raft"
+    jraftpb "jraft/jraft-go-proto"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/health"
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+)
+
+// Services reported by the health service of a Node, on top of the overall status of the empty service name,
+// which is SERVING when the Node is ready and its Executor serving.
+const (
+    // HealthRaftLeader is SERVING on the Raft leader only, like HealthQuisRaftLeader
+    HealthRaftLeader     = "raft-leader"
+    // HealthQuisRaftLeader is the service name reported by leaderhealth, and expected by raft-grpc-leader-rpc clients
+    HealthQuisRaftLeader = "quis.RaftLeader"
+    // HealthRaftReady is SERVING once the Node applied every entry the leader had committed when the Node
+    // started or restored a snapshot, and while a leader is known
+    HealthRaftReady      = "raft-ready"
+    // HealthRaftFollower is SERVING on the followers that are ready, where clients can spread their reads
+    HealthRaftFollower   = "raft-follower"
+    // HealthExecutor is SERVING while the health check of the Executor succeeds
+    HealthExecutor       = "executor"
+)
+
+const (
+    // healthCheckInterval is the time between two evaluations of the Raft state, leadership changes are reported immediately
+    healthCheckInterval     = 200 * time.Millisecond
+    // executorHealthInterval is the time between two health checks of the Executor
+    executorHealthInterval  = time.Second
+)
+
+// healthMonitor keeps the statuses of the health service of a Node up to date.
+type healthMonitor struct {
+    node   *Node
+    server *health.Server
+
+    // restores is the number of Executor restores when the catch up started
+    restores     uint64
+    // catchUpIndex is the commit index of the leader the Node has to apply before being ready
+    catchUpIndex uint64
+    catchUpKnown bool
+    caughtUp     bool
+
+    mtx      sync.Mutex
+    statuses map[string]bool
+}
+
+func newHealthMonitor(node *Node) *healthMonitor {
+    m := &healthMonitor{
+        node:     node,
+        server:   health.NewServer(),
+        statuses: map[string]bool{},
+    }
+    for _, service := range []string{"", HealthRaftReady, HealthRaftFollower, HealthExecutor} {
+        m.server.SetServingStatus(service, healthpb.HealthCheckResponse_NOT_SERVING)
+        m.statuses[service] = false
+    }
+    return m
+}
+
+// run updates the statuses until stopCh is closed.
+func (m *healthMonitor) run(stopCh <-chan struct{}) {
+    observations := make(chan raft.Observation, 1)
+    observer := raft.NewObserver(observations, false, func(o *raft.Observation) bool {
+        switch o.Data.(type) {
+        case raft.LeaderObservation, raft.RaftState:
+            return true
+        }
+        return false
+    })
+    m.node.raft.RegisterObserver(observer)
+    defer m.node.raft.DeregisterObserver(observer)
+    go m.watchExecutor(stopCh)
+
+    ticker := time.NewTicker(healthCheckInterval)
+    defer ticker.Stop()
+    for {
+        m.evaluate()
+        select {
+        case <-ticker.C:
+        case <-observations:
+        case <-stopCh:
+            return
+        }
+    }
+}
+
+func (m *healthMonitor) watchExecutor(stopCh <-chan struct{}) {
+    ticker := time.NewTicker(executorHealthInterval)
+    defer ticker.Stop()
+    for {
+        ctx, cancel := context.WithTimeout(context.Background(), executorHealthInterval)
+        status := m.node.fsm.executorStatus(ctx)
+        cancel()
+        m.set(HealthExecutor, status.ServingStatus == healthpb.HealthCheckResponse_SERVING.String())
+        select {
+        case <-ticker.C:
+        case <-stopCh:
+            return
+        }
+    }
+}
+
+// evaluate updates the Raft statuses from the state of the Node. The leader services are reported by leaderhealth.
+func (m *healthMonitor) evaluate() {
+    state := m.node.raft.State()
+    ready := m.ready(state)
+    m.set(HealthRaftReady, ready)
+    m.set(HealthRaftFollower, ready && state == raft.Follower)
+}
+
+// ready reports whether the Node caught up with the leader. Once caught up, the Node stays ready while a leader
+// is known, until the Executor restores a snapshot and the Node has to catch up again.
+func (m *healthMonitor) ready(state raft.RaftState) bool {
+    fsm := m.node.fsm
+    if restores := fsm.restores.Load(); restores != m.restores {
+        m.restores = restores
+        m.catchUpKnown = false
+        m.caughtUp = false
+    }
+    if fsm.restoring.Load() {
+        return false
+    }
+    leaderAddress, _ := m.node.raft.LeaderWithID()
+    if leaderAddress == "" || (state != raft.Leader && state != raft.Follower) {
+        return false
+    }
+    if m.caughtUp {
+        return true
+    }
+    stats := m.node.raft.Stats()
+    if !m.catchUpKnown {
+        index, ok := m.leaderCommitIndex(state, leaderAddress, stats)
+        if !ok {
+            return false
+        }
+        m.catchUpIndex = index
+        m.catchUpKnown = true
+        m.node.logger.Debug("Catching up with the leader", "commit index", index)
+    }
+    m.caughtUp = statUint(stats, "applied_index") >= m.catchUpIndex
+    if m.caughtUp {
+        m.node.logger.Info("Caught up with the leader", "applied index", statUint(stats, "applied_index"))
+    }
+    return m.caughtUp
+}
+
+// leaderCommitIndex returns the commit index of the leader. If the leader cannot be asked for its status, the
+// commit index of a follower is used once the leader contacted it, since it follows the one of the leader.
+func (m *healthMonitor) leaderCommitIndex(state raft.RaftState, leaderAddress raft.ServerAddress, stats map[string]string) (uint64, bool) {
+    if state == raft.Leader {
+        return statUint(stats, "commit_index"), true
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), statusTimeout)
+    defer cancel()
+    conn, err := grpc.DialContext(ctx, string(leaderAddress), m.node.adminDialOptions...)
+    if err == nil {
+        defer conn.Close()
+        var status *jraftpb.StatusResponse
+        status, err = jraftpb.NewJinaRaftStatusClient(conn).GetStatus(ctx, &jraftpb.StatusRequest{Local: true})
+        if err == nil {
+            return status.CommitIndex, true
+        }
+    }
+    m.node.logger.Debug("Could not get the commit index of the leader", "leader", leaderAddress, "error", err)
+    if _, contacted := statLastContact(stats); !contacted {
+        return 0, false
+    }
+    return statUint(stats, "commit_index"), true
+}
+
+// set updates the status of service, and the overall status which depends on it.
+func (m *healthMonitor) set(service string, serving bool) {
+    m.mtx.Lock()
+    defer m.mtx.Unlock()
+    m.setLocked(service, serving)
+    m.setLocked("", m.statuses[HealthRaftReady] && m.statuses[HealthExecutor])
+}
+
+func (m *healthMonitor) setLocked(service string, serving bool) {
+    if m.statuses[service] == serving {
+        return
+    }
+    m.statuses[service] = serving
+    status := healthpb.HealthCheckResponse_NOT_SERVING
+    if serving {
+        status = healthpb.HealthCheckResponse_SERVING
+    }
+    m.node.logger.Debug("Health status changed", "service", service, "status", status)
+    m.server.SetServingStatus(service, status)
+}
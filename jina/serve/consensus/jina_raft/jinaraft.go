@@ -58,8 +58,8 @@ func DummyNewRaft(conf *raft.Config, fsm raft.FSM, logs raft.LogStore, stable ra
     // Read the index of the last log entry.
     lastIndex, err := logs.LastIndex()
     if err != nil {
-        logger.Error("Error getting the last index: %v", err)
-        return nil, fmt.Errorf("Error getting the last index", "error", err)
+        logger.Error("Error getting the last index", "error", err)
+        return nil, fmt.Errorf("Error getting the last index: %v", err)
     }
 
     // Get the last log entry.
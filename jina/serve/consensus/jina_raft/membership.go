@@ -0,0 +1,145 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "net"
+    "time"
+
+    adminpb "github.com/Jille/raftadmin/proto"
+    "github.com/hashicorp/raft"
+    "google.golang.org/grpc"
+)
+
+// addressUpdateInterval is the time between two attempts to update the address of the Node in the Raft configuration.
+const addressUpdateInterval = time.Second
+
+func isUnspecifiedHost(host string) bool {
+    ip := net.ParseIP(host)
+    return host == "" || (ip != nil && ip.IsUnspecified())
+}
+
+// updateAdvertisedAddress makes sure the Raft configuration holds the advertised Raft address of the Node.
+// A Node restarted on another host or port is still known by the cluster under its previous address, so
+// the change is committed as a configuration change, through the leader, until it succeeds or the Node stops.
+func (n *Node) updateAdvertisedAddress() {
+    address := n.opts.raftAdvertisedAddress()
+    ticker := time.NewTicker(addressUpdateInterval)
+    defer ticker.Stop()
+    for {
+        done, err := n.tryUpdateAdvertisedAddress(address)
+        if done {
+            return
+        }
+        if err != nil {
+            n.logger.Debug("Could not update the address of the node in the RAFT configuration yet", "address", address, "error", err)
+        }
+        select {
+        case <-ticker.C:
+        case <-n.stopCh:
+            return
+        }
+    }
+}
+
+func (n *Node) tryUpdateAdvertisedAddress(address raft.ServerAddress) (bool, error) {
+    servers, err := n.servers()
+    if err != nil {
+        return false, err
+    }
+    self := findServer(servers, n.opts.raftServerID())
+    if self == nil || self.Address == address {
+        // not a member (yet), the node is added with its current address by whoever joins it
+        return true, nil
+    }
+    n.logger.Debug("Updating the address of the node in the RAFT configuration", "previous address", self.Address, "address", address)
+
+    ctx, cancel := context.WithTimeout(context.Background(), 10*addressUpdateInterval)
+    defer cancel()
+    if n.raft.State() == raft.Leader {
+        var future raft.IndexFuture
+        if self.Suffrage == raft.Voter {
+            future = n.raft.AddVoter(self.ID, address, 0, 0)
+        } else {
+            future = n.raft.AddNonvoter(self.ID, address, 0, 0)
+        }
+        if err := future.Error(); err != nil {
+            return false, err
+        }
+    } else {
+        // the leader may not be known while it still tries to reach the node at its previous address,
+        // so every other member is asked in turn, only the leader accepts the change
+        targets := []raft.ServerAddress{}
+        if leaderAddress, leaderID := n.raft.LeaderWithID(); leaderID != "" && leaderID != self.ID {
+            targets = append(targets, leaderAddress)
+        }
+        for _, server := range servers {
+            if server.ID != self.ID {
+                targets = append(targets, server.Address)
+            }
+        }
+        err = errors.New("no other member in the RAFT configuration")
+        for _, target := range targets {
+            if err = addServer(ctx, n.adminDialOptions, string(target), string(self.ID), string(address), self.Suffrage); err == nil {
+                break
+            }
+        }
+        if err != nil {
+            return false, err
+        }
+    }
+    n.logger.Info("Address of the node updated in the RAFT configuration", "address", address)
+    return true, nil
+}
+
+func findServer(servers []raft.Server, id raft.ServerID) *raft.Server {
+    for i := range servers {
+        if servers[i].ID == id {
+            return &servers[i]
+        }
+    }
+    return nil
+}
+
+// addServer asks the leader at target, through its raftadmin service, to add the server id at address,
+// or to update its address if it is already a member.
+func addServer(ctx context.Context, dialOptions []grpc.DialOption, target string, id string, address string, suffrage raft.ServerSuffrage) error {
+    return callAdmin(ctx, dialOptions, target, func(client adminpb.RaftAdminClient) (*adminpb.Future, error) {
+        if suffrage == raft.Voter {
+            return client.AddVoter(ctx, &adminpb.AddVoterRequest{Id: id, Address: address})
+        }
+        return client.AddNonvoter(ctx, &adminpb.AddNonvoterRequest{Id: id, Address: address})
+    })
+}
+
+// removeServer asks the leader at target, through its raftadmin service, to remove the server id.
+func removeServer(ctx context.Context, dialOptions []grpc.DialOption, target string, id string) error {
+    return callAdmin(ctx, dialOptions, target, func(client adminpb.RaftAdminClient) (*adminpb.Future, error) {
+        return client.RemoveServer(ctx, &adminpb.RemoveServerRequest{Id: id})
+    })
+}
+
+// callAdmin sends a raftadmin request to target and awaits the resulting future.
+func callAdmin(ctx context.Context, dialOptions []grpc.DialOption, target string, call func(adminpb.RaftAdminClient) (*adminpb.Future, error)) error {
+    conn, err := grpc.DialContext(ctx, target, dialOptions...)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    client := adminpb.NewRaftAdminClient(conn)
+    future, err := call(client)
+    if err != nil {
+        return err
+    }
+    resp, err := client.Await(ctx, future)
+    if err != nil {
+        return err
+    }
+    if _, err := client.Forget(ctx, future); err != nil {
+        return err
+    }
+    if resp.Error != "" {
+        return errors.New(resp.Error)
+    }
+    return nil
+}
@@ -0,0 +1,213 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "net"
+    "net/http"
+    "strconv"
+    "sync"
+    "time"
+
+    metrics "github.com/armon/go-metrics"
+    metricsprometheus "github.com/armon/go-metrics/prometheus"
+    "github.com/hashicorp/raft"
+    "github.com/prometheus/client_golang/prometheus"
+    "github.com/prometheus/client_golang/prometheus/collectors"
+    "github.com/prometheus/client_golang/prometheus/promauto"
+    "github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const metricsNamespace = "jina_raft"
+
+// metricsRegistry holds the Jina series, the Go runtime ones and, once a Node serves metrics,
+// the Raft internals reported by hashicorp/raft through go-metrics. It is shared by the Nodes of the process, the
+// state of each Raft instance is kept in the registry of its metrics server.
+var metricsRegistry = prometheus.NewRegistry()
+
+// snapshotBuckets cover Executor snapshots and restores, from 100ms to about 15 minutes.
+var snapshotBuckets = prometheus.ExponentialBuckets(0.1, 2, 14)
+
+var (
+    requestsTotal = promauto.With(metricsRegistry).NewCounterVec(prometheus.CounterOpts{
+        Namespace: metricsNamespace,
+        Name:      "requests_total",
+        Help:      "Data requests received, by Executor endpoint and kind (write or read).",
+    }, []string{"endpoint", "kind"})
+    writesTotal = promauto.With(metricsRegistry).NewCounterVec(prometheus.CounterOpts{
+        Namespace: metricsNamespace,
+        Name:      "writes_total",
+        Help:      "Write requests by outcome: applied, or rejected because the node is not the leader, is draining or failed to commit.",
+    }, []string{"outcome"})
+    executorApplySeconds = promauto.With(metricsRegistry).NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: metricsNamespace,
+        Name:      "executor_apply_seconds",
+        Help:      "Time spent by executorFSM.Apply calling the Executor, by outcome.",
+        Buckets:   prometheus.DefBuckets,
+    }, []string{"outcome"})
+    executorCircuitOpen = promauto.With(metricsRegistry).NewGaugeVec(prometheus.GaugeOpts{
+        Namespace: metricsNamespace,
+        Name:      "executor_circuit_open",
+        Help:      "Whether the circuit breaker of the idempotent calls to the Executor is open, by Raft ID of the Node.",
+    }, []string{"raft_id"})
+    snapshotSeconds = promauto.With(metricsRegistry).NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: metricsNamespace,
+        Name:      "snapshot_seconds",
+        Help:      "Duration of the Executor snapshots persisted into the Raft snapshot store, by outcome.",
+        Buckets:   snapshotBuckets,
+    }, []string{"outcome"})
+    restoreSeconds = promauto.With(metricsRegistry).NewHistogramVec(prometheus.HistogramOpts{
+        Namespace: metricsNamespace,
+        Name:      "restore_seconds",
+        Help:      "Duration of the Executor restores from a Raft snapshot, by outcome.",
+        Buckets:   snapshotBuckets,
+    }, []string{"outcome"})
+    executorOperationInProgress = promauto.With(metricsRegistry).NewGaugeVec(prometheus.GaugeOpts{
+        Namespace: metricsNamespace,
+        Name:      "executor_operation_in_progress",
+        Help:      "Whether a snapshot or a restore of the Executor is in progress, by operation.",
+    }, []string{"operation"})
+    executorOperationElapsed = promauto.With(metricsRegistry).NewGaugeVec(prometheus.GaugeOpts{
+        Namespace: metricsNamespace,
+        Name:      "executor_operation_elapsed_seconds",
+        Help:      "Time spent in the last snapshot or restore of the Executor, updated at every poll of its status, by operation.",
+    }, []string{"operation"})
+    snapshotTransferredBytes = promauto.With(metricsRegistry).NewCounterVec(prometheus.CounterOpts{
+        Namespace: metricsNamespace,
+        Name:      "snapshot_transferred_bytes_total",
+        Help:      "Bytes of snapshots transferred from the Executor into the Raft snapshot store and back, by operation.",
+    }, []string{"operation"})
+)
+
+// Outcomes of writes_total.
+const (
+    writeApplied       = "applied"
+    writeExecutorError = "executor_error"
+    writeNotLeader     = "not_leader"
+    writeDraining      = "draining"
+    writeFailed        = "failed"
+)
+
+func init() {
+    metricsRegistry.MustRegister(
+        collectors.NewGoCollector(),
+        collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+    )
+}
+
+// outcome labels a duration observation from the error of the measured operation.
+func outcome(err error) string {
+    if errors.Is(err, ErrExecutorTimeout) {
+        return "timeout"
+    }
+    if err != nil {
+        return "failure"
+    }
+    return "success"
+}
+
+func observeSince(histogram *prometheus.HistogramVec, start time.Time, err error) {
+    histogram.WithLabelValues(outcome(err)).Observe(time.Since(start).Seconds())
+}
+
+var raftMetricsOnce sync.Once
+
+// enableRaftMetrics routes the go-metrics global sink, used by hashicorp/raft, to metricsRegistry.
+// The sink is global to the process, so it is only installed once.
+func enableRaftMetrics() error {
+    var err error
+    raftMetricsOnce.Do(func() {
+        var sink *metricsprometheus.PrometheusSink
+        sink, err = metricsprometheus.NewPrometheusSinkFrom(metricsprometheus.PrometheusOpts{
+            Expiration: 0,
+            Registerer: metricsRegistry,
+        })
+        if err != nil {
+            return
+        }
+        config := metrics.DefaultConfig("")
+        config.EnableHostname = false
+        config.EnableRuntimeMetrics = false
+        _, err = metrics.NewGlobal(config, sink)
+    })
+    return err
+}
+
+// raftCollector exports the state of a Raft instance, read from its stats at every scrape.
+type raftCollector struct {
+    raft   *raft.Raft
+    gauges map[string]*prometheus.Desc
+    leader *prometheus.Desc
+}
+
+func newRaftCollector(r *raft.Raft) *raftCollector {
+    gauge := func(name string, help string) *prometheus.Desc {
+        return prometheus.NewDesc(prometheus.BuildFQName(metricsNamespace, "", name), help, nil, nil)
+    }
+    return &raftCollector{
+        raft: r,
+        gauges: map[string]*prometheus.Desc{
+            "term":                gauge("term", "Current Raft term."),
+            "commit_index":        gauge("commit_index", "Index of the last committed log entry."),
+            "applied_index":       gauge("applied_index", "Index of the last log entry applied to the Executor FSM."),
+            "last_log_index":      gauge("last_log_index", "Index of the last log entry stored."),
+            "last_snapshot_index": gauge("last_snapshot_index", "Index of the last log entry included in a snapshot."),
+            "num_peers":           gauge("peers", "Number of other voters in the Raft configuration."),
+        },
+        leader: gauge("is_leader", "1 if the node is the Raft leader, 0 otherwise."),
+    }
+}
+
+func (c *raftCollector) Describe(ch chan<- *prometheus.Desc) {
+    for _, desc := range c.gauges {
+        ch <- desc
+    }
+    ch <- c.leader
+}
+
+func (c *raftCollector) Collect(ch chan<- prometheus.Metric) {
+    stats := c.raft.Stats()
+    for stat, desc := range c.gauges {
+        value, err := strconv.ParseFloat(stats[stat], 64)
+        if err != nil {
+            continue
+        }
+        ch <- prometheus.MustNewConstMetric(desc, prometheus.GaugeValue, value)
+    }
+    leader := 0.0
+    if c.raft.State() == raft.Leader {
+        leader = 1
+    }
+    ch <- prometheus.MustNewConstMetric(c.leader, prometheus.GaugeValue, leader)
+}
+
+// httpServer adapts an http.Server to the servable listeners of a Node.
+type httpServer struct {
+    *http.Server
+}
+
+func (s httpServer) Serve(sock net.Listener) error {
+    if err := s.Server.Serve(sock); err != http.ErrServerClosed {
+        return err
+    }
+    return nil
+}
+
+func (s httpServer) GracefulStop() {
+    s.Server.Shutdown(context.Background())
+}
+
+func (s httpServer) Stop() {
+    s.Server.Close()
+}
+
+// newMetricsServer serves metricsRegistry and the state of r at /metrics.
+func newMetricsServer(r *raft.Raft) (httpServer, error) {
+    registry := prometheus.NewRegistry()
+    if err := registry.Register(newRaftCollector(r)); err != nil {
+        return httpServer{}, err
+    }
+    mux := http.NewServeMux()
+    mux.Handle("/metrics", promhttp.HandlerFor(prometheus.Gatherers{metricsRegistry, registry}, promhttp.HandlerOpts{}))
+    return httpServer{&http.Server{Handler: mux, ReadHeaderTimeout: 10 * time.Second}}, nil
+}
@@ -0,0 +1,67 @@
+package server
+
+import (
+    "io"
+    "net/http/httptest"
+    "strings"
+    "testing"
+)
+
+func TestMetricsServerPerNode(t *testing.T) {
+    nodes := newTestCluster(t, 2)
+    leaders := 0
+    for _, r := range nodes {
+        // a second Node of the process does not collide with the Raft gauges of the first one
+        server, err := newMetricsServer(r)
+        if err != nil {
+            t.Fatalf("newMetricsServer() = %v", err)
+        }
+        recorder := httptest.NewRecorder()
+        server.Handler.ServeHTTP(recorder, httptest.NewRequest("GET", "/metrics", nil))
+        body, _ := io.ReadAll(recorder.Body)
+        switch {
+        case strings.Contains(string(body), "\njina_raft_is_leader 1\n"):
+            leaders++
+        case !strings.Contains(string(body), "\njina_raft_is_leader 0\n"):
+            t.Fatalf("/metrics has no jina_raft_is_leader:\n%s", body)
+        }
+        if !strings.Contains(string(body), "go_goroutines") {
+            t.Fatal("/metrics has no series of metricsRegistry")
+        }
+    }
+    if leaders != 1 {
+        t.Fatalf("%d nodes report being the leader, want 1", leaders)
+    }
+}
+
+func TestEndpointLabel(t *testing.T) {
+    fsm := &executorFSM{write_endpoints: []string{"/index"}}
+    tests := []struct {
+        endpoint string
+        label    string
+    }{
+        {endpoint: "/index", label: "/index"},
+        {endpoint: "/search", label: otherEndpoint},
+        {endpoint: "", label: otherEndpoint},
+    }
+    for _, test := range tests {
+        if label := fsm.endpointLabel(test.endpoint); label != test.label {
+            t.Errorf("endpointLabel(%q) before the discovery = %q, want %q", test.endpoint, label, test.label)
+        }
+    }
+
+    fsm.endpoints.Store(&[]string{"/index", "/search"})
+    tests = []struct {
+        endpoint string
+        label    string
+    }{
+        {endpoint: "/index", label: "/index"},
+        {endpoint: "/search", label: "/search"},
+        {endpoint: "/random-1234", label: otherEndpoint},
+    }
+    for _, test := range tests {
+        if label := fsm.endpointLabel(test.endpoint); label != test.label {
+            t.Errorf("endpointLabel(%q) = %q, want %q", test.endpoint, label, test.label)
+        }
+    }
+}
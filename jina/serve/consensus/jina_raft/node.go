@@ -0,0 +1,707 @@
+package server
+
+import (
+    "context"
+    "fmt"
+    "net"
+    "os"
+    "path/filepath"
+    "sync"
+    "time"
+
+    transport "github.com/Jille/raft-grpc-transport"
+    "github.com/Jille/raftadmin"
+    "github.com/hashicorp/raft"
+    boltdb "github.com/hashicorp/raft-boltdb"
+    pb "jraft/jina-go-proto"
+    jraftpb "jraft/jraft-go-proto"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials"
+    "google.golang.org/grpc/reflection"
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+    "github.com/Jille/raft-grpc-leader-rpc/leaderhealth"
+    hclog "github.com/hashicorp/go-hclog"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+)
+
+// Options holds everything needed to create a Jina consensus Node.
+type Options struct {
+    // Name identifies the Node in the loggers
+    Name                     string
+    // Address is the host+port where the Node listens for the Jina gRPC services, and for the Raft
+    // transport unless RaftAddress is set. The host is respected, use "0.0.0.0" or "[::]" to listen on all interfaces
+    Address                  string
+    // AdvertiseAddress is the host+port clients use to reach Address, e.g. behind NAT or port mapping. Defaults to Address
+    AdvertiseAddress         string
+    // RaftAddress, if set, is the host+port where the Node listens for the Raft transport, separately from Address
+    RaftAddress              string
+    // RaftAdvertiseAddress is the host+port the other Raft nodes use to reach this Node and the address stored in the
+    // Raft configuration. Defaults to RaftAddress if set, otherwise to AdvertiseAddress
+    RaftAdvertiseAddress     string
+    // RaftID is the ID of the Node inside the Raft cluster
+    RaftID                   string
+    // RaftDir is the parent folder of the Raft data, stored under RaftDir/RaftID
+    RaftDir                  string
+    // AdminAddress, if set, is the host+port where the Node serves raftadmin, JinaRaftAdmin and reflection
+    // instead of Address, so that they are not reachable from the public endpoint. It requires RaftAddress, where
+    // the other members call raftadmin and JinaRaftStatus
+    AdminAddress             string
+    // MetricsAddress, if set, is the host+port where the Node serves its Prometheus metrics over HTTP at /metrics
+    MetricsAddress           string
+    // TracingEndpoint, if set, is the host+port of the OTLP/gRPC collector receiving the spans of the Node
+    TracingEndpoint          string
+    // TracingInsecure exports the spans to TracingEndpoint without TLS
+    TracingInsecure          bool
+    // TracingServiceName is the service name of the exported spans. Defaults to "RAFT-" + Name
+    TracingServiceName       string
+    // AuthPolicyFile is a YAML or JSON AuthPolicy enforced on every gRPC method, reloaded when it changes.
+    // Without it, every method is open to every caller
+    AuthPolicyFile           string
+    // AuthTokenFile holds the bearer token presented by the Node in its raftadmin calls to the other members
+    AuthTokenFile            string
+    // ExecutorTarget is the host+port of the underlying Executor, or its unix socket as unix:///absolute/path
+    // or unix:relative/path
+    ExecutorTarget           string
+    // ExecutorSocket, if set, is the path of the unix socket of the Executor, used instead of ExecutorTarget
+    ExecutorSocket           string
+    // TLSCertFile and TLSKeyFile enable TLS on Address. Certificate files are reloaded when they change
+    TLSCertFile              string
+    TLSKeyFile               string
+    // TLSCAFile verifies the client certificates with TLSClientAuth, and the Raft peers when they share Address
+    TLSCAFile                string
+    // TLSClientAuth requires clients of Address to present a certificate signed by TLSCAFile
+    TLSClientAuth            bool
+    // RaftTLSCertFile, RaftTLSKeyFile and RaftTLSCAFile secure the Raft transport served on RaftAddress,
+    // the certificate is also presented to the other members when dialing them
+    RaftTLSCertFile          string
+    RaftTLSKeyFile           string
+    RaftTLSCAFile            string
+    // RaftTLSVerifyPeers requires the Raft peers to present a certificate signed by RaftTLSCAFile (mutual TLS),
+    // so that only cluster members can append entries
+    RaftTLSVerifyPeers       bool
+    // ExecutorTLSCertFile, ExecutorTLSKeyFile and ExecutorTLSCAFile secure the connection to the Executor
+    ExecutorTLSCertFile      string
+    ExecutorTLSKeyFile       string
+    ExecutorTLSCAFile        string
+    // ExecutorTLSServerName overrides the name verified in the Executor certificate
+    ExecutorTLSServerName    string
+    // ExecutorKeepaliveTime is the time without activity after which the connection to the Executor is pinged
+    ExecutorKeepaliveTime    time.Duration
+    // ExecutorStartupTimeout bounds the wait for the Executor to answer when the Node starts
+    ExecutorStartupTimeout   time.Duration
+    // ExecutorCallAttempts is the number of times an idempotent call (read, health check, discovery, status)
+    // is sent while the Executor is unavailable. Writes, snapshots and restores are never retried
+    ExecutorCallAttempts     int
+    // ExecutorBreakerFailures is the number of consecutive idempotent calls failing because the Executor is
+    // unreachable after which they fail fast for ExecutorBreakerCooldown. 0 disables the circuit breaker
+    ExecutorBreakerFailures  int
+    ExecutorBreakerCooldown  time.Duration
+    // ExecutorPollInterval is the time before the first check of the status of a snapshot or a restore of the
+    // Executor, doubled after each check up to ExecutorPollMaxInterval
+    ExecutorPollInterval     time.Duration
+    ExecutorPollMaxInterval  time.Duration
+    // ExecutorApplyTimeout bounds a write sent to the Executor, waiting for a restarting Executor included. A write
+    // that times out fails on this replica, so that the later entries are not held behind it
+    ExecutorApplyTimeout     time.Duration
+    // ExecutorSnapshotTimeout and ExecutorRestoreTimeout bound the time the Executor takes to finish a snapshot or
+    // a restore, which fails once it elapsed. ExecutorSnapshotTimeout bounds the stream of the snapshot as well
+    ExecutorSnapshotTimeout  time.Duration
+    ExecutorRestoreTimeout   time.Duration
+    HeartbeatTimeout         time.Duration
+    ElectionTimeout          time.Duration
+    CommitTimeout            time.Duration
+    MaxAppendEntries         int
+    BatchApplyCh             bool
+    ShutdownOnRemove         bool
+    TrailingLogs             uint64
+    SnapshotInterval         time.Duration
+    SnapshotThreshold        uint64
+    // SnapshotRetain is the number of most recent snapshots kept in the snapshot store, besides the pinned ones
+    SnapshotRetain           int
+    // SnapshotCompression compresses the Executor snapshots stored and sent to the lagging members: none, gzip or zstd
+    SnapshotCompression      string
+    // SnapshotKeyFile is a YAML or JSON SnapshotKeyring encrypting the snapshots, reloaded when it changes.
+    // Without it, snapshots are stored in plaintext
+    SnapshotKeyFile          string
+    // SnapshotKeyProvider is used instead of SnapshotKeyFile by the programs embedding a Node, to keep the keys in
+    // a key management service
+    SnapshotKeyProvider      SnapshotKeyProvider
+    // SnapshotAllowPlaintext restores the unencrypted snapshots with SnapshotKeyFile or SnapshotKeyProvider, which
+    // are otherwise rejected, while the snapshots taken before the keys were set are replaced
+    SnapshotAllowPlaintext   bool
+    LeaderLeaseTimeout       time.Duration
+    LogLevel                 string
+    NoSnapshotRestoreOnStart bool
+    // DrainTimeout bounds how long a decommission waits for in-flight writes and a new leader
+    DrainTimeout             time.Duration
+    // DecommissionOnSigterm makes the CLI and the Python binding decommission the Node before shutting down on SIGTERM
+    DecommissionOnSigterm    bool
+    // LeaveClusterOnDecommission removes the Node from the Raft configuration when decommissioning on SIGTERM
+    LeaveClusterOnDecommission bool
+}
+
+// DefaultOptions returns Options filled with the defaults of raft.DefaultConfig.
+func DefaultOptions() Options {
+    raftDefaultConfig := raft.DefaultConfig()
+    return Options{
+        Name:                     "executor",
+        Address:                  "localhost:50051",
+        RaftDir:                  "data/",
+        ExecutorTarget:           "localhost:54321",
+        HeartbeatTimeout:         raftDefaultConfig.HeartbeatTimeout,
+        ElectionTimeout:          raftDefaultConfig.ElectionTimeout,
+        CommitTimeout:            raftDefaultConfig.CommitTimeout,
+        MaxAppendEntries:         raftDefaultConfig.MaxAppendEntries,
+        BatchApplyCh:             raftDefaultConfig.BatchApplyCh,
+        ShutdownOnRemove:         raftDefaultConfig.ShutdownOnRemove,
+        TrailingLogs:             raftDefaultConfig.TrailingLogs,
+        SnapshotInterval:         raftDefaultConfig.SnapshotInterval,
+        SnapshotThreshold:        raftDefaultConfig.SnapshotThreshold,
+        SnapshotRetain:           3,
+        SnapshotCompression:      "none",
+        LeaderLeaseTimeout:       raftDefaultConfig.LeaderLeaseTimeout,
+        LogLevel:                 raftDefaultConfig.LogLevel,
+        NoSnapshotRestoreOnStart: raftDefaultConfig.NoSnapshotRestoreOnStart,
+        DrainTimeout:             30 * time.Second,
+        DecommissionOnSigterm:    true,
+        TracingInsecure:          true,
+        ExecutorKeepaliveTime:    defaultExecutorPolicy().keepaliveTime,
+        ExecutorStartupTimeout:   defaultExecutorPolicy().startupTimeout,
+        ExecutorCallAttempts:     defaultExecutorPolicy().callAttempts,
+        ExecutorBreakerFailures:  defaultExecutorPolicy().breakerFailures,
+        ExecutorBreakerCooldown:  defaultExecutorPolicy().breakerCooldown,
+        ExecutorPollInterval:     defaultExecutorPolicy().pollInterval,
+        ExecutorPollMaxInterval:  defaultExecutorPolicy().pollMaxInterval,
+        ExecutorApplyTimeout:     defaultExecutorPolicy().applyTimeout,
+        ExecutorSnapshotTimeout:  defaultExecutorPolicy().snapshotTimeout,
+        ExecutorRestoreTimeout:   defaultExecutorPolicy().restoreTimeout,
+    }
+}
+
+// executorTarget is the gRPC target of the Executor.
+func (opts Options) executorTarget() string {
+    if opts.ExecutorSocket == "" {
+        return opts.ExecutorTarget
+    }
+    if filepath.IsAbs(opts.ExecutorSocket) {
+        return "unix://" + opts.ExecutorSocket
+    }
+    return "unix:" + opts.ExecutorSocket
+}
+
+// executorPolicy is the policy of the connection to the Executor.
+func (opts Options) executorPolicy() executorPolicy {
+    policy := defaultExecutorPolicy()
+    policy.keepaliveTime = opts.ExecutorKeepaliveTime
+    policy.startupTimeout = opts.ExecutorStartupTimeout
+    policy.callAttempts = opts.ExecutorCallAttempts
+    policy.breakerFailures = opts.ExecutorBreakerFailures
+    policy.breakerCooldown = opts.ExecutorBreakerCooldown
+    policy.pollInterval = opts.ExecutorPollInterval
+    policy.pollMaxInterval = opts.ExecutorPollMaxInterval
+    policy.applyTimeout = opts.ExecutorApplyTimeout
+    policy.snapshotTimeout = opts.ExecutorSnapshotTimeout
+    policy.restoreTimeout = opts.ExecutorRestoreTimeout
+    // checked by Validate
+    policy.snapshotCodec, _ = parseSnapshotCodec(opts.SnapshotCompression)
+    return policy
+}
+
+func (opts Options) raftServerID() raft.ServerID {
+    return raft.ServerID(opts.RaftID)
+}
+
+// advertisedAddress is the address where clients reach the Jina gRPC services.
+func (opts Options) advertisedAddress() string {
+    if opts.AdvertiseAddress != "" {
+        return opts.AdvertiseAddress
+    }
+    return opts.Address
+}
+
+// raftAdvertisedAddress is the address of the Node in the Raft configuration.
+func (opts Options) raftAdvertisedAddress() raft.ServerAddress {
+    if opts.RaftAdvertiseAddress != "" {
+        return raft.ServerAddress(opts.RaftAdvertiseAddress)
+    }
+    if opts.RaftAddress != "" {
+        return raft.ServerAddress(opts.RaftAddress)
+    }
+    return raft.ServerAddress(opts.advertisedAddress())
+}
+
+// raftConfig translates the Options into the configuration of the Raft instance.
+func (opts Options) raftConfig(logger hclog.Logger) *raft.Config {
+    config := raft.DefaultConfig()
+    config.LocalID                  = raft.ServerID(opts.RaftID)
+    config.HeartbeatTimeout         = opts.HeartbeatTimeout
+    config.ElectionTimeout          = opts.ElectionTimeout
+    config.CommitTimeout            = opts.CommitTimeout
+    config.MaxAppendEntries         = opts.MaxAppendEntries
+    config.BatchApplyCh             = opts.BatchApplyCh
+    config.ShutdownOnRemove         = opts.ShutdownOnRemove
+    config.TrailingLogs             = opts.TrailingLogs
+    config.SnapshotInterval         = opts.SnapshotInterval
+    config.SnapshotThreshold        = opts.SnapshotThreshold
+    config.LeaderLeaseTimeout       = opts.LeaderLeaseTimeout
+    config.LogLevel                 = opts.LogLevel
+    config.NoSnapshotRestoreOnStart = opts.NoSnapshotRestoreOnStart
+    config.Logger                   = logger
+    return config
+}
+
+// servable is a server of the Node, implemented by grpc.Server and httpServer.
+type servable interface {
+    Serve(net.Listener) error
+    GracefulStop()
+    Stop()
+}
+
+// listener is a server of the Node served on its own address.
+type listener struct {
+    name    string
+    address string
+    server  servable
+    sock    net.Listener
+}
+
+// Node is a Jina consensus node: a Raft instance replicating write requests to an Executor,
+// together with the gRPC servers exposing the Jina, Raft transport and raftadmin services.
+type Node struct {
+    opts         Options
+    logger       hclog.Logger
+    fsm          *executorFSM
+    rpc          *RpcInterface
+    raft         *raft.Raft
+    transport    *transport.Manager
+    // snapshots is the snapshot store of the Raft instance
+    snapshots    *snapshotStore
+    // closeRaft closes the log stores and the transport connections of the Raft instance, once it is shut down
+    closeRaft    func()
+    // raftDialOption secures the Raft transport connections to the other members
+    raftDialOption grpc.DialOption
+    // adminDialOptions secure and authenticate the raftadmin calls to the other members
+    adminDialOptions []grpc.DialOption
+    grpcServer   *grpc.Server
+    // health reports the Raft role and readiness and the Executor health through the gRPC health service
+    health       *healthMonitor
+    listeners    []*listener
+    // tracerProvider exports the spans of the Node, nil unless TracingEndpoint is set
+    tracerProvider *sdktrace.TracerProvider
+    serveErr     chan error
+    stopCh       chan struct{}
+    shutdownOnce sync.Once
+    shutdownErr  error
+    // mtx guards the reloadable fields of opts
+    mtx          sync.RWMutex
+}
+
+// NewNode creates the Executor FSM, the Raft instance and the gRPC server of a Node.
+// Nothing is served until Start is called.
+func NewNode(opts Options) (*Node, error) {
+    if err := opts.Validate(); err != nil {
+        return nil, err
+    }
+    logger := hclog.New(&hclog.LoggerOptions{
+                    Name:   "RAFT-" + opts.Name,
+                    Level:  hclog.LevelFromString(opts.LogLevel),
+                })
+    logger.Debug("Creating RAFT node in", "address", opts.Address, "advertised as", opts.raftAdvertisedAddress(), "with the ID", opts.RaftID, "in directory", opts.RaftDir, "and connecting to Executor", opts.executorTarget())
+
+    if host, _, _ := net.SplitHostPort(string(opts.raftAdvertisedAddress())); isUnspecifiedHost(host) {
+        logger.Warn("The advertised RAFT address is not reachable by the other nodes, set raft_advertise_address", "address", opts.raftAdvertisedAddress())
+    }
+
+    executorDialOptions, err := opts.executorDialOptions()
+    if err != nil {
+        return nil, err
+    }
+    raftDialOption, err := opts.RaftDialOption()
+    if err != nil {
+        return nil, err
+    }
+    adminDialOptions, err := opts.AdminDialOptions()
+    if err != nil {
+        return nil, err
+    }
+    serverCreds, err := opts.clientServerCredentials()
+    if err != nil {
+        return nil, fmt.Errorf("TLS: %v", err)
+    }
+    raftServerCreds, err := opts.raftServerCredentials()
+    if err != nil {
+        return nil, fmt.Errorf("RAFT TLS: %v", err)
+    }
+
+    unaryInterceptors := []grpc.UnaryServerInterceptor{}
+    streamInterceptors := []grpc.StreamServerInterceptor{}
+    if opts.AuthPolicyFile != "" {
+        authz, err := newAuthorizer(opts.AuthPolicyFile)
+        if err != nil {
+            return nil, err
+        }
+        if authz.defaults && !(opts.RaftAddress != "" && opts.RaftTLSVerifyPeers) {
+            return nil, fmt.Errorf("auth policy %q has no rules: the default rules authenticate the RAFT peers by their certificate, which requires raft_address with raft_tls_verify_peers, otherwise add a rule for %s", opts.AuthPolicyFile, RaftPlane)
+        }
+        unaryInterceptors = append(unaryInterceptors, authz.unaryInterceptor)
+        streamInterceptors = append(streamInterceptors, authz.streamInterceptor)
+    }
+    snapshotKeys, err := opts.snapshotKeyProvider()
+    if err != nil {
+        return nil, err
+    }
+    var tracerProvider *sdktrace.TracerProvider
+    if opts.TracingEndpoint != "" {
+        tracerProvider, err = newTracerProvider(context.Background(), opts)
+        if err != nil {
+            return nil, fmt.Errorf("tracing: %v", err)
+        }
+    }
+    executorFSM := newExecutorFSM(opts.executorTarget(), opts.executorPolicy(), opts.LogLevel, opts.Name, opts.RaftID, executorDialOptions...)
+    executorFSM.snapshotKeys = snapshotKeys
+    executorFSM.snapshotAllowPlaintext = opts.SnapshotAllowPlaintext
+    if tracerProvider != nil {
+        executorFSM.tracerProvider = tracerProvider
+    }
+
+    // release closes the connection to the Executor and the tracer provider of a Node that could not be created
+    release := func() {
+        executorFSM.executor.close()
+        if tracerProvider != nil {
+            tracerProvider.Shutdown(context.Background())
+        }
+    }
+    r, tm, snapshots, closeRaft, err := newRaft(opts, logger, executorFSM, raftDialOption)
+    if err != nil {
+        logger.Error("Failed to start RAFT node", "error", err)
+        release()
+        return nil, err
+    }
+    // fail shuts down the Raft instance started by newRaft, which would otherwise keep running with its stores open
+    fail := func(err error) (*Node, error) {
+        if err := r.Shutdown().Error(); err != nil {
+            logger.Warn("Error shutting RAFT down", "error", err)
+        }
+        closeRaft()
+        release()
+        return nil, err
+    }
+
+    newServer := func(creds credentials.TransportCredentials) *grpc.Server {
+        return grpc.NewServer(
+            grpc.Creds(creds),
+            grpc.ChainUnaryInterceptor(unaryInterceptors...),
+            grpc.ChainStreamInterceptor(streamInterceptors...),
+        )
+    }
+
+    grpcServer := newServer(serverCreds)
+    rpc_logger := hclog.New(&hclog.LoggerOptions{
+                    Name:   "RPC-" + opts.Name,
+                    Level:  hclog.LevelFromString(opts.LogLevel),
+                })
+
+    rpc_interface := &RpcInterface{
+                            Executor: executorFSM,
+                            Raft:     r,
+                            Logger:   rpc_logger,
+                        }
+
+    pb.RegisterJinaSingleDataRequestRPCServer(grpcServer, rpc_interface)
+    pb.RegisterJinaDiscoverEndpointsRPCServer(grpcServer, rpc_interface)
+    pb.RegisterJinaInfoRPCServer(grpcServer, rpc_interface)
+    pb.RegisterJinaRPCServer(grpcServer, rpc_interface)
+    listeners := []*listener{{name: "client", address: opts.Address, server: grpcServer}}
+    // the other nodes only know the Raft address, they ask for the status and send the membership changes there
+    peerServer := grpcServer
+    if opts.RaftAddress != "" {
+        peerServer = newServer(raftServerCreds)
+        listeners = append(listeners, &listener{name: "raft", address: opts.RaftAddress, server: peerServer})
+    }
+    tm.Register(peerServer)
+
+    adminServer := grpcServer
+    if opts.AdminAddress != "" {
+        // the admin plane is dialed with RaftDialOption, so it is served with the same credentials as the Raft
+        // address, which Validate requires
+        adminServer = newServer(raftServerCreds)
+        listeners = append(listeners, &listener{name: "admin", address: opts.AdminAddress, server: adminServer})
+    }
+    statusServers := []*grpc.Server{peerServer}
+    if adminServer != peerServer {
+        statusServers = append(statusServers, adminServer)
+    }
+    for _, server := range statusServers {
+        raftadmin.Register(server, r)
+    }
+    reflection.Register(adminServer)
+
+    if opts.MetricsAddress != "" {
+        if err := enableRaftMetrics(); err != nil {
+            return fail(fmt.Errorf("enabling RAFT metrics: %v", err))
+        }
+        metricsServer, err := newMetricsServer(r)
+        if err != nil {
+            return fail(fmt.Errorf("registering RAFT metrics: %v", err))
+        }
+        listeners = append(listeners, &listener{name: "metrics", address: opts.MetricsAddress, server: metricsServer})
+    }
+
+    node := &Node{
+        opts:       opts,
+        logger:     logger,
+        fsm:        executorFSM,
+        rpc:        rpc_interface,
+        raft:       r,
+        transport:  tm,
+        snapshots:  snapshots,
+        closeRaft:  closeRaft,
+        raftDialOption: raftDialOption,
+        adminDialOptions: adminDialOptions,
+        grpcServer: grpcServer,
+        listeners:  listeners,
+        tracerProvider: tracerProvider,
+        serveErr:   make(chan error, len(listeners)),
+        stopCh:     make(chan struct{}),
+    }
+    node.health = newHealthMonitor(node)
+    leaderhealth.Report(r, node.health.server, []string{HealthRaftLeader})
+    healthpb.RegisterHealthServer(grpcServer, node.health.server)
+    jraftpb.RegisterJinaRaftAdminServer(adminServer, &jinaRaftAdminServer{node: node})
+    for _, server := range statusServers {
+        jraftpb.RegisterJinaRaftStatusServer(server, &jinaRaftStatusServer{node: node})
+    }
+    return node, nil
+}
+
+// newRaft starts the Raft instance of the Node. Once it is shut down, closeRaft closes its log stores and the
+// connections of its transport, which raft.Shutdown leaves open.
+func newRaft(opts Options, logger hclog.Logger, fsm raft.FSM, dialOption grpc.DialOption) (r *raft.Raft, tm *transport.Manager, file_snapshot *snapshotStore, closeRaft func(), err error) {
+    config := opts.raftConfig(logger)
+
+    baseDir := filepath.Join(opts.RaftDir, opts.RaftID)
+    err = os.MkdirAll(baseDir, os.ModePerm)
+    if err != nil {
+        return nil, nil, nil, nil, fmt.Errorf(`os.MkdirAll(%q): %v`, baseDir, err)
+    }
+
+    logs_db, err := boltdb.NewBoltStore(filepath.Join(baseDir, "logs.dat"))
+    if err != nil {
+        return nil, nil, nil, nil, fmt.Errorf(`boltdb.NewBoltStore(%q): %v`, filepath.Join(baseDir, "logs.dat"), err)
+    }
+
+    stable_db, err := boltdb.NewBoltStore(filepath.Join(baseDir, "stable.dat"))
+    if err != nil {
+        logs_db.Close()
+        return nil, nil, nil, nil, fmt.Errorf(`boltdb.NewBoltStore(%q): %v`, filepath.Join(baseDir, "stable.dat"), err)
+    }
+
+    peers := &peerConnections{conns: map[*grpc.ClientConn]struct{}{}}
+    closeRaft = func() {
+        peers.close()
+        if err := logs_db.Close(); err != nil {
+            logger.Warn("Error closing the RAFT log store", "error", err)
+        }
+        if err := stable_db.Close(); err != nil {
+            logger.Warn("Error closing the RAFT stable store", "error", err)
+        }
+    }
+
+    file_snapshot, err = newSnapshotStore(baseDir, opts.SnapshotRetain, logger)
+    if err != nil {
+        closeRaft()
+        return nil, nil, nil, nil, fmt.Errorf(`newSnapshotStore(%q, %d): %v`, baseDir, opts.SnapshotRetain, err)
+    }
+
+    tm = transport.New(opts.raftAdvertisedAddress(), []grpc.DialOption{
+        dialOption,
+        grpc.WithChainUnaryInterceptor(peers.unaryInterceptor),
+        grpc.WithChainStreamInterceptor(peers.streamInterceptor),
+    })
+
+    r, err = raft.NewRaft(config, fsm, logs_db, stable_db, file_snapshot, tm.Transport())
+
+    if err != nil {
+        closeRaft()
+        return nil, nil, nil, nil, fmt.Errorf("raft.NewRaft: %v", err)
+    }
+
+    cfg := raft.Configuration{
+        Servers: []raft.Server{
+            {
+                Suffrage: raft.Voter,
+                ID:       raft.ServerID(opts.RaftID),
+                Address:  opts.raftAdvertisedAddress(),
+            },
+        },
+    }
+    f := r.BootstrapCluster(cfg)
+    // raft bootstrap error can be ignored safely https://github.com/hashicorp/raft/blob/44124c28758b8cfb675e90c75a204a08a84f8d4f/api.go#L220
+    if err := f.Error(); err != nil {
+        return r, tm, file_snapshot, closeRaft, nil
+    }
+
+    return r, tm, file_snapshot, closeRaft, nil
+}
+
+// peerConnections records the connections raft-grpc-transport dials to the other members, so that they are closed
+// with the Node: the transport has no Close.
+type peerConnections struct {
+    mtx    sync.Mutex
+    conns  map[*grpc.ClientConn]struct{}
+    closed bool
+}
+
+func (p *peerConnections) add(cc *grpc.ClientConn) {
+    p.mtx.Lock()
+    defer p.mtx.Unlock()
+    if p.closed {
+        // a call raced with the shutdown, its connection is not used again
+        cc.Close()
+        return
+    }
+    p.conns[cc] = struct{}{}
+}
+
+func (p *peerConnections) unaryInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+    p.add(cc)
+    return invoker(ctx, method, req, reply, cc, opts...)
+}
+
+func (p *peerConnections) streamInterceptor(ctx context.Context, desc *grpc.StreamDesc, cc *grpc.ClientConn, method string, streamer grpc.Streamer, opts ...grpc.CallOption) (grpc.ClientStream, error) {
+    p.add(cc)
+    return streamer(ctx, desc, cc, method, opts...)
+}
+
+func (p *peerConnections) close() {
+    p.mtx.Lock()
+    defer p.mtx.Unlock()
+    p.closed = true
+    for cc := range p.conns {
+        cc.Close()
+    }
+    p.conns = nil
+}
+
+// Start listens on the addresses of the Node and serves the gRPC services in the background.
+// Use Wait to block until the servers stop.
+func (n *Node) Start(ctx context.Context) error {
+    var lc net.ListenConfig
+    for _, l := range n.listeners {
+        n.logger.Debug("starting to listen on", "address", l.address, "for", l.name)
+        sock, err := lc.Listen(ctx, "tcp", l.address)
+        if err != nil {
+            n.logger.Error("failed to listen", "address", l.address, "error", err)
+            n.closeListeners()
+            return fmt.Errorf("failed to listen on %q: %v", l.address, err)
+        }
+        l.sock = sock
+    }
+    for _, l := range n.listeners {
+        go func(l *listener) {
+            err := l.server.Serve(l.sock)
+            if err != nil {
+                n.logger.Error("failed to serve", "address", l.address, "error", err)
+            }
+            n.serveErr <- err
+        }(l)
+    }
+    go n.updateAdvertisedAddress()
+    go n.health.run(n.stopCh)
+    go n.watchWriteEndpoints(n.stopCh)
+    return nil
+}
+
+func (n *Node) closeListeners() {
+    for _, l := range n.listeners {
+        if l.sock != nil {
+            l.sock.Close()
+        }
+    }
+}
+
+// Wait blocks until the gRPC servers of a started Node stop, returning the first serving error if any.
+func (n *Node) Wait() error {
+    var err error
+    for range n.listeners {
+        if serveErr := <-n.serveErr; serveErr != nil && err == nil {
+            err = serveErr
+        }
+    }
+    return err
+}
+
+// Shutdown stops the gRPC servers, closes the sockets and shuts Raft down. If ctx expires before
+// the in-flight RPCs finish, the servers are stopped forcefully. Calling Shutdown more than once is safe.
+func (n *Node) Shutdown(ctx context.Context) error {
+    n.shutdownOnce.Do(func() {
+        close(n.stopCh)
+        // report NOT_SERVING while the servers stop, so that clients go elsewhere
+        n.health.server.Shutdown()
+        n.logger.Info("gRPCServer stopping")
+        var wg sync.WaitGroup
+        for _, l := range n.listeners {
+            wg.Add(1)
+            go func(l *listener) {
+                defer wg.Done()
+                l.server.GracefulStop()
+            }(l)
+        }
+        stopped := make(chan struct{})
+        go func() {
+            wg.Wait()
+            close(stopped)
+        }()
+        select {
+        case <-stopped:
+        case <-ctx.Done():
+            n.logger.Warn("gRPCServer graceful stop interrupted, forcing stop", "error", ctx.Err())
+            for _, l := range n.listeners {
+                l.server.Stop()
+            }
+        }
+        n.logger.Info("gRPCServer stopped, close socket")
+        n.closeListeners()
+        n.logger.Info("Socket closed")
+        // every step runs even when an earlier one fails, so that nothing is left open
+        errs := []error{}
+        n.logger.Info("call RAFT shutdown")
+        if err := n.raft.Shutdown().Error(); err != nil {
+            n.logger.Error("Error returned while shutting RAFT down", "error", err)
+            errs = append(errs, err)
+        } else {
+            n.logger.Info("RAFT shutdown whithout error")
+        }
+        n.closeRaft()
+        if err := n.fsm.executor.close(); err != nil {
+            n.logger.Warn("Error closing the connection to the Executor", "error", err)
+            errs = append(errs, err)
+        }
+        if n.tracerProvider != nil {
+            // flush the spans of the last requests
+            if err := n.tracerProvider.Shutdown(ctx); err != nil {
+                n.logger.Warn("Error flushing the spans", "error", err)
+                errs = append(errs, err)
+            }
+        }
+        n.shutdownErr = joinErrors(errs...)
+    })
+    return n.shutdownErr
+}
+
+// Raft returns the Raft instance of the Node.
+func (n *Node) Raft() *raft.Raft {
+    return n.raft
+}
+
+// FSM returns the Executor FSM the Node applies committed logs to.
+func (n *Node) FSM() raft.FSM {
+    return n.fsm
+}
+
+// Options returns the Options of the Node, including the reloaded settings.
+func (n *Node) Options() Options {
+    n.mtx.RLock()
+    defer n.mtx.RUnlock()
+    return n.opts
+}
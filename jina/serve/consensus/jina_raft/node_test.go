@@ -0,0 +1,43 @@
+package server
+
+import (
+    "testing"
+    "time"
+
+    hclog "github.com/hashicorp/go-hclog"
+    "github.com/hashicorp/raft"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials/insecure"
+)
+
+func TestNewRaftReleasesStores(t *testing.T) {
+    opts := DefaultOptions()
+    opts.RaftID = "1"
+    opts.RaftDir = t.TempDir()
+    opts.Address = "localhost:0"
+    dialOption := grpc.WithTransportCredentials(insecure.NewCredentials())
+
+    for i := 0; i < 2; i++ {
+        // the log stores are locked while open, a Raft instance left open blocks the next one on the same directory
+        started := make(chan error, 1)
+        var r *raft.Raft
+        var closeRaft func()
+        go func() {
+            var err error
+            r, _, _, closeRaft, err = newRaft(opts, hclog.NewNullLogger(), &raft.MockFSM{}, dialOption)
+            started <- err
+        }()
+        select {
+        case err := <-started:
+            if err != nil {
+                t.Fatalf("newRaft() = %v", err)
+            }
+        case <-time.After(5 * time.Second):
+            t.Fatal("newRaft() blocked on the stores of the previous Raft instance")
+        }
+        if err := r.Shutdown().Error(); err != nil {
+            t.Fatal(err)
+        }
+        closeRaft()
+    }
+}
@@ -0,0 +1,164 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "sync"
+    "time"
+
+    jraftpb "jraft/jraft-go-proto"
+    "google.golang.org/protobuf/types/known/durationpb"
+)
+
+// ErrExecutorTimeout is returned when the Executor does not finish a snapshot or a restore in time.
+var ErrExecutorTimeout = errors.New("timed out waiting for the Executor")
+
+// statusTimedOut is the status of an operation the Executor did not finish in time.
+const statusTimedOut = "TIMED_OUT"
+
+// Operations tracked by operationProgress, also used as label of the metrics.
+const (
+    operationSnapshot = "snapshot"
+    operationRestore  = "restore"
+)
+
+// operationProgress tracks the last snapshot or restore of the Executor, for the status API and the metrics.
+type operationProgress struct {
+    operation string
+
+    mtx     sync.Mutex
+    started bool
+    id      string
+    status  string
+    start   time.Time
+    end     time.Time
+    bytes   uint64
+    err     error
+}
+
+func newOperationProgress(operation string) *operationProgress {
+    return &operationProgress{operation: operation}
+}
+
+// begin starts tracking a new operation.
+func (p *operationProgress) begin() {
+    p.mtx.Lock()
+    defer p.mtx.Unlock()
+    p.started, p.id, p.status, p.start, p.end, p.bytes, p.err = true, "", "", time.Now(), time.Time{}, 0, nil
+    executorOperationInProgress.WithLabelValues(p.operation).Set(1)
+    executorOperationElapsed.WithLabelValues(p.operation).Set(0)
+}
+
+// update records the ID and the status reported by the Executor.
+func (p *operationProgress) update(id string, status string) {
+    p.mtx.Lock()
+    defer p.mtx.Unlock()
+    p.id, p.status = id, status
+    executorOperationElapsed.WithLabelValues(p.operation).Set(time.Since(p.start).Seconds())
+}
+
+func (p *operationProgress) add(n int) {
+    p.mtx.Lock()
+    p.bytes += uint64(n)
+    p.mtx.Unlock()
+    snapshotTransferredBytes.WithLabelValues(p.operation).Add(float64(n))
+}
+
+// finish ends the operation with its outcome.
+func (p *operationProgress) finish(err error) {
+    p.mtx.Lock()
+    defer p.mtx.Unlock()
+    p.end = time.Now()
+    p.err = err
+    if errors.Is(err, ErrExecutorTimeout) {
+        p.status = statusTimedOut
+    }
+    executorOperationInProgress.WithLabelValues(p.operation).Set(0)
+    executorOperationElapsed.WithLabelValues(p.operation).Set(p.end.Sub(p.start).Seconds())
+}
+
+// proto returns the progress reported by the status API, nil if no operation started.
+func (p *operationProgress) proto() *jraftpb.ExecutorOperation {
+    p.mtx.Lock()
+    defer p.mtx.Unlock()
+    if !p.started {
+        return nil
+    }
+    operation := &jraftpb.ExecutorOperation{
+        Id:         p.id,
+        Status:     p.status,
+        InProgress: p.end.IsZero(),
+        Bytes:      p.bytes,
+    }
+    if operation.InProgress {
+        operation.Elapsed = durationpb.New(time.Since(p.start))
+    } else {
+        operation.Elapsed = durationpb.New(p.end.Sub(p.start))
+    }
+    if p.err != nil {
+        operation.Error = p.err.Error()
+    }
+    return operation
+}
+
+// writer counts the bytes written to w as transferred.
+func (p *operationProgress) writer(w io.Writer) io.Writer {
+    return progressWriter{w, p}
+}
+
+// reader counts the bytes read from r as transferred.
+func (p *operationProgress) reader(r io.Reader) io.Reader {
+    return progressReader{r, p}
+}
+
+type progressWriter struct {
+    w        io.Writer
+    progress *operationProgress
+}
+
+func (w progressWriter) Write(data []byte) (int, error) {
+    n, err := w.w.Write(data)
+    w.progress.add(n)
+    return n, err
+}
+
+type progressReader struct {
+    r        io.Reader
+    progress *operationProgress
+}
+
+func (r progressReader) Read(data []byte) (int, error) {
+    n, err := r.r.Read(data)
+    r.progress.add(n)
+    return n, err
+}
+
+// poll calls check until it reports that the operation of the Executor is finished, or fails with
+// ErrExecutorTimeout after timeout. The first call waits for the poll interval, which doubles after each call up to
+// the max poll interval. Errors reaching the Executor are logged and polled again, the error of a finished operation
+// is returned.
+func (executor *executor) poll(operation string, timeout time.Duration, check func(ctx context.Context) (bool, error)) error {
+    ctx, cancel := context.WithTimeout(context.Background(), timeout)
+    defer cancel()
+    interval := executor.policy.pollInterval
+    for {
+        select {
+        case <-time.After(interval):
+        case <-ctx.Done():
+            executor.Logger.Error("Timed out waiting for the Executor", "operation", operation, "timeout", timeout)
+            return fmt.Errorf("%w to finish the %s after %v", ErrExecutorTimeout, operation, timeout)
+        }
+        done, err := check(ctx)
+        if done {
+            return err
+        }
+        if err != nil {
+            executor.Logger.Error("Error fetching the status of the Executor", "operation", operation, "error", err)
+        }
+        if interval *= 2; interval > executor.policy.pollMaxInterval {
+            interval = executor.policy.pollMaxInterval
+        }
+    }
+}
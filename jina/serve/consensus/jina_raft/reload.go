@@ -0,0 +1,83 @@
+package server
+
+import (
+    "fmt"
+    "strings"
+    "time"
+
+    "github.com/hashicorp/raft"
+    hclog "github.com/hashicorp/go-hclog"
+)
+
+// ReloadableOptions are the Options that can be changed while the Node is running.
+type ReloadableOptions struct {
+    TrailingLogs      uint64
+    SnapshotInterval  time.Duration
+    SnapshotThreshold uint64
+    HeartbeatTimeout  time.Duration
+    ElectionTimeout   time.Duration
+    LogLevel          string
+}
+
+// Reloadable returns the reloadable subset of the Options.
+func (opts Options) Reloadable() ReloadableOptions {
+    return ReloadableOptions{
+        TrailingLogs:      opts.TrailingLogs,
+        SnapshotInterval:  opts.SnapshotInterval,
+        SnapshotThreshold: opts.SnapshotThreshold,
+        HeartbeatTimeout:  opts.HeartbeatTimeout,
+        ElectionTimeout:   opts.ElectionTimeout,
+        LogLevel:          opts.LogLevel,
+    }
+}
+
+// ReloadableOptions returns the reloadable settings currently in effect.
+func (n *Node) ReloadableOptions() ReloadableOptions {
+    rc := n.raft.ReloadableConfig()
+    n.mtx.RLock()
+    defer n.mtx.RUnlock()
+    return ReloadableOptions{
+        TrailingLogs:      rc.TrailingLogs,
+        SnapshotInterval:  rc.SnapshotInterval,
+        SnapshotThreshold: rc.SnapshotThreshold,
+        HeartbeatTimeout:  rc.HeartbeatTimeout,
+        ElectionTimeout:   rc.ElectionTimeout,
+        LogLevel:          n.opts.LogLevel,
+    }
+}
+
+// Reload applies ro to the running Raft instance through raft.ReloadConfig and changes the level of
+// the RAFT, RPC and FSM loggers. Nothing is changed if ro is invalid. The effective settings are returned.
+func (n *Node) Reload(ro ReloadableOptions) (ReloadableOptions, error) {
+    level := hclog.LevelFromString(ro.LogLevel)
+    if level == hclog.NoLevel {
+        return n.ReloadableOptions(), fmt.Errorf("invalid log level %q", ro.LogLevel)
+    }
+    n.mtx.Lock()
+    err := n.raft.ReloadConfig(raft.ReloadableConfig{
+        TrailingLogs:      ro.TrailingLogs,
+        SnapshotInterval:  ro.SnapshotInterval,
+        SnapshotThreshold: ro.SnapshotThreshold,
+        HeartbeatTimeout:  ro.HeartbeatTimeout,
+        ElectionTimeout:   ro.ElectionTimeout,
+    })
+    if err == nil {
+        n.opts.TrailingLogs      = ro.TrailingLogs
+        n.opts.SnapshotInterval  = ro.SnapshotInterval
+        n.opts.SnapshotThreshold = ro.SnapshotThreshold
+        n.opts.HeartbeatTimeout  = ro.HeartbeatTimeout
+        n.opts.ElectionTimeout   = ro.ElectionTimeout
+        n.opts.LogLevel          = strings.ToUpper(ro.LogLevel)
+        n.logger.SetLevel(level)
+        n.rpc.Logger.SetLevel(level)
+        n.fsm.logger.SetLevel(level)
+    }
+    n.mtx.Unlock()
+    if err != nil {
+        n.logger.Error("Error reloading the RAFT configuration", "error", err)
+        return n.ReloadableOptions(), err
+    }
+    effective := n.ReloadableOptions()
+    n.logger.Info("RAFT configuration reloaded", "configuration", fmt.Sprintf("%+v", effective))
+    return effective, nil
+}
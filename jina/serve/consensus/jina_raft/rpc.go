@@ -4,23 +4,26 @@ import (
     "context"
     "time"
     "sync/atomic"
-    "errors"
     "io"
 
-    "github.com/Jille/raft-grpc-leader-rpc/rafterrors"
     "github.com/golang/protobuf/proto"
     empty "github.com/golang/protobuf/ptypes/empty"
 
     "github.com/hashicorp/raft"
     pb "jraft/jina-go-proto"
-    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+    jraftpb "jraft/jraft-go-proto"
     hclog "github.com/hashicorp/go-hclog"
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/trace"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
 )
 
 type RpcInterface struct {
     Executor *executorFSM
     Raft     *raft.Raft
     Logger   hclog.Logger
+    writes   writeGate
     pb.UnimplementedJinaSingleDataRequestRPCServer
     pb.UnimplementedJinaDiscoverEndpointsRPCServer
     pb.UnimplementedJinaInfoRPCServer
@@ -35,7 +38,7 @@ func (rpc *RpcInterface) getRaftState() raft.RaftState {
 
 
 func (rpc *RpcInterface) Call(stream pb.JinaRPC_CallServer) error {
-  ctx := context.Background()
+  ctx := stream.Context()
   for {
     req, err := stream.Recv()
     if err == io.EOF {
@@ -68,75 +71,117 @@ func (rpc *RpcInterface) Call(stream pb.JinaRPC_CallServer) error {
  */
 func (rpc *RpcInterface) ProcessSingleData(
     ctx context.Context,
-    dataRequestProto *pb.DataRequestProto) (*pb.DataRequestProto, error) {
+    dataRequestProto *pb.DataRequestProto) (response *pb.DataRequestProto, err error) {
     rpc.Logger.Debug("Calling ProcessSingleData")
-    endpoint := dataRequestProto.Header.ExecEndpoint
-    found := false
-
-    // Loop through the list and check if the search string is in the list
-    for _, s := range rpc.Executor.write_endpoints {
-        if s == *endpoint {
-            found = true
-            break
-        }
-    }
-
-    if found {
-        rpc.Logger.Debug("Calling a Write Endpoint:", "endpoint", *endpoint)
-        if rpc.getRaftState() == raft.Leader && rpc.Executor.isSnapshotInProgress() {
-            err := errors.New("Leader cannot process write request while Snapshotting")
-            rpc.Logger.Error("Leader cannot process write request while Snapshotting")
-            return nil, err
+    // a request without header or endpoint is sent to the Executor as a read, which answers with its error
+    endpoint := dataRequestProto.GetHeader().GetExecEndpoint()
+    ctx, span := rpc.Executor.tracer().Start(extractIncoming(ctx), "jina_raft.ProcessSingleData", trace.WithSpanKind(trace.SpanKindServer), trace.WithAttributes(
+        attribute.String("jina.endpoint", endpoint),
+        attribute.String("jina.request_id", dataRequestProto.GetHeader().GetRequestId()),
+    ))
+    defer func() { endSpan(span, err) }()
+    if rpc.Executor.isWriteEndpoint(endpoint) {
+        rpc.Logger.Debug("Calling a Write Endpoint:", "endpoint", endpoint)
+        requestsTotal.WithLabelValues(rpc.Executor.endpointLabel(endpoint), "write").Inc()
+        if !rpc.writes.enter() {
+            rpc.Logger.Error("Cannot process write request while decommissioning")
+            writesTotal.WithLabelValues(writeDraining).Inc()
+            return nil, rpc.writeError(ErrDraining)
         }
+        // the write stays in flight until Raft is done with it, even once the client gave up
+        inFlight := true
+        defer func() {
+            if inFlight {
+                rpc.writes.exit()
+            }
+        }()
+        span.SetAttributes(attribute.String("jina.request_kind", "write"))
+        _, enqueueSpan := rpc.Executor.tracer().Start(ctx, "raft.enqueue")
         bytes, err := proto.Marshal(dataRequestProto)
         if err != nil {
             rpc.Logger.Error("Error marshalling DataRequestProto into bytes:", "error", err)
+            endSpan(enqueueSpan, err)
+            return nil, err
+        }
+        entry := newEntry(jraftpb.CommandType_COMMAND_DATA_REQUEST, bytes)
+        entry.RequestId = dataRequestProto.GetHeader().GetRequestId()
+        // the trace context travels with the entry, so that every replica traces its apply under this request
+        entry.TraceContext = traceContext(ctx)
+        data, err := encodeEntry(entry)
+        if err != nil {
+            rpc.Logger.Error("Error encoding log entry:", "error", err)
+            endSpan(enqueueSpan, err)
             return nil, err
         }
         // replicate logs to the followers and then to itself
         rpc.Logger.Debug("Call raft.Apply")
         // here we should read the `on=` from dat
-        future := rpc.Raft.Apply(bytes, time.Second)
-        if err := future.Error(); err != nil {
+        future := rpc.Raft.Apply(data, time.Second)
+        enqueueSpan.End()
+        _, commitSpan := rpc.Executor.tracer().Start(ctx, "raft.commit")
+        committed := make(chan error, 1)
+        go func() { committed <- future.Error() }()
+        select {
+        case err = <-committed:
+        case <-ctx.Done():
+            // the outcome is unknown, the write may still be committed and applied
+            err = ctx.Err()
+            inFlight = false
+            go func() {
+                <-committed
+                rpc.writes.exit()
+            }()
+        }
+        if err == nil {
+            commitSpan.SetAttributes(attribute.Int64("raft.index", int64(future.Index())))
+        }
+        endSpan(commitSpan, err)
+        if err != nil {
             rpc.Logger.Error("Error from calling RAFT apply:", "error", err)
-            return nil, rafterrors.MarkRetriable(err)
+            if err == raft.ErrNotLeader {
+                writesTotal.WithLabelValues(writeNotLeader).Inc()
+            } else {
+                writesTotal.WithLabelValues(writeFailed).Inc()
+            }
+            return nil, rpc.writeError(err)
+        }
+        result, ok := future.Response().(*applyResult)
+        if !ok || result == nil {
+            rpc.Logger.Error("Unexpected response from the FSM", "response", future.Response())
+            writesTotal.WithLabelValues(writeFailed).Inc()
+            return nil, status.Errorf(codes.Internal, "unexpected response from the FSM: %T", future.Response())
         }
-        response, test := future.Response().(*pb.DataRequestProto)
-        if test {
-            return response, nil
+        if result.err != nil {
+            writesTotal.WithLabelValues(writeFailed).Inc()
+            return nil, rpc.writeError(result.err)
+        }
+        // the failures of the Executor are reported in the header status of the response, not as gRPC errors
+        if result.response.GetHeader().GetStatus().GetCode() == pb.StatusProto_ERROR {
+            writesTotal.WithLabelValues(writeExecutorError).Inc()
         } else {
-            err := future.Response().(error)
-            return nil, err
+            writesTotal.WithLabelValues(writeApplied).Inc()
         }
+        return result.response, nil
     } else {
-        rpc.Logger.Debug("Calling a Read Endpoint:", "endpoint", *endpoint)
+        rpc.Logger.Debug("Calling a Read Endpoint:", "endpoint", endpoint)
+        span.SetAttributes(attribute.String("jina.request_kind", "read"))
+        requestsTotal.WithLabelValues(rpc.Executor.endpointLabel(endpoint), "read").Inc()
         return rpc.Executor.Read(ctx, dataRequestProto)
     }
 }
 
 func (rpc *RpcInterface) EndpointDiscovery(ctx context.Context, empty *empty.Empty) (*pb.EndpointsProto, error) {
     rpc.Logger.Debug("Get an Endpoint Discovery Request")
-    return rpc.Executor.EndpointDiscovery(ctx, empty)
+    endpoints, err := rpc.Executor.EndpointDiscovery(ctx, empty)
+    if err != nil {
+        return nil, err
+    }
+    // clients route the requests following the write endpoints every replica applies, not the local Executor
+    endpoints.WriteEndpoints = rpc.Executor.writeEndpoints()
+    return endpoints, nil
 }
 
 func (rpc *RpcInterface) XStatus(ctx context.Context, empty *empty.Empty) (*pb.JinaInfoProto, error) {
    rpc.Logger.Debug("Get an XStatus Request")
    return rpc.Executor.XStatus(ctx, empty)
 }
-
-func (rpc *RpcInterface) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
-    rpc.Logger.Debug("Get a Check Request")
-    return rpc.Executor.Check(ctx, req)
-}
-
-func (rpc *RpcInterface) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
-    rpc.Logger.Debug("Get a Watch Request")
-    healthCheckStatus := &healthpb.HealthCheckResponse{}
-    healthCheckStatus.Status = 1
-    err := stream.Send(healthCheckStatus)
-    if err != nil {
-        rpc.Logger.Error("Error sending back health Check Status", "error", err)
-        return err
-    }
-    return nil
-}
@@ -0,0 +1,135 @@
+package server
+
+import (
+    "context"
+    "io"
+    "testing"
+    "time"
+
+    hclog "github.com/hashicorp/go-hclog"
+    "github.com/hashicorp/raft"
+    "go.opentelemetry.io/otel/trace"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/credentials/insecure"
+    "google.golang.org/grpc/status"
+    pb "jraft/jina-go-proto"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+// blockingFSM holds every Apply until release is closed.
+type blockingFSM struct {
+    release chan struct{}
+}
+
+func (f *blockingFSM) Apply(*raft.Log) interface{} {
+    <-f.release
+    return &applyResult{}
+}
+
+func (f *blockingFSM) Snapshot() (raft.FSMSnapshot, error) {
+    return nil, raft.ErrNothingNewToSnapshot
+}
+
+func (f *blockingFSM) Restore(io.ReadCloser) error {
+    return nil
+}
+
+// newSingleNode starts a single Raft node applying to fsm, and returns it once it leads.
+func newSingleNode(t *testing.T, fsm raft.FSM) *raft.Raft {
+    t.Helper()
+    _, transport := raft.NewInmemTransport("")
+    config := raft.DefaultConfig()
+    config.LocalID = "1"
+    config.HeartbeatTimeout = 50 * time.Millisecond
+    config.ElectionTimeout = 50 * time.Millisecond
+    config.LeaderLeaseTimeout = 50 * time.Millisecond
+    config.Logger = hclog.NewNullLogger()
+    store := raft.NewInmemStore()
+    snapshots := raft.NewInmemSnapshotStore()
+    configuration := raft.Configuration{Servers: []raft.Server{{ID: config.LocalID, Address: transport.LocalAddr()}}}
+    if err := raft.BootstrapCluster(config, store, store, snapshots, transport, configuration); err != nil {
+        t.Fatal(err)
+    }
+    r, err := raft.NewRaft(config, fsm, store, store, snapshots, transport)
+    if err != nil {
+        t.Fatal(err)
+    }
+    t.Cleanup(func() { r.Shutdown().Error() })
+    select {
+    case <-r.LeaderCh():
+    case <-time.After(5 * time.Second):
+        t.Fatal("no leader elected")
+    }
+    return r
+}
+
+func writeRequest(endpoint string) *pb.DataRequestProto {
+    return &pb.DataRequestProto{Header: &pb.HeaderProto{RequestId: "r1", ExecEndpoint: &endpoint}}
+}
+
+func TestProcessSingleDataReturnsWhenTheClientGivesUp(t *testing.T) {
+    fsm := &blockingFSM{release: make(chan struct{})}
+    executor := DummyExecutorFSM()
+    executor.write_endpoints = []string{"/index"}
+    rpc := &RpcInterface{Executor: executor, Raft: newSingleNode(t, fsm), Logger: hclog.NewNullLogger()}
+
+    ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+    defer cancel()
+    start := time.Now()
+    _, err := rpc.ProcessSingleData(ctx, writeRequest("/index"))
+    if status.Code(err) != codes.DeadlineExceeded {
+        t.Fatalf("ProcessSingleData() = %v, want %v", err, codes.DeadlineExceeded)
+    }
+    if elapsed := time.Since(start); elapsed > time.Second {
+        t.Fatalf("ProcessSingleData() returned after %v, want right after the deadline", elapsed)
+    }
+
+    // the abandoned write is in flight until Raft is done with it, a drain waits for it
+    drainCtx, drainCancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+    defer drainCancel()
+    if err := rpc.writes.drain(drainCtx); err == nil {
+        t.Fatal("drain() did not wait for the abandoned write")
+    }
+    rpc.writes.resume()
+    close(fsm.release)
+    if err := rpc.writes.drain(context.Background()); err != nil {
+        t.Fatalf("drain() once the write is applied = %v", err)
+    }
+}
+
+func TestApplyTimesOutOnUnreachableExecutor(t *testing.T) {
+    policy := defaultExecutorPolicy()
+    policy.applyTimeout = 100 * time.Millisecond
+    fsm := DummyExecutorFSM()
+    // nothing listens on the port, the write waits for the Executor until applyTimeout
+    fsm.executor = newExecutor("127.0.0.1:1", "test", policy, hclog.NewNullLogger(), []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())})
+    defer fsm.executor.close()
+    entry := newEntry(jraftpb.CommandType_COMMAND_DATA_REQUEST, mustMarshal(t, writeRequest("/index")))
+
+    start := time.Now()
+    result := fsm.applyDataRequest(context.Background(), trace.SpanFromContext(context.Background()), entry)
+    if !result.unapplied {
+        t.Fatal("applyDataRequest() applied the write to an unreachable Executor")
+    }
+    if elapsed := time.Since(start); elapsed > 5*time.Second {
+        t.Fatalf("applyDataRequest() returned after %v, want about %v", elapsed, policy.applyTimeout)
+    }
+}
+
+func TestReadSnapshotTimesOutOnUnreachableExecutor(t *testing.T) {
+    policy := defaultExecutorPolicy()
+    policy.snapshotTimeout = 100 * time.Millisecond
+    // nothing listens on the port, the stream waits for the Executor until snapshotTimeout
+    executor := newExecutor("127.0.0.1:1", "test", policy, hclog.NewNullLogger(), []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())})
+    defer executor.close()
+    s := &snapshot{executor: executor, id: &pb.SnapshotId{Value: "1"}, Logger: hclog.NewNullLogger()}
+
+    start := time.Now()
+    if err := s.readSnapshot(io.Discard); status.Code(err) != codes.DeadlineExceeded {
+        t.Fatalf("readSnapshot() = %v, want %v", err, codes.DeadlineExceeded)
+    }
+    if elapsed := time.Since(start); elapsed > 5*time.Second {
+        t.Fatalf("readSnapshot() returned after %v, want about %v", elapsed, policy.snapshotTimeout)
+    }
+}
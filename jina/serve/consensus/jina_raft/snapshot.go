@@ -1,15 +1,25 @@
 package server
 
 import (
+    "bufio"
+    "bytes"
     "context"
+    "crypto/sha256"
+    "encoding/binary"
     "fmt"
+    "hash/crc32"
     "sync"
     "time"
     "os"
     "io"
 
     "github.com/hashicorp/raft"
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/codes"
+    "google.golang.org/grpc/status"
+    "google.golang.org/protobuf/proto"
     pb "jraft/jina-go-proto"
+    jraftpb "jraft/jraft-go-proto"
     hclog "github.com/hashicorp/go-hclog"
 )
 
@@ -19,6 +29,12 @@ type snapshot struct {
     mu                sync.RWMutex
     status            *pb.SnapshotStatusProto_Status
     snapshotFile      string
+    // header is the state of the Node written in front of the Executor snapshot
+    header            *jraftpb.SnapshotHeader
+    // progress reports the snapshot of the Executor until it is persisted
+    progress          *operationProgress
+    // keys wrap the data key encrypting the snapshot, nil if it is not encrypted
+    keys              SnapshotKeyProvider
     Logger            hclog.Logger
 }
 
@@ -39,67 +55,85 @@ func (s *snapshot) get() *pb.SnapshotStatusProto_Status {
     return s.status
 }
 
-func (s *snapshot) Persist(sink raft.SnapshotSink) error {
+func (s *snapshot) Persist(sink raft.SnapshotSink) (err error) {
     s.Logger.Debug("Start persist operation")
-    var err error
-    ticker := time.NewTicker(1 * time.Second)
-    done := make(chan bool)
-    defer close(done)
-    timeout := time.NewTimer(500 * time.Second)
-    var status *pb.SnapshotStatusProto_Status
+    start := time.Now()
 
     defer func() {
-        if err != nil || (status != nil && *status != pb.SnapshotStatusProto_SUCCEEDED) {
+        if err != nil {
             failed_snapshot := pb.SnapshotStatusProto_FAILED
             s.store(&failed_snapshot)
             sink.Cancel()
         } else {
             err = sink.Close()
         }
+        s.progress.finish(err)
+        observeSince(snapshotSeconds, start, err)
     }()
 
-    go func(funcTicker *time.Ticker) {
-        for {
-            select {
-            case t := <-funcTicker.C:
-                s.Logger.Debug("Checking snapshot status at", "time", t)
-                conn, err := s.executor.newConnection()
-                if err == nil {
-                    defer conn.Close()
-                    client := pb.NewJinaExecutorSnapshotProgressClient(conn)
-                    response, err := client.SnapshotStatus(context.Background(), s.id)
-                    if err != nil {
-                        s.Logger.Error("Error fetching snapshot status for", "ID", response.Id, "error", err)
-                    } else {
-                        s.store(&response.Status)
-                        s.Logger.Debug("Snapshot", "status", response.Status, "at time", t)
-                        if response.Status == pb.SnapshotStatusProto_FAILED ||
-                            response.Status == pb.SnapshotStatusProto_SUCCEEDED {
-                            timeout.Stop()
-                            done <- true
-                            return
-                        }
-                    }
-                }
-            case <-timeout.C:
-                s.Logger.Error("Timed out waiting for snapshot status.")
-                timeout.Stop()
-                done <- true
-                return
-            }
+    err = s.executor.poll(operationSnapshot, s.executor.policy.snapshotTimeout, func(ctx context.Context) (bool, error) {
+        var response *pb.SnapshotStatusProto
+        err := s.executor.invoke(ctx, "SnapshotStatus", func(ctx context.Context, conn *grpc.ClientConn) (err error) {
+            response, err = pb.NewJinaExecutorSnapshotProgressClient(conn).SnapshotStatus(ctx, s.id)
+            return err
+        })
+        if err != nil {
+            return false, err
         }
-    }(ticker)
-
-    <-done
-    ticker.Stop()
+        s.store(&response.Status)
+        s.progress.update(s.id.GetValue(), response.Status.String())
+        s.Logger.Debug("Snapshot", "ID", s.id.GetValue(), "status", response.Status)
+        switch response.Status {
+        case pb.SnapshotStatusProto_SUCCEEDED:
+            return true, nil
+        case pb.SnapshotStatusProto_FAILED, pb.SnapshotStatusProto_NOT_FOUND:
+            return true, fmt.Errorf("persist job %s failed with status %s", s.id.GetValue(), response.Status)
+        }
+        return false, nil
+    })
+    if err != nil {
+        s.Logger.Error("Snapshot of the Executor failed", "ID", s.id.GetValue(), "error", err)
+        return err
+    }
+    dataKey, err := s.encrypt()
+    if err != nil {
+        s.Logger.Error("Error generating the key of the snapshot", "error", err)
+        return err
+    }
+    header, err := encodeSnapshotHeader(s.header)
+    if err != nil {
+       s.Logger.Error("Error encoding the snapshot header", "error", err)
+       return err
+    }
+    if _, err = sink.Write(header); err != nil {
+       s.Logger.Error("Error writing the snapshot header", "error", err)
+       return err
+    }
+    encoder, err := newSnapshotEncoder(sink, s.header.Codec, dataKey, header)
+    if err != nil {
+        s.Logger.Error("Error compressing the snapshot", "codec", s.header.Codec, "error", err)
+        return err
+    }
+    if err = s.copySnapshot(s.progress.writer(encoder)); err != nil {
+        return err
+    }
+    if err = encoder.Close(); err != nil {
+        s.Logger.Error("Error writing the snapshot manifest", "error", err)
+    }
+    return err
+}
 
-    status = s.get()
-    if status != nil && *status != pb.SnapshotStatusProto_SUCCEEDED {
-        msg := fmt.Sprintf("persist job %s failed with status %s", s.id.Value, status)
-        s.Logger.Error(msg)
-        err = fmt.Errorf(msg)
+// copySnapshot writes the Executor snapshot to w. The snapshot is streamed from the Executor, so that it does not
+// need to share a file system with the Node; Executors that cannot stream snapshots are read from the snapshot file.
+func (s *snapshot) copySnapshot(w io.Writer) error {
+    err := s.readSnapshot(w)
+    if status.Code(err) != codes.Unimplemented {
+        if err != nil {
+            s.Logger.Error("Error streaming the Executor snapshot", "ID", s.id.GetValue(), "error", err)
+        }
         return err
     }
+    s.Logger.Debug("The Executor cannot stream snapshots, reading the snapshot file", "file", s.snapshotFile)
     source, err := os.Open(s.snapshotFile)
     if err != nil {
        s.Logger.Error("Error opening a file where Executor created snapshot", "error", err)
@@ -107,7 +141,7 @@ func (s *snapshot) Persist(sink raft.SnapshotSink) error {
     }
     defer source.Close()
 
-    _, err = io.Copy(sink, source)
+    _, err = io.Copy(w, source)
     if err != nil {
        s.Logger.Error("Error copying temporary Executor snapshot", "error", err)
        return err
@@ -118,3 +152,103 @@ func (s *snapshot) Persist(sink raft.SnapshotSink) error {
     }
     return err
 }
+
+// readSnapshot streams the Executor snapshot into w, checking the checksum of every chunk and the digest of the
+// whole snapshot sent with the last chunk. Nothing is written when the Executor does not implement the stream. The
+// stream fails if it does not end within snapshotTimeout, so that a stuck Executor does not hold the snapshot.
+func (s *snapshot) readSnapshot(w io.Writer) error {
+    conn, err := s.executor.connection()
+    if err != nil {
+        return err
+    }
+    ctx, cancel := context.WithTimeout(context.Background(), s.executor.policy.snapshotTimeout)
+    defer cancel()
+    stream, err := pb.NewJinaExecutorSnapshotReadClient(conn).ReadSnapshot(ctx, s.id, grpc.WaitForReady(true))
+    if err != nil {
+        return err
+    }
+    digest := sha256.New()
+    var offset uint64
+    for {
+        chunk, err := stream.Recv()
+        if err == io.EOF {
+            return fmt.Errorf("snapshot %s ended after %d bytes without its digest", s.id.GetValue(), offset)
+        }
+        if err != nil {
+            return err
+        }
+        if chunk.Offset != offset {
+            return fmt.Errorf("snapshot %s chunk at offset %d, expected offset %d", s.id.GetValue(), chunk.Offset, offset)
+        }
+        if len(chunk.Sha256) > 0 {
+            if !bytes.Equal(chunk.Sha256, digest.Sum(nil)) {
+                return fmt.Errorf("snapshot %s digest mismatch after %d bytes", s.id.GetValue(), offset)
+            }
+            s.Logger.Debug("Streamed the Executor snapshot", "ID", s.id.GetValue(), "bytes", offset)
+            return nil
+        }
+        if crc32.ChecksumIEEE(chunk.Data) != chunk.Crc32 {
+            return fmt.Errorf("snapshot %s checksum mismatch in the chunk at offset %d", s.id.GetValue(), offset)
+        }
+        if _, err := w.Write(chunk.Data); err != nil {
+            return err
+        }
+        digest.Write(chunk.Data)
+        offset += uint64(len(chunk.Data))
+    }
+}
+
+// snapshotMagic starts the snapshots holding a SnapshotHeader, followed by the length of the header as a uvarint,
+// the header and the Executor snapshot. Snapshots taken by earlier versions only hold the Executor snapshot.
+var snapshotMagic = []byte("JRAFTSNP")
+
+// snapshotHeaderVersion is the latest version of the SnapshotHeader read by this node, see snapshotManifestVersion
+// and snapshotEncryptionVersion.
+const snapshotHeaderVersion uint32 = 3
+
+func encodeSnapshotHeader(header *jraftpb.SnapshotHeader) ([]byte, error) {
+    data, err := proto.Marshal(header)
+    if err != nil {
+        return nil, err
+    }
+    encoded := append([]byte{}, snapshotMagic...)
+    encoded = binary.AppendUvarint(encoded, uint64(len(data)))
+    return append(encoded, data...), nil
+}
+
+// maxSnapshotHeaderSize bounds the size of a snapshot header, so that a corrupted length is not allocated.
+const maxSnapshotHeaderSize = 1 << 20
+
+// readSnapshotHeader reads the header in front of a snapshot, and returns it and its encoding with the reader of the
+// Executor snapshot that follows. The header of the snapshots taken by earlier versions is nil.
+func readSnapshotHeader(r io.Reader) (*jraftpb.SnapshotHeader, []byte, io.Reader, error) {
+    buffered := bufio.NewReader(r)
+    magic, err := buffered.Peek(len(snapshotMagic))
+    if err != nil && err != io.EOF {
+        return nil, nil, nil, err
+    }
+    if !bytes.Equal(magic, snapshotMagic) {
+        return nil, nil, buffered, nil
+    }
+    buffered.Discard(len(snapshotMagic))
+    length, err := binary.ReadUvarint(buffered)
+    if err != nil {
+        return nil, nil, nil, fmt.Errorf("truncated snapshot header")
+    }
+    if length > maxSnapshotHeaderSize {
+        return nil, nil, nil, fmt.Errorf("snapshot header of %d bytes exceeds %d bytes", length, maxSnapshotHeaderSize)
+    }
+    encoded := binary.AppendUvarint(append([]byte{}, snapshotMagic...), length)
+    data := make([]byte, length)
+    if _, err := io.ReadFull(buffered, data); err != nil {
+        return nil, nil, nil, fmt.Errorf("truncated snapshot header")
+    }
+    header := &jraftpb.SnapshotHeader{}
+    if err := proto.Unmarshal(data, header); err != nil {
+        return nil, nil, nil, err
+    }
+    if header.Version > snapshotHeaderVersion {
+        return nil, nil, nil, fmt.Errorf("snapshot header version %d is not supported, this node supports up to version %d", header.Version, snapshotHeaderVersion)
+    }
+    return header, append(encoded, data...), buffered, nil
+}
@@ -0,0 +1,370 @@
+package server
+
+import (
+    "bufio"
+    "bytes"
+    "compress/gzip"
+    "crypto/cipher"
+    "crypto/sha256"
+    "encoding/binary"
+    "errors"
+    "fmt"
+    "hash"
+    "io"
+    "strings"
+
+    "github.com/klauspost/compress/zstd"
+    "google.golang.org/protobuf/proto"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+// From snapshotManifestVersion, the Executor snapshot is compressed with the codec of the SnapshotHeader and split
+// in frames, each one a uvarint length followed by as many bytes. An empty frame ends the Executor snapshot, and is
+// followed by the length of the SnapshotManifest as a uvarint and the manifest.
+const snapshotManifestVersion uint32 = 2
+
+// From snapshotEncryptionVersion, the SnapshotHeader may hold the data key of the snapshot. Every frame and the
+// manifest are then encrypted with AES-256-GCM under the data key, with their position in the snapshot as nonce, so
+// that frames cannot be reordered, dropped or passed for the manifest. The encoded header is authenticated with
+// every frame, so that it cannot be modified either.
+const snapshotEncryptionVersion uint32 = 3
+
+// snapshotFrameSize is the maximum size of the data of a frame.
+const snapshotFrameSize = 1 << 20
+
+// manifestAdditionalData authenticates the encrypted manifest as such.
+var manifestAdditionalData = []byte("manifest")
+
+// ErrSnapshotCorrupted is returned when a snapshot does not match its manifest, or is truncated.
+var ErrSnapshotCorrupted = errors.New("snapshot is corrupted")
+
+// parseSnapshotCodec returns the codec called name: none, gzip or zstd.
+func parseSnapshotCodec(name string) (jraftpb.SnapshotCodec, error) {
+    switch strings.ToLower(name) {
+    case "", "none":
+        return jraftpb.SnapshotCodec_CODEC_NONE, nil
+    case "gzip":
+        return jraftpb.SnapshotCodec_CODEC_GZIP, nil
+    case "zstd":
+        return jraftpb.SnapshotCodec_CODEC_ZSTD, nil
+    }
+    return jraftpb.SnapshotCodec_CODEC_NONE, fmt.Errorf("unknown snapshot compression %q, expected none, gzip or zstd", name)
+}
+
+// snapshotEncoder compresses the Executor snapshot written to it into frames, and writes its manifest when closed.
+type snapshotEncoder struct {
+    w          *frameWriter
+    codec      jraftpb.SnapshotCodec
+    frames     *bufio.Writer
+    compressor io.WriteCloser
+    digest     hash.Hash
+    size       uint64
+}
+
+// newSnapshotEncoder returns the encoder writing to w, which encrypts the frames with dataKey unless it is nil,
+// authenticating header, the encoded SnapshotHeader written before.
+func newSnapshotEncoder(w io.Writer, codec jraftpb.SnapshotCodec, dataKey []byte, header []byte) (*snapshotEncoder, error) {
+    frameWriter := &frameWriter{w: w}
+    if dataKey != nil {
+        aead, err := newSnapshotAEAD(dataKey)
+        if err != nil {
+            return nil, err
+        }
+        frameWriter.sealer = &frameSealer{aead: aead, header: header}
+    }
+    frames := bufio.NewWriterSize(frameWriter, snapshotFrameSize)
+    var compressor io.WriteCloser
+    switch codec {
+    case jraftpb.SnapshotCodec_CODEC_NONE:
+        compressor = nopWriteCloser{frames}
+    case jraftpb.SnapshotCodec_CODEC_GZIP:
+        compressor = gzip.NewWriter(frames)
+    case jraftpb.SnapshotCodec_CODEC_ZSTD:
+        encoder, err := zstd.NewWriter(frames)
+        if err != nil {
+            return nil, err
+        }
+        compressor = encoder
+    default:
+        return nil, fmt.Errorf("unknown snapshot codec %v", codec)
+    }
+    return &snapshotEncoder{
+        w:          frameWriter,
+        codec:      codec,
+        frames:     frames,
+        compressor: compressor,
+        digest:     sha256.New(),
+    }, nil
+}
+
+func (e *snapshotEncoder) Write(data []byte) (int, error) {
+    n, err := e.compressor.Write(data)
+    e.digest.Write(data[:n])
+    e.size += uint64(n)
+    return n, err
+}
+
+// Close flushes the compressed Executor snapshot, and writes the end of the frames and the manifest.
+func (e *snapshotEncoder) Close() error {
+    if err := e.compressor.Close(); err != nil {
+        return err
+    }
+    if err := e.frames.Flush(); err != nil {
+        return err
+    }
+    manifest, err := proto.Marshal(&jraftpb.SnapshotManifest{
+        Codec:  e.codec,
+        Size:   e.size,
+        Sha256: e.digest.Sum(nil),
+    })
+    if err != nil {
+        return err
+    }
+    if e.w.sealer != nil {
+        manifest = e.w.sealer.seal(manifest, manifestAdditionalData)
+    }
+    trailer := binary.AppendUvarint([]byte{0}, uint64(len(manifest)))
+    _, err = e.w.w.Write(append(trailer, manifest...))
+    return err
+}
+
+// frameWriter writes the data written to it as frames of up to snapshotFrameSize bytes, encrypted by sealer if set.
+type frameWriter struct {
+    w      io.Writer
+    sealer *frameSealer
+}
+
+func (w *frameWriter) Write(data []byte) (int, error) {
+    written := 0
+    for len(data) > written {
+        frame := data[written:]
+        if len(frame) > snapshotFrameSize {
+            frame = frame[:snapshotFrameSize]
+        }
+        encoded := frame
+        if w.sealer != nil {
+            encoded = w.sealer.seal(frame, nil)
+        }
+        if _, err := w.w.Write(binary.AppendUvarint(nil, uint64(len(encoded)))); err != nil {
+            return written, err
+        }
+        if _, err := w.w.Write(encoded); err != nil {
+            return written, err
+        }
+        written += len(frame)
+    }
+    return written, nil
+}
+
+// frameSealer encrypts and decrypts the frames of a snapshot in order, the nonce of each one being its position.
+type frameSealer struct {
+    aead    cipher.AEAD
+    // header is the encoded SnapshotHeader, authenticated with every frame and the manifest
+    header  []byte
+    counter uint64
+}
+
+func (s *frameSealer) nonce() []byte {
+    nonce := make([]byte, s.aead.NonceSize())
+    binary.BigEndian.PutUint64(nonce[len(nonce)-8:], s.counter)
+    s.counter++
+    return nonce
+}
+
+// additionalData returns the header followed by additionalData, without writing to the array of the header.
+func (s *frameSealer) additionalData(additionalData []byte) []byte {
+    return append(s.header[:len(s.header):len(s.header)], additionalData...)
+}
+
+func (s *frameSealer) seal(data []byte, additionalData []byte) []byte {
+    return s.aead.Seal(nil, s.nonce(), data, s.additionalData(additionalData))
+}
+
+func (s *frameSealer) open(data []byte, additionalData []byte) ([]byte, error) {
+    return s.aead.Open(data[:0], s.nonce(), data, s.additionalData(additionalData))
+}
+
+type nopWriteCloser struct {
+    io.Writer
+}
+
+func (nopWriteCloser) Close() error {
+    return nil
+}
+
+// snapshotDecoder reads the Executor snapshot written by a snapshotEncoder. Once the Executor snapshot is read, the
+// manifest is checked and any mismatch is returned instead of io.EOF, so that the snapshot is never fully read by
+// the Executor.
+type snapshotDecoder struct {
+    r            *bufio.Reader
+    codec        jraftpb.SnapshotCodec
+    frames       *frameReader
+    decompressed io.Reader
+    closer       func()
+    digest       hash.Hash
+    size         uint64
+    err          error
+}
+
+// newSnapshotDecoder returns the decoder reading from r, which decrypts the frames with dataKey unless it is nil,
+// authenticating header, the encoded SnapshotHeader read before.
+func newSnapshotDecoder(r io.Reader, codec jraftpb.SnapshotCodec, dataKey []byte, header []byte) (*snapshotDecoder, error) {
+    buffered, ok := r.(*bufio.Reader)
+    if !ok {
+        buffered = bufio.NewReader(r)
+    }
+    d := &snapshotDecoder{
+        r:      buffered,
+        codec:  codec,
+        frames: &frameReader{r: buffered},
+        closer: func() {},
+        digest: sha256.New(),
+    }
+    if dataKey != nil {
+        aead, err := newSnapshotAEAD(dataKey)
+        if err != nil {
+            return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+        }
+        d.frames.sealer = &frameSealer{aead: aead, header: header}
+    }
+    switch codec {
+    case jraftpb.SnapshotCodec_CODEC_NONE:
+        d.decompressed = d.frames
+    case jraftpb.SnapshotCodec_CODEC_GZIP:
+        decompressor, err := gzip.NewReader(d.frames)
+        if err != nil {
+            return nil, fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+        }
+        d.decompressed = decompressor
+    case jraftpb.SnapshotCodec_CODEC_ZSTD:
+        decompressor, err := zstd.NewReader(d.frames, zstd.WithDecoderConcurrency(1))
+        if err != nil {
+            return nil, err
+        }
+        d.decompressed, d.closer = decompressor, decompressor.Close
+    default:
+        return nil, fmt.Errorf("unknown snapshot codec %v", codec)
+    }
+    return d, nil
+}
+
+func (d *snapshotDecoder) Read(data []byte) (int, error) {
+    if d.err != nil {
+        return 0, d.err
+    }
+    n, err := d.decompressed.Read(data)
+    d.digest.Write(data[:n])
+    d.size += uint64(n)
+    if err == io.EOF {
+        err = d.verify()
+    } else if err != nil {
+        err = fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+    }
+    d.err = err
+    return n, err
+}
+
+// verify reads the manifest following the frames, and returns io.EOF if the Executor snapshot matches it.
+func (d *snapshotDecoder) verify() error {
+    // the decompressor may stop before the empty frame
+    if _, err := io.Copy(io.Discard, d.frames); err != nil {
+        return fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+    }
+    length, err := binary.ReadUvarint(d.r)
+    if err != nil {
+        return fmt.Errorf("%w: truncated manifest", ErrSnapshotCorrupted)
+    }
+    if length > maxSnapshotHeaderSize {
+        return fmt.Errorf("%w: manifest of %d bytes exceeds %d bytes", ErrSnapshotCorrupted, length, maxSnapshotHeaderSize)
+    }
+    data := make([]byte, length)
+    if _, err := io.ReadFull(d.r, data); err != nil {
+        return fmt.Errorf("%w: truncated manifest", ErrSnapshotCorrupted)
+    }
+    if d.frames.sealer != nil {
+        if data, err = d.frames.sealer.open(data, manifestAdditionalData); err != nil {
+            return fmt.Errorf("%w: manifest cannot be decrypted", ErrSnapshotCorrupted)
+        }
+    }
+    manifest := &jraftpb.SnapshotManifest{}
+    if err := proto.Unmarshal(data, manifest); err != nil {
+        return fmt.Errorf("%w: %v", ErrSnapshotCorrupted, err)
+    }
+    if manifest.Codec != d.codec {
+        return fmt.Errorf("%w: compressed with %v, the header says %v", ErrSnapshotCorrupted, manifest.Codec, d.codec)
+    }
+    if manifest.Size != d.size {
+        return fmt.Errorf("%w: %d bytes, expected %d bytes", ErrSnapshotCorrupted, d.size, manifest.Size)
+    }
+    if !bytes.Equal(manifest.Sha256, d.digest.Sum(nil)) {
+        return fmt.Errorf("%w: SHA-256 mismatch", ErrSnapshotCorrupted)
+    }
+    return io.EOF
+}
+
+// Close releases the decompressor.
+func (d *snapshotDecoder) Close() {
+    d.closer()
+}
+
+// frameReader reads the frames until the empty one, decrypting them with sealer if set.
+type frameReader struct {
+    r         *bufio.Reader
+    sealer    *frameSealer
+    remaining uint64
+    // decrypted holds the rest of the last decrypted frame
+    decrypted []byte
+    done      bool
+}
+
+func (f *frameReader) Read(data []byte) (int, error) {
+    if len(f.decrypted) > 0 {
+        n := copy(data, f.decrypted)
+        f.decrypted = f.decrypted[n:]
+        return n, nil
+    }
+    if f.done {
+        return 0, io.EOF
+    }
+    if f.remaining == 0 {
+        length, err := binary.ReadUvarint(f.r)
+        if err != nil {
+            return 0, io.ErrUnexpectedEOF
+        }
+        if length == 0 {
+            f.done = true
+            return 0, io.EOF
+        }
+        if f.sealer != nil {
+            return f.open(data, length)
+        }
+        f.remaining = length
+    }
+    if uint64(len(data)) > f.remaining {
+        data = data[:f.remaining]
+    }
+    n, err := f.r.Read(data)
+    f.remaining -= uint64(n)
+    if err == io.EOF {
+        err = io.ErrUnexpectedEOF
+    }
+    return n, err
+}
+
+// open decrypts the frame of length bytes, and reads it into data.
+func (f *frameReader) open(data []byte, length uint64) (int, error) {
+    if length > snapshotFrameSize+uint64(f.sealer.aead.Overhead()) {
+        return 0, fmt.Errorf("encrypted frame of %d bytes exceeds %d bytes", length, snapshotFrameSize)
+    }
+    frame := make([]byte, length)
+    if _, err := io.ReadFull(f.r, frame); err != nil {
+        return 0, io.ErrUnexpectedEOF
+    }
+    decrypted, err := f.sealer.open(frame, nil)
+    if err != nil {
+        return 0, errors.New("frame cannot be decrypted")
+    }
+    n := copy(data, decrypted)
+    f.decrypted = decrypted[n:]
+    return n, nil
+}
@@ -0,0 +1,370 @@
+package server
+
+import (
+    "bytes"
+    "encoding/binary"
+    "errors"
+    "io"
+    "math/rand"
+    "path/filepath"
+    "testing"
+
+    "google.golang.org/protobuf/proto"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+var testDataKey = bytes.Repeat([]byte{0x42}, snapshotKeySize)
+
+// testHeader stands for the encoded SnapshotHeader in front of the snapshots encoded by the tests.
+var testHeader = []byte("snapshot header")
+
+var testCodecs = []jraftpb.SnapshotCodec{
+    jraftpb.SnapshotCodec_CODEC_NONE,
+    jraftpb.SnapshotCodec_CODEC_GZIP,
+    jraftpb.SnapshotCodec_CODEC_ZSTD,
+}
+
+// randomSnapshot returns size bytes that do not compress, so that the encoded snapshot has as many frames.
+func randomSnapshot(size int) []byte {
+    data := make([]byte, size)
+    rand.New(rand.NewSource(int64(size))).Read(data)
+    return data
+}
+
+func encodeSnapshot(t *testing.T, data []byte, codec jraftpb.SnapshotCodec, dataKey []byte) []byte {
+    t.Helper()
+    return encodeSnapshotWithHeader(t, data, codec, dataKey, testHeader)
+}
+
+func encodeSnapshotWithHeader(t *testing.T, data []byte, codec jraftpb.SnapshotCodec, dataKey []byte, header []byte) []byte {
+    t.Helper()
+    encoded := &bytes.Buffer{}
+    encoder, err := newSnapshotEncoder(encoded, codec, dataKey, header)
+    if err != nil {
+        t.Fatalf("newSnapshotEncoder(%v): %v", codec, err)
+    }
+    if _, err := encoder.Write(data); err != nil {
+        t.Fatalf("Write: %v", err)
+    }
+    if err := encoder.Close(); err != nil {
+        t.Fatalf("Close: %v", err)
+    }
+    return encoded.Bytes()
+}
+
+func decodeSnapshot(encoded []byte, codec jraftpb.SnapshotCodec, dataKey []byte) ([]byte, error) {
+    return decodeSnapshotWithHeader(encoded, codec, dataKey, testHeader)
+}
+
+func decodeSnapshotWithHeader(encoded []byte, codec jraftpb.SnapshotCodec, dataKey []byte, header []byte) ([]byte, error) {
+    decoder, err := newSnapshotDecoder(bytes.NewReader(encoded), codec, dataKey, header)
+    if err != nil {
+        return nil, err
+    }
+    defer decoder.Close()
+    return io.ReadAll(decoder)
+}
+
+// splitFrames returns the frames of an encoded snapshot, without their length, and what follows the empty frame.
+func splitFrames(t *testing.T, encoded []byte) ([][]byte, []byte) {
+    t.Helper()
+    frames := [][]byte{}
+    for {
+        length, n := binary.Uvarint(encoded)
+        if n <= 0 || uint64(len(encoded)-n) < length {
+            t.Fatalf("invalid frame length in the encoded snapshot")
+        }
+        encoded = encoded[n:]
+        if length == 0 {
+            return frames, encoded
+        }
+        frames = append(frames, encoded[:length])
+        encoded = encoded[length:]
+    }
+}
+
+func joinFrames(frames [][]byte, trailer []byte) []byte {
+    joined := []byte{}
+    for _, frame := range frames {
+        joined = binary.AppendUvarint(joined, uint64(len(frame)))
+        joined = append(joined, frame...)
+    }
+    joined = append(joined, 0)
+    return append(joined, trailer...)
+}
+
+func TestParseSnapshotCodec(t *testing.T) {
+    tests := []struct {
+        name    string
+        want    jraftpb.SnapshotCodec
+        wantErr bool
+    }{
+        {name: "", want: jraftpb.SnapshotCodec_CODEC_NONE},
+        {name: "none", want: jraftpb.SnapshotCodec_CODEC_NONE},
+        {name: "gzip", want: jraftpb.SnapshotCodec_CODEC_GZIP},
+        {name: "ZSTD", want: jraftpb.SnapshotCodec_CODEC_ZSTD},
+        {name: "lz4", wantErr: true},
+    }
+    for _, test := range tests {
+        got, err := parseSnapshotCodec(test.name)
+        if (err != nil) != test.wantErr {
+            t.Errorf("parseSnapshotCodec(%q) error = %v, want error %v", test.name, err, test.wantErr)
+            continue
+        }
+        if got != test.want {
+            t.Errorf("parseSnapshotCodec(%q) = %v, want %v", test.name, got, test.want)
+        }
+    }
+}
+
+func TestSnapshotCodecRoundTrip(t *testing.T) {
+    sizes := []int{0, 1, 1000, snapshotFrameSize, 2*snapshotFrameSize + 12345}
+    for _, codec := range testCodecs {
+        for _, dataKey := range [][]byte{nil, testDataKey} {
+            for _, size := range sizes {
+                data := randomSnapshot(size)
+                encoded := encodeSnapshot(t, data, codec, dataKey)
+                decoded, err := decodeSnapshot(encoded, codec, dataKey)
+                if err != nil {
+                    t.Errorf("%v, encrypted %v, %d bytes: decoding: %v", codec, dataKey != nil, size, err)
+                    continue
+                }
+                if !bytes.Equal(decoded, data) {
+                    t.Errorf("%v, encrypted %v, %d bytes: decoded %d bytes that differ from the snapshot", codec, dataKey != nil, size, len(decoded))
+                }
+            }
+        }
+    }
+}
+
+func TestSnapshotCodecEncryptsFrames(t *testing.T) {
+    data := bytes.Repeat([]byte("executor state "), 1000)
+    encoded := encodeSnapshot(t, data, jraftpb.SnapshotCodec_CODEC_NONE, testDataKey)
+    if bytes.Contains(encoded, []byte("executor state")) {
+        t.Fatal("the encrypted snapshot contains the Executor snapshot in clear")
+    }
+}
+
+func TestSnapshotDecoderRejectsCorruption(t *testing.T) {
+    data := randomSnapshot(2*snapshotFrameSize + 12345)
+    otherKey := bytes.Repeat([]byte{0x24}, snapshotKeySize)
+    tests := []struct {
+        name    string
+        dataKey []byte
+        // corrupt returns the snapshot to decode from the encoded frames and trailer
+        corrupt func(frames [][]byte, trailer []byte) []byte
+        // decodeKey is the data key used to decode the snapshot, dataKey if nil
+        decodeKey []byte
+    }{
+        {
+            name: "truncated frame",
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                encoded := joinFrames(frames, trailer)
+                return encoded[:len(encoded)/2]
+            },
+        },
+        {
+            name: "missing end of frames",
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                encoded := joinFrames(frames, nil)
+                return encoded[:len(encoded)-1]
+            },
+        },
+        {
+            name: "missing manifest",
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                return joinFrames(frames, nil)
+            },
+        },
+        {
+            name: "truncated manifest",
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                return joinFrames(frames, trailer[:len(trailer)-1])
+            },
+        },
+        {
+            name: "dropped frame",
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                return joinFrames(frames[1:], trailer)
+            },
+        },
+        {
+            name: "reordered frames",
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                frames[0], frames[1] = frames[1], frames[0]
+                return joinFrames(frames, trailer)
+            },
+        },
+        {
+            name: "tampered frame",
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                frames[1][10] ^= 1
+                return joinFrames(frames, trailer)
+            },
+        },
+        {
+            name: "tampered manifest",
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                trailer[len(trailer)-1] ^= 1
+                return joinFrames(frames, trailer)
+            },
+        },
+        {
+            name:    "encrypted dropped frame",
+            dataKey: testDataKey,
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                return joinFrames(frames[:len(frames)-1], trailer)
+            },
+        },
+        {
+            name:    "encrypted reordered frames",
+            dataKey: testDataKey,
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                frames[0], frames[1] = frames[1], frames[0]
+                return joinFrames(frames, trailer)
+            },
+        },
+        {
+            name:    "encrypted tampered frame",
+            dataKey: testDataKey,
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                frames[1][10] ^= 1
+                return joinFrames(frames, trailer)
+            },
+        },
+        {
+            name:    "encrypted tampered manifest",
+            dataKey: testDataKey,
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                trailer[len(trailer)-1] ^= 1
+                return joinFrames(frames, trailer)
+            },
+        },
+        {
+            name:    "encrypted frame passed for the manifest",
+            dataKey: testDataKey,
+            corrupt: func(frames [][]byte, trailer []byte) []byte {
+                last := frames[len(frames)-1]
+                return joinFrames(frames[:len(frames)-1], append(binary.AppendUvarint(nil, uint64(len(last))), last...))
+            },
+        },
+        {
+            name:      "wrong data key",
+            dataKey:   testDataKey,
+            decodeKey: otherKey,
+            corrupt:   joinFrames,
+        },
+    }
+    for _, codec := range testCodecs {
+        for _, test := range tests {
+            frames, trailer := splitFrames(t, encodeSnapshot(t, data, codec, test.dataKey))
+            decodeKey := test.decodeKey
+            if decodeKey == nil {
+                decodeKey = test.dataKey
+            }
+            decoded, err := decodeSnapshot(test.corrupt(frames, trailer), codec, decodeKey)
+            if !errors.Is(err, ErrSnapshotCorrupted) {
+                t.Errorf("%v, %s: decoded %d bytes with error %v, want %v", codec, test.name, len(decoded), err, ErrSnapshotCorrupted)
+            }
+        }
+    }
+}
+
+func TestSnapshotDecoderRejectsCodecMismatch(t *testing.T) {
+    encoded := encodeSnapshot(t, []byte("executor state"), jraftpb.SnapshotCodec_CODEC_NONE, nil)
+    frames, trailer := splitFrames(t, encoded)
+    // a manifest claiming another codec than the header
+    manifest := encodeSnapshot(t, []byte("executor state"), jraftpb.SnapshotCodec_CODEC_GZIP, nil)
+    _, gzipTrailer := splitFrames(t, manifest)
+    if bytes.Equal(trailer, gzipTrailer) {
+        t.Fatal("the manifests of two codecs are equal")
+    }
+    _, err := decodeSnapshot(joinFrames(frames, gzipTrailer), jraftpb.SnapshotCodec_CODEC_NONE, nil)
+    if !errors.Is(err, ErrSnapshotCorrupted) {
+        t.Fatalf("decoding with the manifest of another codec: %v, want %v", err, ErrSnapshotCorrupted)
+    }
+}
+
+func TestSnapshotDecoderAuthenticatesHeader(t *testing.T) {
+    header := &jraftpb.SnapshotHeader{
+        Version:        snapshotEncryptionVersion,
+        WriteEndpoints: &jraftpb.WriteEndpoints{Endpoints: []string{"/index"}},
+        AppliedIndex:   10,
+        Codec:          jraftpb.SnapshotCodec_CODEC_ZSTD,
+        Encryption:     &jraftpb.SnapshotEncryption{KeyId: "k1", DataKey: []byte("wrapped data key")},
+    }
+    encodedHeader, err := encodeSnapshotHeader(header)
+    if err != nil {
+        t.Fatal(err)
+    }
+    data := randomSnapshot(2*snapshotFrameSize + 12345)
+    encoded := encodeSnapshotWithHeader(t, data, header.Codec, testDataKey, encodedHeader)
+    if decoded, err := decodeSnapshotWithHeader(encoded, header.Codec, testDataKey, encodedHeader); err != nil || !bytes.Equal(decoded, data) {
+        t.Fatalf("decoding with the header of the snapshot: %d bytes, %v", len(decoded), err)
+    }
+
+    tests := []struct {
+        name   string
+        tamper func(header *jraftpb.SnapshotHeader)
+    }{
+        {name: "applied index", tamper: func(header *jraftpb.SnapshotHeader) { header.AppliedIndex++ }},
+        {name: "write endpoints", tamper: func(header *jraftpb.SnapshotHeader) { header.WriteEndpoints.Endpoints = []string{"/index", "/delete"} }},
+        {name: "no write endpoints", tamper: func(header *jraftpb.SnapshotHeader) { header.WriteEndpoints = nil }},
+        {name: "codec", tamper: func(header *jraftpb.SnapshotHeader) { header.Codec = jraftpb.SnapshotCodec_CODEC_GZIP }},
+        {name: "executor snapshot ID", tamper: func(header *jraftpb.SnapshotHeader) { header.ExecutorSnapshotId = "other" }},
+    }
+    for _, test := range tests {
+        tampered := proto.Clone(header).(*jraftpb.SnapshotHeader)
+        test.tamper(tampered)
+        tamperedHeader, err := encodeSnapshotHeader(tampered)
+        if err != nil {
+            t.Fatal(err)
+        }
+        // the frames are decoded with the codec of the snapshot, only the header differs
+        decoded, err := decodeSnapshotWithHeader(encoded, header.Codec, testDataKey, tamperedHeader)
+        if !errors.Is(err, ErrSnapshotCorrupted) {
+            t.Errorf("%s: decoded %d bytes with error %v, want %v", test.name, len(decoded), err, ErrSnapshotCorrupted)
+        }
+    }
+}
+
+func TestRestoreRejectsPlaintextSnapshot(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "keys.yml")
+    writeKeyring(t, path, SnapshotKeyring{Current: "k1", Keys: []SnapshotKey{{ID: "k1", Key: testKey(1)}}})
+    provider, err := newKeyFileProvider(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    header := &jraftpb.SnapshotHeader{Version: snapshotManifestVersion, AppliedIndex: 10}
+    encodedHeader, err := encodeSnapshotHeader(header)
+    if err != nil {
+        t.Fatal(err)
+    }
+    plaintext := append(encodedHeader, encodeSnapshotWithHeader(t, []byte("executor state"), header.Codec, nil, encodedHeader)...)
+
+    fsm := DummyExecutorFSM()
+    defer fsm.executor.close()
+    fsm.snapshotKeys = provider
+    snapshots := []struct {
+        name string
+        data []byte
+    }{
+        {name: "unencrypted snapshot", data: plaintext},
+        // the snapshots taken by earlier versions have no header
+        {name: "snapshot without header", data: []byte("executor state")},
+    }
+    for _, snapshot := range snapshots {
+        if err := fsm.Restore(io.NopCloser(bytes.NewReader(snapshot.data))); !errors.Is(err, ErrSnapshotNotEncrypted) {
+            t.Errorf("Restore() of an %s with snapshot keys = %v, want %v", snapshot.name, err, ErrSnapshotNotEncrypted)
+        }
+    }
+    if fsm.appliedIndex.Load() != 0 {
+        t.Errorf("applied index = %d after the rejected restores, want 0", fsm.appliedIndex.Load())
+    }
+
+    // allowed while the snapshots taken before the keys are replaced
+    fsm.snapshotAllowPlaintext = true
+    if dataKey, err := fsm.dataKey(header); dataKey != nil || err != nil {
+        t.Fatalf("dataKey() of an unencrypted snapshot with snapshot_allow_plaintext = %x, %v, want no data key", dataKey, err)
+    }
+}
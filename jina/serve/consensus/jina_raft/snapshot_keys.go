@@ -0,0 +1,228 @@
+package server
+
+import (
+    "context"
+    "crypto/aes"
+    "crypto/cipher"
+    "crypto/rand"
+    "encoding/base64"
+    "errors"
+    "fmt"
+    "os"
+    "sync"
+    "time"
+
+    "gopkg.in/yaml.v3"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+// snapshotKeySize is the size of the AES-256 keys, both the data keys of the snapshots and the keys wrapping them.
+const snapshotKeySize = 32
+
+// ErrSnapshotKeyNotFound is returned when a snapshot is encrypted with a key unknown to the SnapshotKeyProvider.
+var ErrSnapshotKeyNotFound = errors.New("snapshot key not found")
+
+// ErrSnapshotNotEncrypted is returned when a Node with snapshot keys restores an unencrypted snapshot, unless it
+// allows them.
+var ErrSnapshotNotEncrypted = errors.New("snapshot is not encrypted")
+
+// SnapshotKeyProvider holds the key encryption keys wrapping the data key of every snapshot. Keys are named by an ID
+// recorded in the snapshots, so that a snapshot is still decrypted with the key that wrapped it after a rotation.
+type SnapshotKeyProvider interface {
+    // WrapKey encrypts dataKey with the current key, and returns the ID of that key with the encrypted data key
+    WrapKey(ctx context.Context, dataKey []byte) (keyID string, wrapped []byte, err error)
+    // UnwrapKey decrypts a data key wrapped with the key keyID
+    UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error)
+}
+
+// SnapshotKey is a key encryption key of the snapshot key file.
+type SnapshotKey struct {
+    ID      string `yaml:"id"`
+    // Key is the base64 encoding of 32 random bytes
+    Key     string `yaml:"key"`
+    // KeyFile is read instead of Key, so that the secret is not stored in the key file
+    KeyFile string `yaml:"key_file"`
+}
+
+// SnapshotKeyring is the content of the snapshot key file. New snapshots are encrypted with the Current key, the
+// other keys are only used to decrypt the snapshots they encrypted before a rotation.
+type SnapshotKeyring struct {
+    Current string        `yaml:"current"`
+    Keys    []SnapshotKey `yaml:"keys"`
+}
+
+// keyFileProvider is the SnapshotKeyProvider of a SnapshotKeyring file, which is loaded again when it changes on
+// disk so that keys are rotated without restarting the Node.
+type keyFileProvider struct {
+    path string
+
+    mtx     sync.Mutex
+    modTime time.Time
+    current string
+    keys    map[string]cipher.AEAD
+}
+
+func newKeyFileProvider(path string) (*keyFileProvider, error) {
+    p := &keyFileProvider{path: path}
+    if err := p.reload(); err != nil {
+        return nil, err
+    }
+    return p, nil
+}
+
+func (p *keyFileProvider) reload() error {
+    info, err := os.Stat(p.path)
+    if err != nil {
+        return fmt.Errorf("snapshot key file: %v", err)
+    }
+    if info.ModTime() == p.modTime && p.keys != nil {
+        return nil
+    }
+    content, err := os.ReadFile(p.path)
+    if err != nil {
+        return fmt.Errorf("snapshot key file: %v", err)
+    }
+    keyring := SnapshotKeyring{}
+    if err := yaml.Unmarshal(content, &keyring); err != nil {
+        return fmt.Errorf("parsing snapshot key file %q: %v", p.path, err)
+    }
+    keys := map[string]cipher.AEAD{}
+    for _, key := range keyring.Keys {
+        secret := key.Key
+        if key.KeyFile != "" {
+            secret, err = readToken(key.KeyFile)
+            if err != nil {
+                return fmt.Errorf("snapshot key file %q: key %q: %v", p.path, key.ID, err)
+            }
+        }
+        if key.ID == "" || secret == "" {
+            return fmt.Errorf("snapshot key file %q: keys need an id and a key or key_file", p.path)
+        }
+        if _, ok := keys[key.ID]; ok {
+            return fmt.Errorf("snapshot key file %q: duplicate key %q", p.path, key.ID)
+        }
+        decoded, err := base64.StdEncoding.DecodeString(secret)
+        if err != nil || len(decoded) != snapshotKeySize {
+            return fmt.Errorf("snapshot key file %q: key %q must be the base64 encoding of %d bytes", p.path, key.ID, snapshotKeySize)
+        }
+        keys[key.ID], err = newSnapshotAEAD(decoded)
+        if err != nil {
+            return err
+        }
+    }
+    if _, ok := keys[keyring.Current]; !ok {
+        return fmt.Errorf("snapshot key file %q: current key %q is not one of the keys", p.path, keyring.Current)
+    }
+    p.current = keyring.Current
+    p.keys = keys
+    p.modTime = info.ModTime()
+    return nil
+}
+
+// key returns the key id, or the current key if id is empty, after loading the key file again if it changed. An
+// invalid key file fails the snapshots and the restores until it is fixed, rather than using keys meant to be retired.
+func (p *keyFileProvider) key(id string) (string, cipher.AEAD, error) {
+    p.mtx.Lock()
+    defer p.mtx.Unlock()
+    if err := p.reload(); err != nil {
+        return "", nil, err
+    }
+    if id == "" {
+        id = p.current
+    }
+    aead, ok := p.keys[id]
+    if !ok {
+        return "", nil, fmt.Errorf("%w: %q", ErrSnapshotKeyNotFound, id)
+    }
+    return id, aead, nil
+}
+
+// WrapKey encrypts dataKey with AES-256-GCM under the current key, authenticating the key ID. The random nonce is
+// written in front of the encrypted data key.
+func (p *keyFileProvider) WrapKey(ctx context.Context, dataKey []byte) (string, []byte, error) {
+    id, aead, err := p.key("")
+    if err != nil {
+        return "", nil, err
+    }
+    nonce := make([]byte, aead.NonceSize())
+    if _, err := rand.Read(nonce); err != nil {
+        return "", nil, err
+    }
+    return id, aead.Seal(nonce, nonce, dataKey, []byte(id)), nil
+}
+
+// UnwrapKey decrypts a data key wrapped by WrapKey with the key keyID.
+func (p *keyFileProvider) UnwrapKey(ctx context.Context, keyID string, wrapped []byte) ([]byte, error) {
+    if keyID == "" {
+        return nil, fmt.Errorf("%w: the snapshot does not name its key", ErrSnapshotKeyNotFound)
+    }
+    _, aead, err := p.key(keyID)
+    if err != nil {
+        return nil, err
+    }
+    if len(wrapped) < aead.NonceSize() {
+        return nil, fmt.Errorf("%w: truncated data key", ErrSnapshotCorrupted)
+    }
+    dataKey, err := aead.Open(nil, wrapped[:aead.NonceSize()], wrapped[aead.NonceSize():], []byte(keyID))
+    if err != nil {
+        return nil, fmt.Errorf("%w: data key cannot be decrypted with key %q", ErrSnapshotCorrupted, keyID)
+    }
+    return dataKey, nil
+}
+
+// newSnapshotAEAD returns the AES-256-GCM cipher of key.
+func newSnapshotAEAD(key []byte) (cipher.AEAD, error) {
+    block, err := aes.NewCipher(key)
+    if err != nil {
+        return nil, err
+    }
+    return cipher.NewGCM(block)
+}
+
+// snapshotKeyProvider returns the SnapshotKeyProvider encrypting the snapshots, nil if they are not encrypted.
+func (opts Options) snapshotKeyProvider() (SnapshotKeyProvider, error) {
+    if opts.SnapshotKeyProvider != nil {
+        return opts.SnapshotKeyProvider, nil
+    }
+    if opts.SnapshotKeyFile == "" {
+        return nil, nil
+    }
+    return newKeyFileProvider(opts.SnapshotKeyFile)
+}
+
+// encrypt generates the data key of the snapshot and records it in the header, wrapped by keys. The data key is nil
+// if the snapshot is not encrypted.
+func (s *snapshot) encrypt() ([]byte, error) {
+    if s.keys == nil {
+        return nil, nil
+    }
+    dataKey := make([]byte, snapshotKeySize)
+    if _, err := rand.Read(dataKey); err != nil {
+        return nil, err
+    }
+    keyID, wrapped, err := s.keys.WrapKey(context.Background(), dataKey)
+    if err != nil {
+        return nil, err
+    }
+    s.header.Version = snapshotEncryptionVersion
+    s.header.Encryption = &jraftpb.SnapshotEncryption{KeyId: keyID, DataKey: wrapped}
+    s.Logger.Debug("Encrypting the snapshot", "key", keyID)
+    return dataKey, nil
+}
+
+// dataKey unwraps the data key of an encrypted snapshot, nil if the snapshot is not encrypted. A Node with snapshot
+// keys rejects the unencrypted snapshots unless snapshotAllowPlaintext is set, so that the encryption of a snapshot
+// cannot be stripped.
+func (fsm *executorFSM) dataKey(header *jraftpb.SnapshotHeader) ([]byte, error) {
+    encryption := header.GetEncryption()
+    if encryption == nil {
+        if fsm.snapshotKeys != nil && !fsm.snapshotAllowPlaintext {
+            return nil, fmt.Errorf("%w: the node has a snapshot_key_file, set snapshot_allow_plaintext to restore the snapshots taken without it", ErrSnapshotNotEncrypted)
+        }
+        return nil, nil
+    }
+    if fsm.snapshotKeys == nil {
+        return nil, fmt.Errorf("%w: the snapshot is encrypted with key %q, but the node has no snapshot_key_file", ErrSnapshotKeyNotFound, encryption.KeyId)
+    }
+    return fsm.snapshotKeys.UnwrapKey(context.Background(), encryption.KeyId, encryption.DataKey)
+}
@@ -0,0 +1,181 @@
+package server
+
+import (
+    "bytes"
+    "context"
+    "encoding/base64"
+    "errors"
+    "os"
+    "path/filepath"
+    "testing"
+    "time"
+
+    hclog "github.com/hashicorp/go-hclog"
+    "gopkg.in/yaml.v3"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+func testKey(b byte) string {
+    return base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{b}, snapshotKeySize))
+}
+
+// writeKeyring writes keyring to path, with a modification time after the previous one so that it is reloaded.
+func writeKeyring(t *testing.T, path string, keyring SnapshotKeyring) {
+    t.Helper()
+    content, err := yaml.Marshal(keyring)
+    if err != nil {
+        t.Fatal(err)
+    }
+    modTime := time.Now()
+    if info, err := os.Stat(path); err == nil {
+        modTime = info.ModTime().Add(time.Second)
+    }
+    if err := os.WriteFile(path, content, 0600); err != nil {
+        t.Fatal(err)
+    }
+    if err := os.Chtimes(path, modTime, modTime); err != nil {
+        t.Fatal(err)
+    }
+}
+
+func TestKeyFileProviderRotation(t *testing.T) {
+    ctx := context.Background()
+    path := filepath.Join(t.TempDir(), "keys.yml")
+    writeKeyring(t, path, SnapshotKeyring{Current: "k1", Keys: []SnapshotKey{{ID: "k1", Key: testKey(1)}}})
+    provider, err := newKeyFileProvider(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    dataKey := bytes.Repeat([]byte{0xaa}, snapshotKeySize)
+    oldID, oldWrapped, err := provider.WrapKey(ctx, dataKey)
+    if err != nil || oldID != "k1" {
+        t.Fatalf("WrapKey() = %q, %v, want k1", oldID, err)
+    }
+
+    // k2 becomes the current key, k1 is kept to decrypt the older snapshots
+    secretFile := filepath.Join(t.TempDir(), "k2")
+    if err := os.WriteFile(secretFile, []byte(testKey(2)+"\n"), 0600); err != nil {
+        t.Fatal(err)
+    }
+    writeKeyring(t, path, SnapshotKeyring{Current: "k2", Keys: []SnapshotKey{{ID: "k1", Key: testKey(1)}, {ID: "k2", KeyFile: secretFile}}})
+    newID, newWrapped, err := provider.WrapKey(ctx, dataKey)
+    if err != nil || newID != "k2" {
+        t.Fatalf("WrapKey() after the rotation = %q, %v, want k2", newID, err)
+    }
+    for id, wrapped := range map[string][]byte{oldID: oldWrapped, newID: newWrapped} {
+        unwrapped, err := provider.UnwrapKey(ctx, id, wrapped)
+        if err != nil || !bytes.Equal(unwrapped, dataKey) {
+            t.Errorf("UnwrapKey(%q) = %x, %v, want %x", id, unwrapped, err, dataKey)
+        }
+    }
+
+    // the data key wrapped by k1 is authenticated with its ID, it cannot be passed for a key wrapped by k2
+    if _, err := provider.UnwrapKey(ctx, newID, oldWrapped); !errors.Is(err, ErrSnapshotCorrupted) {
+        t.Errorf("UnwrapKey(%q) of a data key wrapped by %q = %v, want %v", newID, oldID, err, ErrSnapshotCorrupted)
+    }
+
+    // once k1 is retired, its snapshots cannot be decrypted anymore
+    writeKeyring(t, path, SnapshotKeyring{Current: "k2", Keys: []SnapshotKey{{ID: "k2", KeyFile: secretFile}}})
+    if _, err := provider.UnwrapKey(ctx, oldID, oldWrapped); !errors.Is(err, ErrSnapshotKeyNotFound) {
+        t.Errorf("UnwrapKey(%q) after retiring it = %v, want %v", oldID, err, ErrSnapshotKeyNotFound)
+    }
+}
+
+func TestKeyFileProviderUnwrapErrors(t *testing.T) {
+    ctx := context.Background()
+    path := filepath.Join(t.TempDir(), "keys.yml")
+    writeKeyring(t, path, SnapshotKeyring{Current: "k1", Keys: []SnapshotKey{{ID: "k1", Key: testKey(1)}}})
+    provider, err := newKeyFileProvider(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+    _, wrapped, err := provider.WrapKey(ctx, bytes.Repeat([]byte{0xaa}, snapshotKeySize))
+    if err != nil {
+        t.Fatal(err)
+    }
+    tampered := append([]byte{}, wrapped...)
+    tampered[len(tampered)-1] ^= 1
+
+    tests := []struct {
+        name    string
+        keyID   string
+        wrapped []byte
+        want    error
+    }{
+        {name: "unknown key", keyID: "k0", wrapped: wrapped, want: ErrSnapshotKeyNotFound},
+        {name: "no key ID", keyID: "", wrapped: wrapped, want: ErrSnapshotKeyNotFound},
+        {name: "tampered data key", keyID: "k1", wrapped: tampered, want: ErrSnapshotCorrupted},
+        {name: "truncated data key", keyID: "k1", wrapped: wrapped[:4], want: ErrSnapshotCorrupted},
+    }
+    for _, test := range tests {
+        if _, err := provider.UnwrapKey(ctx, test.keyID, test.wrapped); !errors.Is(err, test.want) {
+            t.Errorf("%s: UnwrapKey() = %v, want %v", test.name, err, test.want)
+        }
+    }
+}
+
+func TestKeyFileProviderInvalidKeyring(t *testing.T) {
+    tests := []struct {
+        name    string
+        keyring SnapshotKeyring
+    }{
+        {name: "no keys", keyring: SnapshotKeyring{Current: "k1"}},
+        {name: "unknown current key", keyring: SnapshotKeyring{Current: "k2", Keys: []SnapshotKey{{ID: "k1", Key: testKey(1)}}}},
+        {name: "key without ID", keyring: SnapshotKeyring{Current: "", Keys: []SnapshotKey{{Key: testKey(1)}}}},
+        {name: "key without secret", keyring: SnapshotKeyring{Current: "k1", Keys: []SnapshotKey{{ID: "k1"}}}},
+        {name: "duplicate key", keyring: SnapshotKeyring{Current: "k1", Keys: []SnapshotKey{{ID: "k1", Key: testKey(1)}, {ID: "k1", Key: testKey(2)}}}},
+        {name: "short key", keyring: SnapshotKeyring{Current: "k1", Keys: []SnapshotKey{{ID: "k1", Key: base64.StdEncoding.EncodeToString([]byte("short"))}}}},
+        {name: "missing key file", keyring: SnapshotKeyring{Current: "k1", Keys: []SnapshotKey{{ID: "k1", KeyFile: "/nonexistent/k1"}}}},
+    }
+    for _, test := range tests {
+        path := filepath.Join(t.TempDir(), "keys.yml")
+        writeKeyring(t, path, test.keyring)
+        if _, err := newKeyFileProvider(path); err == nil {
+            t.Errorf("%s: newKeyFileProvider() succeeded", test.name)
+        }
+    }
+
+    if _, err := newKeyFileProvider(filepath.Join(t.TempDir(), "missing.yml")); err == nil {
+        t.Error("newKeyFileProvider() of a missing file succeeded")
+    }
+}
+
+func TestSnapshotDataKey(t *testing.T) {
+    path := filepath.Join(t.TempDir(), "keys.yml")
+    writeKeyring(t, path, SnapshotKeyring{Current: "k1", Keys: []SnapshotKey{{ID: "k1", Key: testKey(1)}}})
+    provider, err := newKeyFileProvider(path)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    plain := &snapshot{header: &jraftpb.SnapshotHeader{Version: snapshotManifestVersion}, Logger: hclog.NewNullLogger()}
+    if dataKey, err := plain.encrypt(); dataKey != nil || err != nil {
+        t.Fatalf("encrypt() without keys = %x, %v, want no data key", dataKey, err)
+    }
+    if dataKey, err := (&executorFSM{}).dataKey(plain.header); dataKey != nil || err != nil {
+        t.Fatalf("dataKey() of an unencrypted snapshot = %x, %v, want no data key", dataKey, err)
+    }
+    if _, err := (&executorFSM{snapshotKeys: provider}).dataKey(plain.header); !errors.Is(err, ErrSnapshotNotEncrypted) {
+        t.Fatalf("dataKey() of an unencrypted snapshot with snapshot keys = %v, want %v", err, ErrSnapshotNotEncrypted)
+    }
+
+    encrypted := &snapshot{header: &jraftpb.SnapshotHeader{Version: snapshotManifestVersion}, keys: provider, Logger: hclog.NewNullLogger()}
+    dataKey, err := encrypted.encrypt()
+    if err != nil || len(dataKey) != snapshotKeySize {
+        t.Fatalf("encrypt() = %x, %v, want a data key of %d bytes", dataKey, err, snapshotKeySize)
+    }
+    if encrypted.header.Version != snapshotEncryptionVersion || encrypted.header.GetEncryption().GetKeyId() != "k1" {
+        t.Fatalf("header of the encrypted snapshot = %v, want version %d and key k1", encrypted.header, snapshotEncryptionVersion)
+    }
+    if bytes.Contains(encrypted.header.GetEncryption().GetDataKey(), dataKey) {
+        t.Fatal("the header holds the data key in clear")
+    }
+    unwrapped, err := (&executorFSM{snapshotKeys: provider}).dataKey(encrypted.header)
+    if err != nil || !bytes.Equal(unwrapped, dataKey) {
+        t.Fatalf("dataKey() = %x, %v, want %x", unwrapped, err, dataKey)
+    }
+    if _, err := (&executorFSM{}).dataKey(encrypted.header); !errors.Is(err, ErrSnapshotKeyNotFound) {
+        t.Fatalf("dataKey() without snapshot keys = %v, want %v", err, ErrSnapshotKeyNotFound)
+    }
+}
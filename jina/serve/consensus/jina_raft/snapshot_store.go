@@ -0,0 +1,313 @@
+package server
+
+import (
+    "context"
+    "errors"
+    "fmt"
+    "io"
+    "math"
+    "os"
+    "path/filepath"
+    "sync"
+
+    "github.com/hashicorp/raft"
+    hclog "github.com/hashicorp/go-hclog"
+    jraftpb "jraft/jraft-go-proto"
+    "google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// pinnedFile is written in the folder of a pinned snapshot, so that the pin survives a restart.
+const pinnedFile = "pinned"
+
+var (
+    // ErrSnapshotNotFound is returned for a snapshot ID missing from the store.
+    ErrSnapshotNotFound = errors.New("snapshot not found")
+    // ErrSnapshotPinned is returned when deleting a pinned snapshot, unpin it first.
+    ErrSnapshotPinned = errors.New("snapshot is pinned")
+    // ErrLatestSnapshot is returned when deleting the most recent snapshot, which Raft needs to compact its log
+    // and to catch up the members lagging behind.
+    ErrLatestSnapshot = errors.New("the most recent snapshot cannot be deleted")
+    // ErrSnapshotInUse is returned when deleting a snapshot being read, e.g. sent to a follower by InstallSnapshot.
+    ErrSnapshotInUse = errors.New("snapshot is in use")
+)
+
+// snapshotStore is the raft.FileSnapshotStore of a Node, keeping the retain most recent snapshots, the pinned ones
+// and the ones being read.
+type snapshotStore struct {
+    *raft.FileSnapshotStore
+    // dir holds a folder per snapshot, named after its ID
+    dir    string
+    retain int
+    logger hclog.Logger
+    // mtx serializes the reaping of the old snapshots with the deletes and the pins
+    mtx    sync.Mutex
+    // readers counts the open readers of each snapshot, guarded by readersMtx which is also held while a snapshot
+    // is removed, so that it is never opened half deleted
+    readers    map[string]int
+    readersMtx sync.Mutex
+}
+
+func newSnapshotStore(baseDir string, retain int, logger hclog.Logger) (*snapshotStore, error) {
+    // the file store keeps every snapshot, they are reaped by snapshotStore which knows about the pins
+    store, err := raft.NewFileSnapshotStoreWithLogger(baseDir, math.MaxInt32, logger)
+    if err != nil {
+        return nil, err
+    }
+    return &snapshotStore{
+        FileSnapshotStore: store,
+        dir:               filepath.Join(baseDir, "snapshots"),
+        retain:            retain,
+        logger:            logger,
+        readers:           map[string]int{},
+    }, nil
+}
+
+// Open opens the snapshot id for reading, it is not reaped nor deleted until the reader is closed.
+func (s *snapshotStore) Open(id string) (*raft.SnapshotMeta, io.ReadCloser, error) {
+    s.readersMtx.Lock()
+    defer s.readersMtx.Unlock()
+    meta, r, err := s.FileSnapshotStore.Open(id)
+    if err != nil {
+        return nil, nil, err
+    }
+    s.readers[id]++
+    return meta, &snapshotReader{ReadCloser: r, store: s, id: id}, nil
+}
+
+type snapshotReader struct {
+    io.ReadCloser
+    store *snapshotStore
+    id    string
+    once  sync.Once
+}
+
+func (r *snapshotReader) Close() error {
+    err := r.ReadCloser.Close()
+    r.once.Do(func() {
+        r.store.readersMtx.Lock()
+        defer r.store.readersMtx.Unlock()
+        if r.store.readers[r.id]--; r.store.readers[r.id] == 0 {
+            delete(r.store.readers, r.id)
+        }
+    })
+    return err
+}
+
+// remove deletes the folder of the snapshot id, unless it is open.
+func (s *snapshotStore) remove(id string) error {
+    s.readersMtx.Lock()
+    defer s.readersMtx.Unlock()
+    if s.readers[id] > 0 {
+        return fmt.Errorf("%w: %s", ErrSnapshotInUse, id)
+    }
+    return os.RemoveAll(filepath.Join(s.dir, id))
+}
+
+// Create starts a new snapshot, the old ones are reaped once it is closed.
+func (s *snapshotStore) Create(version raft.SnapshotVersion, index, term uint64, configuration raft.Configuration, configurationIndex uint64, trans raft.Transport) (raft.SnapshotSink, error) {
+    sink, err := s.FileSnapshotStore.Create(version, index, term, configuration, configurationIndex, trans)
+    if err != nil {
+        return nil, err
+    }
+    return &reapingSnapshotSink{SnapshotSink: sink, store: s}, nil
+}
+
+type reapingSnapshotSink struct {
+    raft.SnapshotSink
+    store *snapshotStore
+}
+
+func (sink *reapingSnapshotSink) Close() error {
+    if err := sink.SnapshotSink.Close(); err != nil {
+        return err
+    }
+    return sink.store.reap()
+}
+
+// reap deletes the snapshots older than the retain most recent ones, except the pinned ones and the open ones, which
+// are reaped after the next snapshot.
+func (s *snapshotStore) reap() error {
+    s.mtx.Lock()
+    defer s.mtx.Unlock()
+    snapshots, err := s.List()
+    if err != nil {
+        return err
+    }
+    retained := 0
+    for _, meta := range snapshots {
+        if s.pinned(meta.ID) {
+            continue
+        }
+        if retained < s.retain {
+            retained++
+            continue
+        }
+        err := s.remove(meta.ID)
+        if errors.Is(err, ErrSnapshotInUse) {
+            s.logger.Info("Keeping snapshot in use", "id", meta.ID)
+            continue
+        }
+        if err != nil {
+            return fmt.Errorf("reaping snapshot %s: %v", meta.ID, err)
+        }
+        s.logger.Info("Reaped snapshot", "id", meta.ID)
+    }
+    return nil
+}
+
+func (s *snapshotStore) pinned(id string) bool {
+    _, err := os.Stat(filepath.Join(s.dir, id, pinnedFile))
+    return err == nil
+}
+
+// find returns the position of the snapshot id in snapshots, the most recent first.
+func (s *snapshotStore) find(id string) ([]*raft.SnapshotMeta, int, error) {
+    snapshots, err := s.List()
+    if err != nil {
+        return nil, 0, err
+    }
+    for i, meta := range snapshots {
+        if meta.ID == id {
+            return snapshots, i, nil
+        }
+    }
+    return nil, 0, fmt.Errorf("%w: %s", ErrSnapshotNotFound, id)
+}
+
+// Delete removes the snapshot id, unless it is the most recent, a pinned or an open one.
+func (s *snapshotStore) Delete(id string) error {
+    s.mtx.Lock()
+    defer s.mtx.Unlock()
+    _, i, err := s.find(id)
+    if err != nil {
+        return err
+    }
+    if i == 0 {
+        return fmt.Errorf("%w: %s", ErrLatestSnapshot, id)
+    }
+    if s.pinned(id) {
+        return fmt.Errorf("%w: %s", ErrSnapshotPinned, id)
+    }
+    if err := s.remove(id); err != nil {
+        return err
+    }
+    s.logger.Info("Deleted snapshot", "id", id)
+    return nil
+}
+
+// Pin keeps the snapshot id whatever the retention, or lets it be reaped again with the next snapshot if not pinned.
+func (s *snapshotStore) Pin(id string, pinned bool) (*jraftpb.SnapshotInfo, error) {
+    s.mtx.Lock()
+    defer s.mtx.Unlock()
+    snapshots, i, err := s.find(id)
+    if err != nil {
+        return nil, err
+    }
+    path := filepath.Join(s.dir, id, pinnedFile)
+    if pinned {
+        err = os.WriteFile(path, nil, 0644)
+    } else if err = os.Remove(path); os.IsNotExist(err) {
+        err = nil
+    }
+    if err != nil {
+        return nil, err
+    }
+    s.logger.Info("Changed the pin of snapshot", "id", id, "pinned", pinned)
+    return s.info(snapshots[i]), nil
+}
+
+// Get returns the snapshot id.
+func (s *snapshotStore) Get(id string) (*jraftpb.SnapshotInfo, error) {
+    snapshots, i, err := s.find(id)
+    if err != nil {
+        return nil, err
+    }
+    return s.info(snapshots[i]), nil
+}
+
+// Infos returns the snapshots of the store, the most recent first.
+func (s *snapshotStore) Infos() ([]*jraftpb.SnapshotInfo, error) {
+    snapshots, err := s.List()
+    if err != nil {
+        return nil, err
+    }
+    infos := make([]*jraftpb.SnapshotInfo, len(snapshots))
+    for i, meta := range snapshots {
+        infos[i] = s.info(meta)
+    }
+    return infos, nil
+}
+
+// info describes the snapshot of meta, with the Executor snapshot ID read from its header.
+func (s *snapshotStore) info(meta *raft.SnapshotMeta) *jraftpb.SnapshotInfo {
+    info := &jraftpb.SnapshotInfo{
+        Id:     meta.ID,
+        Index:  meta.Index,
+        Term:   meta.Term,
+        Size:   meta.Size,
+        Pinned: s.pinned(meta.ID),
+    }
+    if stat, err := os.Stat(filepath.Join(s.dir, meta.ID, "meta.json")); err == nil {
+        info.CreatedAt = timestamppb.New(stat.ModTime())
+    }
+    _, r, err := s.Open(meta.ID)
+    if err != nil {
+        s.logger.Warn("Error opening snapshot", "id", meta.ID, "error", err)
+        return info
+    }
+    defer r.Close()
+    header, _, _, err := readSnapshotHeader(r)
+    if err != nil {
+        s.logger.Warn("Error reading the header of snapshot", "id", meta.ID, "error", err)
+        return info
+    }
+    info.ExecutorSnapshotId = header.GetExecutorSnapshotId()
+    info.Codec = header.GetCodec()
+    info.KeyId = header.GetEncryption().GetKeyId()
+    return info
+}
+
+// TakeSnapshot takes a snapshot of the Node and of its Executor, and returns it once persisted. The snapshot goes on
+// when ctx expires before.
+func (n *Node) TakeSnapshot(ctx context.Context) (*jraftpb.SnapshotInfo, error) {
+    n.logger.Info("Taking a snapshot")
+    future := n.raft.Snapshot()
+    done := make(chan error, 1)
+    go func() { done <- future.Error() }()
+    select {
+    case err := <-done:
+        if err != nil {
+            n.logger.Error("Error taking a snapshot", "error", err)
+            return nil, err
+        }
+    case <-ctx.Done():
+        return nil, fmt.Errorf("waiting for the snapshot: %w", ctx.Err())
+    }
+    meta, r, err := future.Open()
+    if err != nil {
+        return nil, err
+    }
+    r.Close()
+    return n.snapshots.Get(meta.ID)
+}
+
+// Snapshots lists the snapshots in the store of the Node, the most recent first.
+func (n *Node) Snapshots() ([]*jraftpb.SnapshotInfo, error) {
+    return n.snapshots.Infos()
+}
+
+// SnapshotInfo returns the snapshot id of the store of the Node.
+func (n *Node) SnapshotInfo(id string) (*jraftpb.SnapshotInfo, error) {
+    return n.snapshots.Get(id)
+}
+
+// DeleteSnapshot removes the snapshot id from the store of the Node. The most recent snapshot, the pinned ones and the
+// ones being read cannot be deleted.
+func (n *Node) DeleteSnapshot(id string) error {
+    return n.snapshots.Delete(id)
+}
+
+// PinSnapshot keeps the snapshot id in the store of the Node whatever SnapshotRetain, or unpins it.
+func (n *Node) PinSnapshot(id string, pinned bool) (*jraftpb.SnapshotInfo, error) {
+    return n.snapshots.Pin(id, pinned)
+}
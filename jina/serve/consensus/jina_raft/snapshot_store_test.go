@@ -0,0 +1,87 @@
+package server
+
+import (
+    "errors"
+    "testing"
+
+    hclog "github.com/hashicorp/go-hclog"
+    "github.com/hashicorp/raft"
+)
+
+// createSnapshot persists an empty snapshot at index in store, which reaps the old ones, and returns its ID.
+func createSnapshot(t *testing.T, store *snapshotStore, index uint64) string {
+    t.Helper()
+    sink, err := store.Create(raft.SnapshotVersionMax, index, 1, raft.Configuration{}, 1, nil)
+    if err != nil {
+        t.Fatal(err)
+    }
+    if err := sink.Close(); err != nil {
+        t.Fatal(err)
+    }
+    return sink.ID()
+}
+
+func snapshotIDs(t *testing.T, store *snapshotStore) []string {
+    t.Helper()
+    snapshots, err := store.List()
+    if err != nil {
+        t.Fatal(err)
+    }
+    ids := make([]string, len(snapshots))
+    for i, meta := range snapshots {
+        ids[i] = meta.ID
+    }
+    return ids
+}
+
+func TestSnapshotStoreKeepsOpenSnapshots(t *testing.T) {
+    store, err := newSnapshotStore(t.TempDir(), 1, hclog.NewNullLogger())
+    if err != nil {
+        t.Fatal(err)
+    }
+    first := createSnapshot(t, store, 10)
+    _, r, err := store.Open(first)
+    if err != nil {
+        t.Fatal(err)
+    }
+    // a second reader, as when InstallSnapshot sends the snapshot to several followers
+    _, r2, err := store.Open(first)
+    if err != nil {
+        t.Fatal(err)
+    }
+
+    second := createSnapshot(t, store, 20)
+    third := createSnapshot(t, store, 30)
+    // the second snapshot is reaped, the first one is read
+    if ids := snapshotIDs(t, store); len(ids) != 2 || ids[0] != third || ids[1] != first {
+        t.Fatalf("snapshots = %v, want %v", ids, []string{third, first})
+    }
+    if err := store.Delete(first); !errors.Is(err, ErrSnapshotInUse) {
+        t.Fatalf("Delete() of an open snapshot = %v, want %v", err, ErrSnapshotInUse)
+    }
+
+    r.Close()
+    // closing twice does not release the other reader
+    r.Close()
+    if err := store.Delete(first); !errors.Is(err, ErrSnapshotInUse) {
+        t.Fatalf("Delete() of a snapshot still open = %v, want %v", err, ErrSnapshotInUse)
+    }
+    r2.Close()
+    if err := store.Delete(first); err != nil {
+        t.Fatalf("Delete() of a closed snapshot = %v", err)
+    }
+    if _, _, err := store.Open(second); err == nil {
+        t.Fatal("Open() of a reaped snapshot succeeded")
+    }
+
+    _, r, err = store.Open(third)
+    if err != nil {
+        t.Fatal(err)
+    }
+    r.Close()
+    // once closed, an old snapshot is reaped with the next one
+    fourth := createSnapshot(t, store, 40)
+    if ids := snapshotIDs(t, store); len(ids) != 1 || ids[0] != fourth {
+        t.Fatalf("snapshots = %v, want %v", ids, []string{fourth})
+    }
+}
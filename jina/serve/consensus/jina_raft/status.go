@@ -0,0 +1,162 @@
+package server
+
+import (
+    "context"
+    "strconv"
+    "sync"
+    "time"
+
+    jraftpb "jraft/jraft-go-proto"
+    "google.golang.org/grpc"
+    healthpb "google.golang.org/grpc/health/grpc_health_v1"
+    "google.golang.org/protobuf/types/known/durationpb"
+)
+
+// statusTimeout bounds the health check of the Executor and the status calls to each member.
+const statusTimeout = time.Second
+
+// jinaRaftStatusServer implements the JinaRaftStatus service on top of a Node.
+type jinaRaftStatusServer struct {
+    node *Node
+    jraftpb.UnimplementedJinaRaftStatusServer
+}
+
+// GetStatus is the status RPC counterpart of Node.Status.
+func (s *jinaRaftStatusServer) GetStatus(ctx context.Context, req *jraftpb.StatusRequest) (*jraftpb.StatusResponse, error) {
+    return s.node.Status(ctx, req.Local)
+}
+
+// Status reports the state of the Node, its snapshots and the reachability of its Executor. Unless local is set,
+// every other member of the Raft configuration is asked for its own progress, in parallel.
+func (n *Node) Status(ctx context.Context, local bool) (*jraftpb.StatusResponse, error) {
+    stats := n.raft.Stats()
+    leaderAddress, leaderID := leaderWithID(n.raft)
+    status := &jraftpb.StatusResponse{
+        Id:                n.opts.RaftID,
+        Address:           string(n.opts.raftAdvertisedAddress()),
+        State:             n.raft.State().String(),
+        LeaderId:          string(leaderID),
+        LeaderAddress:     string(leaderAddress),
+        Term:              statUint(stats, "term"),
+        CommitIndex:       statUint(stats, "commit_index"),
+        AppliedIndex:      statUint(stats, "applied_index"),
+        LastLogIndex:      statUint(stats, "last_log_index"),
+        LastLogTerm:       statUint(stats, "last_log_term"),
+        LastSnapshotIndex: statUint(stats, "last_snapshot_index"),
+        LastSnapshotTerm:  statUint(stats, "last_snapshot_term"),
+    }
+    if lastContact, ok := statLastContact(stats); ok {
+        status.LastContact = durationpb.New(lastContact)
+        status.HasLastContact = true
+    }
+
+    future := n.raft.GetConfiguration()
+    if err := future.Error(); err != nil {
+        n.logger.Error("Error getting the RAFT configuration", "error", err)
+        return nil, err
+    }
+    servers := future.Configuration().Servers
+
+    snapshots, err := n.snapshots.Infos()
+    if err != nil {
+        n.logger.Error("Error listing the snapshots", "error", err)
+        return nil, err
+    }
+    status.Snapshots = snapshots
+
+    executorCtx, cancel := context.WithTimeout(ctx, statusTimeout)
+    defer cancel()
+    status.Executor = n.fsm.executorStatus(executorCtx)
+    status.ExecutorSnapshot = n.fsm.snapshotProgress.proto()
+    status.ExecutorRestore = n.fsm.restoreProgress.proto()
+
+    status.Peers = make([]*jraftpb.PeerStatus, len(servers))
+    var wg sync.WaitGroup
+    for i, server := range servers {
+        peer := &jraftpb.PeerStatus{
+            Id:       string(server.ID),
+            Address:  string(server.Address),
+            Suffrage: server.Suffrage.String(),
+            IsLeader: server.ID == leaderID,
+        }
+        status.Peers[i] = peer
+        switch {
+        case server.ID == n.opts.raftServerID():
+            peer.Reachable = true
+            peer.State = status.State
+            peer.Term = status.Term
+            peer.LastLogIndex = status.LastLogIndex
+            peer.AppliedIndex = status.AppliedIndex
+            peer.LastContact = status.LastContact
+        case !local:
+            wg.Add(1)
+            go func(peer *jraftpb.PeerStatus) {
+                defer wg.Done()
+                n.peerStatus(ctx, peer)
+            }(peer)
+        }
+    }
+    wg.Wait()
+    return status, nil
+}
+
+// peerStatus fills peer with the local status reported by the member.
+func (n *Node) peerStatus(ctx context.Context, peer *jraftpb.PeerStatus) {
+    ctx, cancel := context.WithTimeout(ctx, statusTimeout)
+    defer cancel()
+    conn, err := grpc.DialContext(ctx, peer.Address, n.adminDialOptions...)
+    if err != nil {
+        peer.Error = err.Error()
+        return
+    }
+    defer conn.Close()
+    remote, err := jraftpb.NewJinaRaftStatusClient(conn).GetStatus(ctx, &jraftpb.StatusRequest{Local: true})
+    if err != nil {
+        n.logger.Debug("Could not get the status of a member", "id", peer.Id, "address", peer.Address, "error", err)
+        peer.Error = err.Error()
+        return
+    }
+    peer.Reachable = true
+    peer.State = remote.State
+    peer.Term = remote.Term
+    peer.LastLogIndex = remote.LastLogIndex
+    peer.AppliedIndex = remote.AppliedIndex
+    peer.LastContact = remote.LastContact
+}
+
+// executorStatus checks the Executor through its gRPC health service.
+func (fsm *executorFSM) executorStatus(ctx context.Context) *jraftpb.ExecutorStatus {
+    status := &jraftpb.ExecutorStatus{Target: fsm.executor.target}
+    var resp *healthpb.HealthCheckResponse
+    err := fsm.executor.invoke(ctx, "Check", func(ctx context.Context, conn *grpc.ClientConn) (err error) {
+        resp, err = healthpb.NewHealthClient(conn).Check(ctx, &healthpb.HealthCheckRequest{})
+        return err
+    })
+    if err != nil {
+        status.Error = err.Error()
+        return status
+    }
+    status.Reachable = true
+    status.ServingStatus = resp.Status.String()
+    return status
+}
+
+func statUint(stats map[string]string, key string) uint64 {
+    value, _ := strconv.ParseUint(stats[key], 10, 64)
+    return value
+}
+
+// statLastContact parses the last_contact stat, which is "never" until the node hears from a leader.
+func statLastContact(stats map[string]string) (time.Duration, bool) {
+    switch stats["last_contact"] {
+    case "never", "":
+        return 0, false
+    case "0":
+        return 0, true
+    }
+    lastContact, err := time.ParseDuration(stats["last_contact"])
+    if err != nil {
+        return 0, false
+    }
+    return lastContact, true
+}
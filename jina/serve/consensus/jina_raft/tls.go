@@ -0,0 +1,226 @@
+package server
+
+import (
+    "context"
+    "crypto/tls"
+    "crypto/x509"
+    "errors"
+    "fmt"
+    "net"
+    "os"
+    "sync"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/grpc/credentials"
+    "google.golang.org/grpc/credentials/insecure"
+)
+
+// tlsReloadInterval is the minimum time between two checks of the certificate files for changes.
+const tlsReloadInterval = time.Second
+
+// tlsFiles are the PEM files of one end of a TLS connection. They are loaded again when they
+// change on disk, so that rotated certificates are used by the next handshakes without a restart.
+type tlsFiles struct {
+    certFile string
+    keyFile  string
+    caFile   string
+
+    mtx         sync.Mutex
+    checked     time.Time
+    modTimes    [3]time.Time
+    certificate *tls.Certificate
+    pool        *x509.CertPool
+}
+
+func newTLSFiles(certFile string, keyFile string, caFile string) (*tlsFiles, error) {
+    if (certFile == "") != (keyFile == "") {
+        return nil, errors.New("a TLS certificate and its key must be given together")
+    }
+    files := &tlsFiles{certFile: certFile, keyFile: keyFile, caFile: caFile}
+    if _, _, err := files.load(); err != nil {
+        return nil, err
+    }
+    return files, nil
+}
+
+// load returns the current certificate and CA pool, reading the files again if they changed.
+// If the changed files cannot be loaded, e.g. while they are being rewritten, the previous ones are kept.
+func (f *tlsFiles) load() (*tls.Certificate, *x509.CertPool, error) {
+    f.mtx.Lock()
+    defer f.mtx.Unlock()
+    if f.checked.IsZero() || time.Since(f.checked) >= tlsReloadInterval {
+        f.checked = time.Now()
+        if err := f.reload(); err != nil && (f.certificate == nil && f.pool == nil) {
+            return nil, nil, err
+        }
+    }
+    return f.certificate, f.pool, nil
+}
+
+func (f *tlsFiles) reload() error {
+    var modTimes [3]time.Time
+    for i, path := range []string{f.certFile, f.keyFile, f.caFile} {
+        if path == "" {
+            continue
+        }
+        info, err := os.Stat(path)
+        if err != nil {
+            return err
+        }
+        modTimes[i] = info.ModTime()
+    }
+    if modTimes == f.modTimes && (f.certificate != nil || f.pool != nil) {
+        return nil
+    }
+    var certificate *tls.Certificate
+    if f.certFile != "" {
+        cert, err := tls.LoadX509KeyPair(f.certFile, f.keyFile)
+        if err != nil {
+            return fmt.Errorf("loading TLS certificate %q: %v", f.certFile, err)
+        }
+        certificate = &cert
+    }
+    var pool *x509.CertPool
+    if f.caFile != "" {
+        pem, err := os.ReadFile(f.caFile)
+        if err != nil {
+            return fmt.Errorf("reading TLS CA %q: %v", f.caFile, err)
+        }
+        pool = x509.NewCertPool()
+        if !pool.AppendCertsFromPEM(pem) {
+            return fmt.Errorf("no certificate found in TLS CA %q", f.caFile)
+        }
+    }
+    f.certificate = certificate
+    f.pool = pool
+    f.modTimes = modTimes
+    return nil
+}
+
+// reloadingCredentials are gRPC transport credentials built from tlsFiles at every handshake.
+type reloadingCredentials struct {
+    files      *tlsFiles
+    server     bool
+    clientAuth bool
+    serverName string
+}
+
+func (c *reloadingCredentials) current() (credentials.TransportCredentials, error) {
+    certificate, pool, err := c.files.load()
+    if err != nil {
+        return nil, err
+    }
+    config := &tls.Config{MinVersion: tls.VersionTLS12}
+    if certificate != nil {
+        config.Certificates = []tls.Certificate{*certificate}
+    }
+    if c.server {
+        config.ClientCAs = pool
+        if c.clientAuth {
+            config.ClientAuth = tls.RequireAndVerifyClientCert
+        }
+    } else {
+        config.RootCAs = pool
+        config.ServerName = c.serverName
+    }
+    return credentials.NewTLS(config), nil
+}
+
+func (c *reloadingCredentials) ClientHandshake(ctx context.Context, authority string, conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+    creds, err := c.current()
+    if err != nil {
+        return nil, nil, err
+    }
+    return creds.ClientHandshake(ctx, authority, conn)
+}
+
+func (c *reloadingCredentials) ServerHandshake(conn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+    creds, err := c.current()
+    if err != nil {
+        return nil, nil, err
+    }
+    return creds.ServerHandshake(conn)
+}
+
+func (c *reloadingCredentials) Info() credentials.ProtocolInfo {
+    return credentials.ProtocolInfo{SecurityProtocol: "tls", SecurityVersion: "1.2", ServerName: c.serverName}
+}
+
+func (c *reloadingCredentials) Clone() credentials.TransportCredentials {
+    clone := *c
+    return &clone
+}
+
+func (c *reloadingCredentials) OverrideServerName(serverName string) error {
+    c.serverName = serverName
+    return nil
+}
+
+// serverCredentials are the credentials of a gRPC server, insecure when no certificate is given.
+// With clientAuth, the clients must present a certificate signed by the CA.
+func serverCredentials(certFile string, keyFile string, caFile string, clientAuth bool) (credentials.TransportCredentials, error) {
+    if certFile == "" && keyFile == "" {
+        if clientAuth {
+            return nil, errors.New("verifying client certificates requires a TLS certificate")
+        }
+        return insecure.NewCredentials(), nil
+    }
+    if clientAuth && caFile == "" {
+        return nil, errors.New("verifying client certificates requires a TLS CA")
+    }
+    files, err := newTLSFiles(certFile, keyFile, caFile)
+    if err != nil {
+        return nil, err
+    }
+    return &reloadingCredentials{files: files, server: true, clientAuth: clientAuth}, nil
+}
+
+// clientCredentials are the credentials to dial a gRPC server, insecure when neither a certificate
+// nor a CA is given. Without CA, the server certificate is verified against the system roots.
+func clientCredentials(certFile string, keyFile string, caFile string, serverName string) (credentials.TransportCredentials, error) {
+    if certFile == "" && keyFile == "" && caFile == "" {
+        return insecure.NewCredentials(), nil
+    }
+    files, err := newTLSFiles(certFile, keyFile, caFile)
+    if err != nil {
+        return nil, err
+    }
+    return &reloadingCredentials{files: files, serverName: serverName}, nil
+}
+
+// clientServerCredentials are the credentials of the server exposing the Jina services. It also serves
+// the Raft transport when no RaftAddress is set.
+func (opts Options) clientServerCredentials() (credentials.TransportCredentials, error) {
+    return serverCredentials(opts.TLSCertFile, opts.TLSKeyFile, opts.TLSCAFile, opts.TLSClientAuth)
+}
+
+// raftServerCredentials are the credentials of the Raft transport server when it has its own RaftAddress.
+// With RaftTLSVerifyPeers, only the peers with a certificate signed by the Raft CA can append entries.
+func (opts Options) raftServerCredentials() (credentials.TransportCredentials, error) {
+    return serverCredentials(opts.RaftTLSCertFile, opts.RaftTLSKeyFile, opts.RaftTLSCAFile, opts.RaftTLSVerifyPeers)
+}
+
+// RaftDialOption is the dial option to reach the Raft transport and raftadmin services of the cluster members.
+func (opts Options) RaftDialOption() (grpc.DialOption, error) {
+    var creds credentials.TransportCredentials
+    var err error
+    if opts.RaftAddress != "" {
+        creds, err = clientCredentials(opts.RaftTLSCertFile, opts.RaftTLSKeyFile, opts.RaftTLSCAFile, "")
+    } else {
+        creds, err = clientCredentials(opts.TLSCertFile, opts.TLSKeyFile, opts.TLSCAFile, "")
+    }
+    if err != nil {
+        return nil, fmt.Errorf("RAFT TLS: %v", err)
+    }
+    return grpc.WithTransportCredentials(creds), nil
+}
+
+// executorDialOptions are the options to dial the Executor.
+func (opts Options) executorDialOptions() ([]grpc.DialOption, error) {
+    creds, err := clientCredentials(opts.ExecutorTLSCertFile, opts.ExecutorTLSKeyFile, opts.ExecutorTLSCAFile, opts.ExecutorTLSServerName)
+    if err != nil {
+        return nil, fmt.Errorf("Executor TLS: %v", err)
+    }
+    return []grpc.DialOption{grpc.WithTransportCredentials(creds)}, nil
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+    "context"
+
+    "go.opentelemetry.io/otel/attribute"
+    "go.opentelemetry.io/otel/codes"
+    "go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracegrpc"
+    "go.opentelemetry.io/otel/propagation"
+    "go.opentelemetry.io/otel/sdk/resource"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    semconv "go.opentelemetry.io/otel/semconv/v1.17.0"
+    "go.opentelemetry.io/otel/trace"
+    "google.golang.org/grpc/metadata"
+)
+
+const tracerName = "jraft/jina_raft"
+
+// propagator reads and writes the W3C trace context, in the gRPC metadata and in the replicated entries.
+var propagator = propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{})
+
+// tracer returns the tracer of the provider of the Node, a no-op one unless the Node exports traces. The provider
+// is not installed globally, so that the Nodes of a process and the program embedding them trace independently.
+func (fsm *executorFSM) tracer() trace.Tracer {
+    if fsm.tracerProvider == nil {
+        return trace.NewNoopTracerProvider().Tracer(tracerName)
+    }
+    return fsm.tracerProvider.Tracer(tracerName)
+}
+
+// newTracerProvider exports the spans of the Node over OTLP/gRPC to opts.TracingEndpoint.
+func newTracerProvider(ctx context.Context, opts Options) (*sdktrace.TracerProvider, error) {
+    exporterOptions := []otlptracegrpc.Option{otlptracegrpc.WithEndpoint(opts.TracingEndpoint)}
+    if opts.TracingInsecure {
+        exporterOptions = append(exporterOptions, otlptracegrpc.WithInsecure())
+    }
+    exporter, err := otlptracegrpc.New(ctx, exporterOptions...)
+    if err != nil {
+        return nil, err
+    }
+    serviceName := opts.TracingServiceName
+    if serviceName == "" {
+        serviceName = "RAFT-" + opts.Name
+    }
+    provider := sdktrace.NewTracerProvider(
+        sdktrace.WithBatcher(exporter),
+        sdktrace.WithResource(resource.NewWithAttributes(
+            semconv.SchemaURL,
+            semconv.ServiceName(serviceName),
+            attribute.String("raft.id", opts.RaftID),
+        )),
+    )
+    return provider, nil
+}
+
+// metadataCarrier adapts gRPC metadata to the TextMapCarrier of the propagator.
+type metadataCarrier metadata.MD
+
+func (c metadataCarrier) Get(key string) string {
+    values := metadata.MD(c).Get(key)
+    if len(values) == 0 {
+        return ""
+    }
+    return values[0]
+}
+
+func (c metadataCarrier) Set(key string, value string) {
+    metadata.MD(c).Set(key, value)
+}
+
+func (c metadataCarrier) Keys() []string {
+    keys := make([]string, 0, len(c))
+    for key := range c {
+        keys = append(keys, key)
+    }
+    return keys
+}
+
+// extractIncoming returns ctx with the trace context sent by the caller in the gRPC metadata.
+func extractIncoming(ctx context.Context) context.Context {
+    md, ok := metadata.FromIncomingContext(ctx)
+    if !ok {
+        return ctx
+    }
+    return propagator.Extract(ctx, metadataCarrier(md))
+}
+
+// injectOutgoing returns ctx with the trace context of its span in the outgoing gRPC metadata, so that
+// the Executor continues the trace.
+func injectOutgoing(ctx context.Context) context.Context {
+    md, ok := metadata.FromOutgoingContext(ctx)
+    if ok {
+        md = md.Copy()
+    } else {
+        md = metadata.MD{}
+    }
+    propagator.Inject(ctx, metadataCarrier(md))
+    return metadata.NewOutgoingContext(ctx, md)
+}
+
+// traceContext serializes the trace context of ctx to be stored in a replicated entry, nil without a valid span.
+func traceContext(ctx context.Context) map[string]string {
+    if !trace.SpanContextFromContext(ctx).IsValid() {
+        return nil
+    }
+    carrier := propagation.MapCarrier{}
+    propagator.Inject(ctx, carrier)
+    return carrier
+}
+
+// contextFromEntry restores the trace context stored in a replicated entry.
+func contextFromEntry(entry map[string]string) context.Context {
+    return propagator.Extract(context.Background(), propagation.MapCarrier(entry))
+}
+
+// spanError records err on span and returns it.
+func spanError(span trace.Span, err error) error {
+    span.RecordError(err)
+    span.SetStatus(codes.Error, err.Error())
+    return err
+}
+
+// endSpan records err, if any, on span and ends it.
+func endSpan(span trace.Span, err error) {
+    if err != nil {
+        spanError(span, err)
+    }
+    span.End()
+}
@@ -0,0 +1,32 @@
+package server
+
+import (
+    "context"
+    "testing"
+
+    "go.opentelemetry.io/otel"
+    sdktrace "go.opentelemetry.io/otel/sdk/trace"
+    "go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+func TestTracerOfTheNode(t *testing.T) {
+    first, second := tracetest.NewSpanRecorder(), tracetest.NewSpanRecorder()
+    firstFSM := &executorFSM{tracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(first))}
+    secondFSM := &executorFSM{tracerProvider: sdktrace.NewTracerProvider(sdktrace.WithSpanProcessor(second))}
+    _, span := firstFSM.tracer().Start(context.Background(), "first")
+    span.End()
+    _, span = secondFSM.tracer().Start(context.Background(), "second")
+    span.End()
+    if len(first.Ended()) != 1 || first.Ended()[0].Name() != "first" || len(second.Ended()) != 1 || second.Ended()[0].Name() != "second" {
+        t.Fatal("the spans of a Node were exported by the provider of another one")
+    }
+    if _, ok := otel.GetTracerProvider().(*sdktrace.TracerProvider); ok {
+        t.Fatal("the provider of a Node was installed globally")
+    }
+
+    // without a provider, the spans are not recorded
+    _, span = (&executorFSM{}).tracer().Start(context.Background(), "untraced")
+    if span.IsRecording() {
+        t.Fatal("a Node without tracing recorded a span")
+    }
+}
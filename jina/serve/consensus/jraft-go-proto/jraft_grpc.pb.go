@@ -0,0 +1,479 @@
+//protoc --go_out jraft-go-proto --go_opt=paths=source_relative --go_opt=Mjraft.proto=jraft-go-proto=jraft/jraft-go-proto --go-grpc_out jraft-go-proto --go-grpc_opt=paths=source_relative --experimental_allow_proto3_optional jraft.proto
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.3.0
+// - protoc             v4.23.4
+// source: jraft.proto
+
+package jraft_go_proto
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+	emptypb "google.golang.org/protobuf/types/known/emptypb"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.32.0 or later.
+const _ = grpc.SupportPackageIsVersion7
+
+const (
+	JinaRaftStatus_GetStatus_FullMethodName = "/jraft.JinaRaftStatus/GetStatus"
+)
+
+// JinaRaftStatusClient is the client API for JinaRaftStatus service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type JinaRaftStatusClient interface {
+	// Returns the role, term, indices, snapshots and Executor reachability of the node, and the progress of every member
+	GetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error)
+}
+
+type jinaRaftStatusClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJinaRaftStatusClient(cc grpc.ClientConnInterface) JinaRaftStatusClient {
+	return &jinaRaftStatusClient{cc}
+}
+
+func (c *jinaRaftStatusClient) GetStatus(ctx context.Context, in *StatusRequest, opts ...grpc.CallOption) (*StatusResponse, error) {
+	out := new(StatusResponse)
+	err := c.cc.Invoke(ctx, JinaRaftStatus_GetStatus_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JinaRaftStatusServer is the server API for JinaRaftStatus service.
+// All implementations must embed UnimplementedJinaRaftStatusServer
+// for forward compatibility
+type JinaRaftStatusServer interface {
+	// Returns the role, term, indices, snapshots and Executor reachability of the node, and the progress of every member
+	GetStatus(context.Context, *StatusRequest) (*StatusResponse, error)
+	mustEmbedUnimplementedJinaRaftStatusServer()
+}
+
+// UnimplementedJinaRaftStatusServer must be embedded to have forward compatible implementations.
+type UnimplementedJinaRaftStatusServer struct {
+}
+
+func (UnimplementedJinaRaftStatusServer) GetStatus(context.Context, *StatusRequest) (*StatusResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetStatus not implemented")
+}
+func (UnimplementedJinaRaftStatusServer) mustEmbedUnimplementedJinaRaftStatusServer() {}
+
+// UnsafeJinaRaftStatusServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JinaRaftStatusServer will
+// result in compilation errors.
+type UnsafeJinaRaftStatusServer interface {
+	mustEmbedUnimplementedJinaRaftStatusServer()
+}
+
+func RegisterJinaRaftStatusServer(s grpc.ServiceRegistrar, srv JinaRaftStatusServer) {
+	s.RegisterService(&JinaRaftStatus_ServiceDesc, srv)
+}
+
+func _JinaRaftStatus_GetStatus_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(StatusRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftStatusServer).GetStatus(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftStatus_GetStatus_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftStatusServer).GetStatus(ctx, req.(*StatusRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// JinaRaftStatus_ServiceDesc is the grpc.ServiceDesc for JinaRaftStatus service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JinaRaftStatus_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jraft.JinaRaftStatus",
+	HandlerType: (*JinaRaftStatusServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetStatus",
+			Handler:    _JinaRaftStatus_GetStatus_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "jraft.proto",
+}
+
+const (
+	JinaRaftAdmin_Decommission_FullMethodName        = "/jraft.JinaRaftAdmin/Decommission"
+	JinaRaftAdmin_ReloadConfig_FullMethodName        = "/jraft.JinaRaftAdmin/ReloadConfig"
+	JinaRaftAdmin_GetReloadableConfig_FullMethodName = "/jraft.JinaRaftAdmin/GetReloadableConfig"
+	JinaRaftAdmin_TakeSnapshot_FullMethodName        = "/jraft.JinaRaftAdmin/TakeSnapshot"
+	JinaRaftAdmin_ListSnapshots_FullMethodName       = "/jraft.JinaRaftAdmin/ListSnapshots"
+	JinaRaftAdmin_GetSnapshot_FullMethodName         = "/jraft.JinaRaftAdmin/GetSnapshot"
+	JinaRaftAdmin_DeleteSnapshot_FullMethodName      = "/jraft.JinaRaftAdmin/DeleteSnapshot"
+	JinaRaftAdmin_PinSnapshot_FullMethodName         = "/jraft.JinaRaftAdmin/PinSnapshot"
+)
+
+// JinaRaftAdminClient is the client API for JinaRaftAdmin service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type JinaRaftAdminClient interface {
+	// Stops accepting writes, drains in-flight writes, hands over leadership and optionally leaves the cluster
+	Decommission(ctx context.Context, in *DecommissionRequest, opts ...grpc.CallOption) (*DecommissionResponse, error)
+	// Applies new reloadable settings and returns the effective ones
+	ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadableConfigProto, error)
+	// Returns the effective reloadable settings
+	GetReloadableConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReloadableConfigProto, error)
+	// Takes a snapshot of the node and of its Executor, and returns it once persisted
+	TakeSnapshot(ctx context.Context, in *TakeSnapshotRequest, opts ...grpc.CallOption) (*SnapshotInfo, error)
+	// Lists the snapshots in the store of the node
+	ListSnapshots(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSnapshotsResponse, error)
+	// Returns a snapshot of the store of the node
+	GetSnapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotInfo, error)
+	// Deletes a snapshot from the store of the node, except the most recent one and the pinned ones
+	DeleteSnapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	// Pins a snapshot so that it is kept whatever the retention, or unpins it
+	PinSnapshot(ctx context.Context, in *PinSnapshotRequest, opts ...grpc.CallOption) (*SnapshotInfo, error)
+}
+
+type jinaRaftAdminClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewJinaRaftAdminClient(cc grpc.ClientConnInterface) JinaRaftAdminClient {
+	return &jinaRaftAdminClient{cc}
+}
+
+func (c *jinaRaftAdminClient) Decommission(ctx context.Context, in *DecommissionRequest, opts ...grpc.CallOption) (*DecommissionResponse, error) {
+	out := new(DecommissionResponse)
+	err := c.cc.Invoke(ctx, JinaRaftAdmin_Decommission_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jinaRaftAdminClient) ReloadConfig(ctx context.Context, in *ReloadConfigRequest, opts ...grpc.CallOption) (*ReloadableConfigProto, error) {
+	out := new(ReloadableConfigProto)
+	err := c.cc.Invoke(ctx, JinaRaftAdmin_ReloadConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jinaRaftAdminClient) GetReloadableConfig(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ReloadableConfigProto, error) {
+	out := new(ReloadableConfigProto)
+	err := c.cc.Invoke(ctx, JinaRaftAdmin_GetReloadableConfig_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jinaRaftAdminClient) TakeSnapshot(ctx context.Context, in *TakeSnapshotRequest, opts ...grpc.CallOption) (*SnapshotInfo, error) {
+	out := new(SnapshotInfo)
+	err := c.cc.Invoke(ctx, JinaRaftAdmin_TakeSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jinaRaftAdminClient) ListSnapshots(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ListSnapshotsResponse, error) {
+	out := new(ListSnapshotsResponse)
+	err := c.cc.Invoke(ctx, JinaRaftAdmin_ListSnapshots_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jinaRaftAdminClient) GetSnapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*SnapshotInfo, error) {
+	out := new(SnapshotInfo)
+	err := c.cc.Invoke(ctx, JinaRaftAdmin_GetSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jinaRaftAdminClient) DeleteSnapshot(ctx context.Context, in *SnapshotRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, JinaRaftAdmin_DeleteSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *jinaRaftAdminClient) PinSnapshot(ctx context.Context, in *PinSnapshotRequest, opts ...grpc.CallOption) (*SnapshotInfo, error) {
+	out := new(SnapshotInfo)
+	err := c.cc.Invoke(ctx, JinaRaftAdmin_PinSnapshot_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// JinaRaftAdminServer is the server API for JinaRaftAdmin service.
+// All implementations must embed UnimplementedJinaRaftAdminServer
+// for forward compatibility
+type JinaRaftAdminServer interface {
+	// Stops accepting writes, drains in-flight writes, hands over leadership and optionally leaves the cluster
+	Decommission(context.Context, *DecommissionRequest) (*DecommissionResponse, error)
+	// Applies new reloadable settings and returns the effective ones
+	ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadableConfigProto, error)
+	// Returns the effective reloadable settings
+	GetReloadableConfig(context.Context, *emptypb.Empty) (*ReloadableConfigProto, error)
+	// Takes a snapshot of the node and of its Executor, and returns it once persisted
+	TakeSnapshot(context.Context, *TakeSnapshotRequest) (*SnapshotInfo, error)
+	// Lists the snapshots in the store of the node
+	ListSnapshots(context.Context, *emptypb.Empty) (*ListSnapshotsResponse, error)
+	// Returns a snapshot of the store of the node
+	GetSnapshot(context.Context, *SnapshotRequest) (*SnapshotInfo, error)
+	// Deletes a snapshot from the store of the node, except the most recent one and the pinned ones
+	DeleteSnapshot(context.Context, *SnapshotRequest) (*emptypb.Empty, error)
+	// Pins a snapshot so that it is kept whatever the retention, or unpins it
+	PinSnapshot(context.Context, *PinSnapshotRequest) (*SnapshotInfo, error)
+	mustEmbedUnimplementedJinaRaftAdminServer()
+}
+
+// UnimplementedJinaRaftAdminServer must be embedded to have forward compatible implementations.
+type UnimplementedJinaRaftAdminServer struct {
+}
+
+func (UnimplementedJinaRaftAdminServer) Decommission(context.Context, *DecommissionRequest) (*DecommissionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Decommission not implemented")
+}
+func (UnimplementedJinaRaftAdminServer) ReloadConfig(context.Context, *ReloadConfigRequest) (*ReloadableConfigProto, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReloadConfig not implemented")
+}
+func (UnimplementedJinaRaftAdminServer) GetReloadableConfig(context.Context, *emptypb.Empty) (*ReloadableConfigProto, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetReloadableConfig not implemented")
+}
+func (UnimplementedJinaRaftAdminServer) TakeSnapshot(context.Context, *TakeSnapshotRequest) (*SnapshotInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method TakeSnapshot not implemented")
+}
+func (UnimplementedJinaRaftAdminServer) ListSnapshots(context.Context, *emptypb.Empty) (*ListSnapshotsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListSnapshots not implemented")
+}
+func (UnimplementedJinaRaftAdminServer) GetSnapshot(context.Context, *SnapshotRequest) (*SnapshotInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSnapshot not implemented")
+}
+func (UnimplementedJinaRaftAdminServer) DeleteSnapshot(context.Context, *SnapshotRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSnapshot not implemented")
+}
+func (UnimplementedJinaRaftAdminServer) PinSnapshot(context.Context, *PinSnapshotRequest) (*SnapshotInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method PinSnapshot not implemented")
+}
+func (UnimplementedJinaRaftAdminServer) mustEmbedUnimplementedJinaRaftAdminServer() {}
+
+// UnsafeJinaRaftAdminServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to JinaRaftAdminServer will
+// result in compilation errors.
+type UnsafeJinaRaftAdminServer interface {
+	mustEmbedUnimplementedJinaRaftAdminServer()
+}
+
+func RegisterJinaRaftAdminServer(s grpc.ServiceRegistrar, srv JinaRaftAdminServer) {
+	s.RegisterService(&JinaRaftAdmin_ServiceDesc, srv)
+}
+
+func _JinaRaftAdmin_Decommission_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DecommissionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftAdminServer).Decommission(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftAdmin_Decommission_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftAdminServer).Decommission(ctx, req.(*DecommissionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JinaRaftAdmin_ReloadConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReloadConfigRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftAdminServer).ReloadConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftAdmin_ReloadConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftAdminServer).ReloadConfig(ctx, req.(*ReloadConfigRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JinaRaftAdmin_GetReloadableConfig_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftAdminServer).GetReloadableConfig(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftAdmin_GetReloadableConfig_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftAdminServer).GetReloadableConfig(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JinaRaftAdmin_TakeSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TakeSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftAdminServer).TakeSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftAdmin_TakeSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftAdminServer).TakeSnapshot(ctx, req.(*TakeSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JinaRaftAdmin_ListSnapshots_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftAdminServer).ListSnapshots(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftAdmin_ListSnapshots_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftAdminServer).ListSnapshots(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JinaRaftAdmin_GetSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftAdminServer).GetSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftAdmin_GetSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftAdminServer).GetSnapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JinaRaftAdmin_DeleteSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftAdminServer).DeleteSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftAdmin_DeleteSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftAdminServer).DeleteSnapshot(ctx, req.(*SnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _JinaRaftAdmin_PinSnapshot_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PinSnapshotRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(JinaRaftAdminServer).PinSnapshot(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: JinaRaftAdmin_PinSnapshot_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(JinaRaftAdminServer).PinSnapshot(ctx, req.(*PinSnapshotRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// JinaRaftAdmin_ServiceDesc is the grpc.ServiceDesc for JinaRaftAdmin service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var JinaRaftAdmin_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "jraft.JinaRaftAdmin",
+	HandlerType: (*JinaRaftAdminServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Decommission",
+			Handler:    _JinaRaftAdmin_Decommission_Handler,
+		},
+		{
+			MethodName: "ReloadConfig",
+			Handler:    _JinaRaftAdmin_ReloadConfig_Handler,
+		},
+		{
+			MethodName: "GetReloadableConfig",
+			Handler:    _JinaRaftAdmin_GetReloadableConfig_Handler,
+		},
+		{
+			MethodName: "TakeSnapshot",
+			Handler:    _JinaRaftAdmin_TakeSnapshot_Handler,
+		},
+		{
+			MethodName: "ListSnapshots",
+			Handler:    _JinaRaftAdmin_ListSnapshots_Handler,
+		},
+		{
+			MethodName: "GetSnapshot",
+			Handler:    _JinaRaftAdmin_GetSnapshot_Handler,
+		},
+		{
+			MethodName: "DeleteSnapshot",
+			Handler:    _JinaRaftAdmin_DeleteSnapshot_Handler,
+		},
+		{
+			MethodName: "PinSnapshot",
+			Handler:    _JinaRaftAdmin_PinSnapshot_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "jraft.proto",
+}
@@ -8,9 +8,8 @@ package main
 
 // Workaround missing variadic function support
 // https://github.com/golang/go/issues/975
-int PyArg_ParseTuple_run(PyObject * args, PyObject * kwargs, char **myAddr, char **raftId, char **raftDir, char **name, char **executorTarget, int *HeartbeatTimeout, int *ElectionTimeout, int *CommitTimeout, int *MaxAppendEntries, bool *BatchApplyCh, bool *ShutdownOnRemove, uint64_t *TrailingLogs, int *snapshotInterval, uint64_t *SnapshotThreshold, int *LeaderLeaseTimeout, char **LogLevel, bool *NoSnapshotRestoreOnStart) {
-    static char *kwlist[] = {"myAddr", "raftId", "raftDir", "name", "executorTarget", "HeartbeatTimeout", "ElectionTimeout", "CommitTimeout", "MaxAppendEntries", "BatchApplyCh", "ShutdownOnRemove", "TrailingLogs", "SnapshotInterval", "SnapshotThreshold", "LeaderLeaseTimeout", "LogLevel", "NoSnapshotRestoreOnStart", NULL};
-    return PyArg_ParseTupleAndKeywords(args, kwargs, "sssss|llllppklklsp", kwlist, myAddr, raftId, raftDir, name, executorTarget, HeartbeatTimeout, ElectionTimeout, CommitTimeout, MaxAppendEntries, BatchApplyCh, ShutdownOnRemove, TrailingLogs, snapshotInterval, SnapshotThreshold, LeaderLeaseTimeout, LogLevel, NoSnapshotRestoreOnStart);
+int PyArg_ParseTuple_run(PyObject * args, char **myAddr, char **raftId, char **raftDir, char **name, char **executorTarget) {
+    return PyArg_ParseTuple(args, "sssss", myAddr, raftId, raftDir, name, executorTarget);
 }
 
 int PyArg_ParseTuple_add_voter(PyObject * args, char **a, char **b, char **c) {
@@ -21,15 +20,41 @@ int PyArg_ParseTuple_get_configuration(PyObject * args, char **a, char **b) {
     return PyArg_ParseTuple(args, "ss", a, b);
 }
 
+int PyArg_ParseTuple_get_status(PyObject * args, char **a) {
+    return PyArg_ParseTuple(args, "s", a);
+}
+
+int PyArg_ParseTuple_take_snapshot(PyObject * args, char **a, double *b) {
+    return PyArg_ParseTuple(args, "s|d", a, b);
+}
+
+int PyArg_ParseTuple_delete_snapshot(PyObject * args, char **a, char **b) {
+    return PyArg_ParseTuple(args, "ss", a, b);
+}
+
+int PyArg_ParseTuple_pin_snapshot(PyObject * args, char **a, char **b, int *c) {
+    return PyArg_ParseTuple(args, "ss|p", a, b, c);
+}
+
 PyObject * run(PyObject* , PyObject*, PyObject*);
 
 PyObject * add_voter(PyObject* , PyObject*);
 PyObject * get_configuration(PyObject* , PyObject*);
+PyObject * get_status(PyObject* , PyObject*);
+PyObject * take_snapshot(PyObject* , PyObject*);
+PyObject * list_snapshots(PyObject* , PyObject*);
+PyObject * delete_snapshot(PyObject* , PyObject*);
+PyObject * pin_snapshot(PyObject* , PyObject*);
 
 static PyMethodDef methods[] = {
     {"run", (PyCFunction)run, METH_VARARGS | METH_KEYWORDS, "Run the raft Node server"},
     {"add_voter", (PyCFunction)add_voter, METH_VARARGS, "Client to add voter"},
     {"get_configuration", (PyCFunction)get_configuration, METH_VARARGS, "Get configuration"},
+    {"get_status", (PyCFunction)get_status, METH_VARARGS, "Get the status of a RAFT node and of its cluster"},
+    {"take_snapshot", (PyCFunction)take_snapshot, METH_VARARGS, "Take a snapshot of a RAFT node and wait for it"},
+    {"list_snapshots", (PyCFunction)list_snapshots, METH_VARARGS, "List the snapshots of a RAFT node"},
+    {"delete_snapshot", (PyCFunction)delete_snapshot, METH_VARARGS, "Delete a snapshot of a RAFT node"},
+    {"pin_snapshot", (PyCFunction)pin_snapshot, METH_VARARGS, "Pin or unpin a snapshot of a RAFT node"},
     {NULL, NULL, 0, NULL}
 };
 
@@ -51,6 +76,16 @@ void raise_exception(char *msg) {
     PyErr_SetString(PyExc_ValueError, msg);
 }
 
+// Parse a JSON document into Python objects with the json module
+PyObject * json_loads(char *s) {
+    PyObject *json = PyImport_ImportModule("json");
+    if (json == NULL)
+        return NULL;
+    PyObject *result = PyObject_CallMethod(json, "loads", "s", s);
+    Py_DECREF(json);
+    return result;
+}
+
 */
 import "C"
 
@@ -12,6 +12,8 @@
 
 #ifndef GO_CGO_GOSTRING_TYPEDEF
 typedef struct { const char *p; ptrdiff_t n; } _GoString_;
+extern size_t _GoStringLen(_GoString_ s);
+extern const char *_GoStringPtr(_GoString_ s);
 #endif
 
 #endif
@@ -22,10 +24,15 @@ typedef struct { const char *p; ptrdiff_t n; } _GoString_;
 #line 3 "run.go"
  #include <Python.h>
  #include <stdbool.h>
- int PyArg_ParseTuple_run(PyObject * args, PyObject * kwargs, char **myAddr, char **raftId, char **raftDir, char **name, char **executorTarget, int *HeartbeatTimeout, int *ElectionTimeout, int *CommitTimeout, int *MaxAppendEntries, bool *BatchApplyCh, bool *ShutdownOnRemove, uint64_t *TrailingLogs, int *snapshotInterval, uint64_t *SnapshotThreshold, int *LeaderLeaseTimeout, char **LogLevel, bool *NoSnapshotRestoreOnStart);
+ int PyArg_ParseTuple_run(PyObject * args, char **myAddr, char **raftId, char **raftDir, char **name, char **executorTarget);
  int PyArg_ParseTuple_add_voter(PyObject * args, char **a, char **b, char **c);
  int PyArg_ParseTuple_get_configuration(PyObject * args, char **a, char **b);
+ int PyArg_ParseTuple_get_status(PyObject * args, char **a);
+ int PyArg_ParseTuple_take_snapshot(PyObject * args, char **a, double *b);
+ int PyArg_ParseTuple_delete_snapshot(PyObject * args, char **a, char **b);
+ int PyArg_ParseTuple_pin_snapshot(PyObject * args, char **a, char **b, int *c);
  void raise_exception(char *msg);
+ PyObject * json_loads(char *s);
 
 #line 1 "cgo-generated-wrapper"
 
@@ -53,10 +60,16 @@ typedef size_t GoUintptr;
 typedef float GoFloat32;
 typedef double GoFloat64;
 #ifdef _MSC_VER
+#if !defined(__cplusplus) || _MSVC_LANG <= 201402L
 #include <complex.h>
 typedef _Fcomplex GoComplex64;
 typedef _Dcomplex GoComplex128;
 #else
+#include <complex>
+typedef std::complex<float> GoComplex64;
+typedef std::complex<double> GoComplex128;
+#endif
+#else
 typedef float _Complex GoComplex64;
 typedef double _Complex GoComplex128;
 #endif
@@ -86,6 +99,11 @@ extern "C" {
 extern PyObject* run(PyObject* self, PyObject* args, PyObject* kwargs);
 extern PyObject* add_voter(PyObject* self, PyObject* args);
 extern PyObject* get_configuration(PyObject* self, PyObject* args);
+extern PyObject* get_status(PyObject* self, PyObject* args);
+extern PyObject* take_snapshot(PyObject* self, PyObject* args);
+extern PyObject* list_snapshots(PyObject* self, PyObject* args);
+extern PyObject* delete_snapshot(PyObject* self, PyObject* args);
+extern PyObject* pin_snapshot(PyObject* self, PyObject* args);
 
 #ifdef __cplusplus
 }
@@ -2,10 +2,15 @@ package main
 
 // #include <Python.h>
 // #include <stdbool.h>
-// int PyArg_ParseTuple_run(PyObject * args, PyObject * kwargs, char **myAddr, char **raftId, char **raftDir, char **name, char **executorTarget, int *HeartbeatTimeout, int *ElectionTimeout, int *CommitTimeout, int *MaxAppendEntries, bool *BatchApplyCh, bool *ShutdownOnRemove, uint64_t *TrailingLogs, int *snapshotInterval, uint64_t *SnapshotThreshold, int *LeaderLeaseTimeout, char **LogLevel, bool *NoSnapshotRestoreOnStart);
+// int PyArg_ParseTuple_run(PyObject * args, char **myAddr, char **raftId, char **raftDir, char **name, char **executorTarget);
 // int PyArg_ParseTuple_add_voter(PyObject * args, char **a, char **b, char **c);
 // int PyArg_ParseTuple_get_configuration(PyObject * args, char **a, char **b);
+// int PyArg_ParseTuple_get_status(PyObject * args, char **a);
+// int PyArg_ParseTuple_take_snapshot(PyObject * args, char **a, double *b);
+// int PyArg_ParseTuple_delete_snapshot(PyObject * args, char **a, char **b);
+// int PyArg_ParseTuple_pin_snapshot(PyObject * args, char **a, char **b, int *c);
 // void raise_exception(char *msg);
+// PyObject * json_loads(char *s);
 import "C"
 
 
@@ -14,216 +19,76 @@ import (
     "flag"
     "fmt"
     "log"
-    "net"
     "os"
     "os/signal"
     "syscall"
-    "path/filepath"
     "time"
+    "path/filepath"
     "unsafe"
     transport "github.com/Jille/raft-grpc-transport"
-    "github.com/Jille/raftadmin"
     "github.com/hashicorp/raft"
     boltdb "github.com/hashicorp/raft-boltdb"
     jinaraft "jraft/jina_raft"
-    pb "jraft/jina-go-proto"
     "google.golang.org/grpc"
     "google.golang.org/grpc/credentials/insecure"
-    "google.golang.org/grpc/reflection"
-    healthpb "google.golang.org/grpc/health/grpc_health_v1"
     hclog "github.com/hashicorp/go-hclog"
 )
 
-func NewRaft(ctx context.Context,
-            name string,
-            myID string,
-            myAddress string,
-            raftDir string,
-            HeartbeatTimeout int,
-            ElectionTimeout int,
-            CommitTimeout int,
-            MaxAppendEntries int,
-            BatchApplyCh bool,
-            ShutdownOnRemove bool,
-            TrailingLogs uint64,
-            SnapshotInterval int,
-            SnapshotThreshold uint64,
-            LeaderLeaseTimeout int,
-            LogLevel string,
-            NoSnapshotRestoreOnStart bool,
-            fsm raft.FSM) (*raft.Raft, *transport.Manager, error) {
-    config := raft.DefaultConfig()
-    config.LocalID = raft.ServerID(myID)
-    config.HeartbeatTimeout         = time.Duration(HeartbeatTimeout) * time.Millisecond
-    config.ElectionTimeout          = time.Duration(ElectionTimeout) * time.Millisecond
-    config.CommitTimeout            = time.Duration(CommitTimeout) * time.Millisecond
-    config.MaxAppendEntries         = MaxAppendEntries
-    config.BatchApplyCh             = BatchApplyCh
-    config.ShutdownOnRemove         = ShutdownOnRemove
-    config.TrailingLogs             = TrailingLogs
-    config.SnapshotInterval         = time.Duration(SnapshotInterval) * time.Second
-    config.SnapshotThreshold        = SnapshotThreshold
-    config.LeaderLeaseTimeout       = time.Duration(LeaderLeaseTimeout) * time.Millisecond
-    config.LogLevel                 = LogLevel
-    config.NoSnapshotRestoreOnStart = NoSnapshotRestoreOnStart
-    config.Logger = hclog.New(&hclog.LoggerOptions{
-                    Name:   "RAFT-" + name,
-                    Level:  hclog.LevelFromString(LogLevel),
-                })
-
-    baseDir := filepath.Join(raftDir, myID)
-    err := os.MkdirAll(baseDir, os.ModePerm)
-    if err != nil {
-        fmt.Printf("Error creating baseDir (%v) folder: %v\n", baseDir, err)
-    }
-
-    logs_db, err := boltdb.NewBoltStore(filepath.Join(baseDir, "logs.dat"))
-    if err != nil {
-        return nil, nil, fmt.Errorf(`boltdb.NewBoltStore(%q): %v`, filepath.Join(baseDir, "logs.dat"), err)
-    }
-
-    stable_db, err := boltdb.NewBoltStore(filepath.Join(baseDir, "stable.dat"))
-    if err != nil {
-        return nil, nil, fmt.Errorf(`boltdb.NewBoltStore(%q): %v`, filepath.Join(baseDir, "stable.dat"), err)
+// Run loads the node settings from configFile, the environment and the overrides, then runs the node
+// until it is stopped by a signal. On SIGHUP, the settings are loaded again and the reloadable ones applied.
+func Run(configFile string, overrides map[string]string) {
+    opts, err := jinaraft.LoadOptions(configFile, overrides)
+    if err == nil {
+        err = opts.Validate()
     }
-
-    file_snapshot, err := raft.NewFileSnapshotStore(baseDir, 3, os.Stderr)
-    if err != nil {
-        return nil, nil, fmt.Errorf(`raft.NewFileSnapshotStore(%q, ...): %v`, baseDir, err)
-    }
-
-    tm := transport.New(raft.ServerAddress(myAddress), []grpc.DialOption{grpc.WithTransportCredentials(insecure.NewCredentials())})
-
-    r, err := raft.NewRaft(config, fsm, logs_db, stable_db, file_snapshot, tm.Transport())
-
     if err != nil {
-        return nil, nil, fmt.Errorf("raft.NewRaft: %v", err)
-    }
-
-    cfg := raft.Configuration{
-        Servers: []raft.Server{
-            {
-                Suffrage: raft.Voter,
-                ID:       raft.ServerID(myID),
-                Address:  raft.ServerAddress(myAddress),
-            },
-        },
-    }
-    f := r.BootstrapCluster(cfg)
-    // raft bootstrap error can be ignored safely https://github.com/hashicorp/raft/blob/44124c28758b8cfb675e90c75a204a08a84f8d4f/api.go#L220
-    if err := f.Error(); err != nil {
-        return r, tm, nil
+        log.Fatalf("Invalid configuration: %v", err)
     }
-
-    return r, tm, nil
-}
-
-func Run(myAddr string,
-         raftId string,
-         raftDir string,
-         name string,
-         executorTarget string,
-         HeartbeatTimeout int,
-         ElectionTimeout int,
-         CommitTimeout int,
-         MaxAppendEntries int,
-         BatchApplyCh bool,
-         ShutdownOnRemove bool,
-         TrailingLogs uint64,
-         SnapshotInterval int,
-         SnapshotThreshold uint64,
-         LeaderLeaseTimeout int,
-         LogLevel string,
-         NoSnapshotRestoreOnStart bool) {
     run_logger := hclog.New(&hclog.LoggerOptions{
-                    Name:   "RAFT-" + name,
-                    Level:  hclog.LevelFromString(LogLevel),
+                    Name:   "RAFT-" + opts.Name,
+                    Level:  hclog.LevelFromString(opts.LogLevel),
                 })
-    if raftId == "" {
-        log.Fatalf("flag --raft_id is required")
-    }
-    run_logger.Info("Running RAFT node in", "address", myAddr, "with the ID", raftId, "in directory", raftDir, "and connecting to Executor", executorTarget)
+    run_logger.Info("Running RAFT node in", "address", opts.Address, "with the ID", opts.RaftID, "in directory", opts.RaftDir, "and connecting to Executor", opts.ExecutorTarget, "executor socket", opts.ExecutorSocket)
     ctx := context.Background()
-    _, port, err := net.SplitHostPort(myAddr)
-    if err != nil {
-        run_logger.Error("failed to parse", "local address", myAddr, "with error", err)
-        log.Fatalf("failed to parse local address (%q): %v", myAddr, err)
-    }
-    run_logger.Debug("starting to listen on", "port", port)
-    sock, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
-    if err != nil {
-        run_logger.Error("failed to listen", "error", err)
-        log.Fatalf("failed to listen: %v", err)
-    }
-    defer sock.Close()
-
-    executorFSM := jinaraft.NewExecutorFSM(executorTarget, LogLevel, name, raftId)
-
-    r, tm, err := NewRaft(ctx,
-                        name,
-                        raftId,
-                        myAddr,
-                        raftDir,
-                        HeartbeatTimeout,
-                        ElectionTimeout,
-                        CommitTimeout,
-                        MaxAppendEntries,
-                        BatchApplyCh,
-                        ShutdownOnRemove,
-                        TrailingLogs,
-                        SnapshotInterval,
-                        SnapshotThreshold,
-                        LeaderLeaseTimeout,
-                        LogLevel,
-                        NoSnapshotRestoreOnStart,
-                        executorFSM)
+    node, err := jinaraft.NewNode(opts)
     if err != nil {
         run_logger.Error("Failed to start RAFT node", "error", err)
         log.Fatalf("Failed to start RAFT node: %v", err)
     }
-    grpcServer := grpc.NewServer()
-    rpc_logger := hclog.New(&hclog.LoggerOptions{
-                    Name:   "RPC-" + name,
-                    Level:  hclog.LevelFromString(LogLevel),
-                })
-
-    rpc_interface := jinaraft.RpcInterface{
-                            Executor: executorFSM,
-                            Raft:     r,
-                            Logger:   rpc_logger,
-                        }
-
-    pb.RegisterJinaSingleDataRequestRPCServer(grpcServer, &rpc_interface)
-    pb.RegisterJinaDiscoverEndpointsRPCServer(grpcServer, &rpc_interface)
-    pb.RegisterJinaInfoRPCServer(grpcServer, &rpc_interface)
-    pb.RegisterJinaRPCServer(grpcServer, &rpc_interface)
-    tm.Register(grpcServer)
-
-    healthpb.RegisterHealthServer(grpcServer, &rpc_interface)
-
-    raftadmin.Register(grpcServer, r)
-    reflection.Register(grpcServer)
+    if err := node.Start(ctx); err != nil {
+        log.Fatalf("Failed to start RAFT node: %v", err)
+    }
+    sighup := make(chan os.Signal, 1)
+    signal.Notify(sighup, syscall.SIGHUP)
+    go func(){
+        for range sighup {
+            run_logger.Info("Received SIGHUP, reloading configuration")
+            reloaded, err := jinaraft.LoadOptions(configFile, overrides)
+            if err != nil {
+                run_logger.Error("Error loading configuration, keeping the current one", "error", err)
+                continue
+            }
+            node.Reload(reloaded.Reloadable())
+        }
+    }()
     sigchnl := make(chan os.Signal, 1)
     signal.Notify(sigchnl, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT, os.Interrupt)
     go func(){
         sig := <-sigchnl
         run_logger.Info("Received", "signal", sig)
-        run_logger.Info("gRPCServer stopping")
-        grpcServer.GracefulStop()
-        run_logger.Info("gRPCServer stopped, close socket")
-        sock.Close()
-        run_logger.Info("Socket closed")
-        run_logger.Info("call RAFT shutdown")
-        shutdownResultFuture := r.Shutdown()
-        err := shutdownResultFuture.Error()
-        if err != nil {
-            run_logger.Error("Error returned while shutting RAFT down", "error", err)
+        if sig == syscall.SIGTERM && opts.DecommissionOnSigterm {
+            decommissionCtx, cancel := context.WithTimeout(ctx, opts.DrainTimeout)
+            _, err := node.Decommission(decommissionCtx, opts.LeaveClusterOnDecommission)
+            cancel()
+            if err != nil {
+                run_logger.Error("Error decommissioning RAFT node, shutting down anyway", "error", err)
+            }
+        }
+        if err := node.Shutdown(ctx); err != nil {
             log.Fatalf("Error returned while shutting RAFT down: %v", err)
         }
-        run_logger.Info("RAFT shutdown whithout error")
     }()
-    if err := grpcServer.Serve(sock); err != nil {
-        run_logger.Error("failed to serve", "error", err)
+    if err := node.Wait(); err != nil {
         log.Fatalf("failed to serve: %v", err)
     }
 }
@@ -238,43 +103,19 @@ func findServerByID(servers []raft.Server, id raft.ServerID) *raft.Server {
 }
 
 func main() {
-    raftDefaultConfig := raft.DefaultConfig()
-
-    name                     := flag.String("name", "executor", "name to identify in the logger the Node")
-    myAddr                   := flag.String("address", "localhost:50051", "TCP host+port for this node")
-    raftId                   := flag.String("raft_id", "", "Node id used by Raft")
-    raftDir                  := flag.String("raft_data_dir", "data/", "Raft data dir")
-    executorTarget           := flag.String("executor_target", "localhost:54321", "underlying executor host+port")
-    HeartbeatTimeout         := flag.Int("heartbeat_timeout", int(raftDefaultConfig.HeartbeatTimeout / time.Millisecond), "HeartbeatTimeout for the RAFT node")
-    ElectionTimeout          := flag.Int("election_timeout", int(raftDefaultConfig.ElectionTimeout / time.Millisecond), "ElectionTimeout for the RAFT node")
-    CommitTimeout            := flag.Int("commit_timeout", int(raftDefaultConfig.CommitTimeout / time.Millisecond), "CommitTimeout for the RAFT node")
-    MaxAppendEntries         := flag.Int("max_append_entries", raftDefaultConfig.MaxAppendEntries, "MaxAppendEntries for the RAFT node")
-    BatchApplyCh             := flag.Bool("batch_applych", raftDefaultConfig.BatchApplyCh, "BatchApplyCh for the RAFT node")
-    ShutdownOnRemove         := flag.Bool("shutdown_on_remove", raftDefaultConfig.ShutdownOnRemove, "ShutdownOnRemove for the RAFT node")
-    TrailingLogs             := flag.Uint64("trailing_logs", raftDefaultConfig.TrailingLogs, "TrailingLogs for the RAFT node")
-    SnapshotInterval         := flag.Int("snapshot_interval", int(raftDefaultConfig.SnapshotInterval / time.Second), "SnapshotInterval for the RAFT node")
-    SnapshotThreshold        := flag.Uint64("snapshot_threshold", raftDefaultConfig.SnapshotThreshold, "SnapshotThreshold for the RAFT node")
-    LeaderLeaseTimeout       := flag.Int("leader_lease_timeout", int(raftDefaultConfig.LeaderLeaseTimeout / time.Millisecond), "LeaderLeaseTimeout for the RAFT node")
-    LogLevel                 := flag.String("log_level", raftDefaultConfig.LogLevel, "LogLevel for the RAFT node")
-    NoSnapshotRestoreOnStart := flag.Bool("no_snapshot_restore_on_start", raftDefaultConfig.NoSnapshotRestoreOnStart, "NoSnapshotRestoreOnStart for the RAFT node")
-
-    Run(*myAddr,
-        *raftId,
-        *raftDir,
-        *name,
-        *executorTarget,
-        *HeartbeatTimeout,
-        *ElectionTimeout,
-        *CommitTimeout,
-        *MaxAppendEntries,
-        *BatchApplyCh,
-        *ShutdownOnRemove,
-        *TrailingLogs,
-        *SnapshotInterval,
-        *SnapshotThreshold,
-        *LeaderLeaseTimeout,
-        *LogLevel,
-        *NoSnapshotRestoreOnStart)
+    configFile := flag.String("config", "", "YAML or JSON file with the node settings, overridden by the JINA_RAFT_* environment variables and by the flags")
+    flagOptions := jinaraft.DefaultOptions()
+    flagOptions.RegisterFlags(flag.CommandLine)
+    flag.Parse()
+
+    // only the flags set explicitly override the configuration file and the environment
+    overrides := map[string]string{}
+    flag.Visit(func(f *flag.Flag) {
+        if f.Name != "config" {
+            overrides[f.Name] = f.Value.String()
+        }
+    })
+    Run(*configFile, overrides)
 }
 
 
@@ -285,76 +126,64 @@ func run(self *C.PyObject, args *C.PyObject, kwargs *C.PyObject) *C.PyObject {
     var raftDir *C.char
     var name *C.char
     var executorTarget *C.char
-    var HeartbeatTimeout C.int
-    var ElectionTimeout C.int
-    var CommitTimeout C.int
-    var MaxAppendEntries C.int
-    var BatchApplyCh C.bool
-    var ShutdownOnRemove C.bool
-    var TrailingLogs C.uint64_t
-    var SnapshotInterval C.int
-    var SnapshotThreshold C.uint64_t
-    var LeaderLeaseTimeout C.int
-    var LogLevel *C.char
-    var NoSnapshotRestoreOnStart C.bool
-
-    raftDefaultConfig := raft.DefaultConfig()
-    HeartbeatTimeout         = C.int(int64(raftDefaultConfig.HeartbeatTimeout / time.Millisecond))
-    ElectionTimeout          = C.int(int64(raftDefaultConfig.ElectionTimeout / time.Millisecond))
-    CommitTimeout            = C.int(int64(raftDefaultConfig.CommitTimeout / time.Millisecond))
-    MaxAppendEntries         = C.int(raftDefaultConfig.MaxAppendEntries)
-    BatchApplyCh             = C.bool(raftDefaultConfig.BatchApplyCh)
-    ShutdownOnRemove         = C.bool(raftDefaultConfig.ShutdownOnRemove)
-    TrailingLogs             = C.uint64_t(raftDefaultConfig.TrailingLogs)
-    SnapshotInterval         = C.int(raftDefaultConfig.SnapshotInterval / time.Second)
-    SnapshotThreshold        = C.uint64_t(raftDefaultConfig.SnapshotThreshold)
-    LeaderLeaseTimeout       = C.int(raftDefaultConfig.LeaderLeaseTimeout / time.Millisecond)
-    LogLevel                 = C.CString(raftDefaultConfig.LogLevel)
-    defer C.free(unsafe.Pointer(LogLevel))
-
-    NoSnapshotRestoreOnStart = C.bool(raftDefaultConfig.NoSnapshotRestoreOnStart)
-
-    if C.PyArg_ParseTuple_run(args,
-                             kwargs,
-                             &myAddr,
-                             &raftId,
-                             &raftDir,
-                             &name,
-                             &executorTarget,
-                             &HeartbeatTimeout,
-                             &ElectionTimeout,
-                             &CommitTimeout,
-                             &MaxAppendEntries,
-                             &BatchApplyCh,
-                             &ShutdownOnRemove,
-                             &TrailingLogs,
-                             &SnapshotInterval,
-                             &SnapshotThreshold,
-                             &LeaderLeaseTimeout,
-                             &LogLevel,
-                             &NoSnapshotRestoreOnStart) != 0 {
-        Run(C.GoString(myAddr),
-            C.GoString(raftId),
-            C.GoString(raftDir),
-            C.GoString(name),
-            C.GoString(executorTarget),
-            int(HeartbeatTimeout),
-            int(ElectionTimeout),
-            int(CommitTimeout),
-            int(MaxAppendEntries),
-            BatchApplyCh != false,
-            ShutdownOnRemove != false,
-            uint64(TrailingLogs),
-            int(SnapshotInterval),
-            uint64(SnapshotThreshold),
-            int(LeaderLeaseTimeout),
-            C.GoString(LogLevel),
-            NoSnapshotRestoreOnStart != false)
+
+    if C.PyArg_ParseTuple_run(args, &myAddr, &raftId, &raftDir, &name, &executorTarget) == 0 {
+        return nil
+    }
+    overrides := map[string]string{
+        "address":         C.GoString(myAddr),
+        "raft_id":         C.GoString(raftId),
+        "raft_data_dir":   C.GoString(raftDir),
+        "name":            C.GoString(name),
+        "executor_target": C.GoString(executorTarget),
+    }
+    // every keyword argument is a setting of the node, except `config` which points to a configuration file
+    configFile := ""
+    if kwargs != nil {
+        var pos C.Py_ssize_t
+        var key *C.PyObject
+        var value *C.PyObject
+        for C.PyDict_Next(kwargs, &pos, &key, &value) != 0 {
+            if value == C.Py_None {
+                continue
+            }
+            if pyObjectToString(key) == "config" {
+                configFile = pyObjectToString(value)
+                continue
+            }
+            overrides[pyObjectToString(key)] = pyObjectToString(value)
+        }
     }
+    opts, err := jinaraft.LoadOptions(configFile, overrides)
+    if err == nil {
+        err = opts.Validate()
+    }
+    if err != nil {
+        cerr := C.CString(fmt.Sprintf("Invalid RAFT configuration: %v", err))
+        defer C.free(unsafe.Pointer(cerr))
+        C.raise_exception(cerr)
+        return nil
+    }
+    Run(configFile, overrides)
     C.Py_IncRef(C.Py_None);
     return C.Py_None;
 }
 
+// pyObjectToString returns the Go string of str(obj)
+func pyObjectToString(obj *C.PyObject) string {
+    str := C.PyObject_Str(obj)
+    if str == nil {
+        return ""
+    }
+    defer C.Py_DecRef(str)
+    utf8 := C.PyUnicode_AsUTF8String(str)
+    if utf8 == nil {
+        return ""
+    }
+    defer C.Py_DecRef(utf8)
+    return C.GoString(C.PyBytes_AsString(utf8))
+}
+
 //export add_voter
 func add_voter(self *C.PyObject, args *C.PyObject) *C.PyObject {
     logLevel := os.Getenv("JINA_LOG_LEVEL")
@@ -460,3 +289,73 @@ func get_configuration(self *C.PyObject, args *C.PyObject) *C.PyObject {
     C.raise_exception(cerr)
     return nil
 }
+
+//export get_status
+func get_status(self *C.PyObject, args *C.PyObject) *C.PyObject {
+    var target *C.char
+    if C.PyArg_ParseTuple_get_status(args, &target) == 0 {
+        return nil
+    }
+    status, err := GetStatus(C.GoString(target))
+    return jsonResult("get_status", status, err)
+}
+
+//export take_snapshot
+func take_snapshot(self *C.PyObject, args *C.PyObject) *C.PyObject {
+    var target *C.char
+    var timeout C.double
+    if C.PyArg_ParseTuple_take_snapshot(args, &target, &timeout) == 0 {
+        return nil
+    }
+    info, err := TakeSnapshot(C.GoString(target), time.Duration(float64(timeout) * float64(time.Second)))
+    return jsonResult("take_snapshot", info, err)
+}
+
+//export list_snapshots
+func list_snapshots(self *C.PyObject, args *C.PyObject) *C.PyObject {
+    var target *C.char
+    if C.PyArg_ParseTuple_get_status(args, &target) == 0 {
+        return nil
+    }
+    snapshots, err := ListSnapshots(C.GoString(target))
+    return jsonResult("list_snapshots", snapshots, err)
+}
+
+//export delete_snapshot
+func delete_snapshot(self *C.PyObject, args *C.PyObject) *C.PyObject {
+    var target *C.char
+    var id *C.char
+    if C.PyArg_ParseTuple_delete_snapshot(args, &target, &id) == 0 {
+        return nil
+    }
+    if err := DeleteSnapshot(C.GoString(target), C.GoString(id)); err != nil {
+        return jsonResult("delete_snapshot", "", err)
+    }
+    C.Py_IncRef(C.Py_None);
+    return C.Py_None;
+}
+
+//export pin_snapshot
+func pin_snapshot(self *C.PyObject, args *C.PyObject) *C.PyObject {
+    var target *C.char
+    var id *C.char
+    pinned := C.int(1)
+    if C.PyArg_ParseTuple_pin_snapshot(args, &target, &id, &pinned) == 0 {
+        return nil
+    }
+    info, err := PinSnapshot(C.GoString(target), C.GoString(id), pinned != 0)
+    return jsonResult("pin_snapshot", info, err)
+}
+
+// jsonResult returns the Python objects of the JSON content, or raises err from the function name
+func jsonResult(name string, content string, err error) *C.PyObject {
+    if err != nil {
+        cerr := C.CString(fmt.Sprintf("Error from %s: %v", name, err))
+        defer C.free(unsafe.Pointer(cerr))
+        C.raise_exception(cerr)
+        return nil
+    }
+    ccontent := C.CString(content)
+    defer C.free(unsafe.Pointer(ccontent))
+    return C.json_loads(ccontent)
+}
@@ -13,6 +13,10 @@ JINA_PROTO="jina.proto"
 JINA_DIR="../../jina"
 JINA_PACKAGE="$GO_MODULE/jina-go-proto"
 
+JRAFT_PROTO="jraft.proto"
+JRAFT_DIR="jraft-go-proto"
+JRAFT_PACKAGE="$GO_MODULE/jraft-go-proto"
+
 
 cd jina/proto
 if ! $(grep -q '^option go_package = ' docarray.proto);then
@@ -39,3 +43,14 @@ protoc --go_out=${JINA_DIR} \
        --experimental_allow_proto3_optional \
        ${JINA_PROTO} 
 cd -
+
+# jraft.proto is the API of the consensus module itself, its stubs live next to it
+cd jina/serve/consensus
+protoc --go_out=${JRAFT_DIR} \
+       --go_opt=paths=source_relative \
+       --go_opt=M${JRAFT_PROTO}=${JRAFT_PACKAGE} \
+       --go-grpc_out=${JRAFT_DIR} \
+       --go-grpc_opt=paths=source_relative \
+       --experimental_allow_proto3_optional \
+       ${JRAFT_PROTO}
+cd -
@@ -0,0 +1,89 @@
+package main
+
+import (
+    "context"
+    "strings"
+    "time"
+
+    "google.golang.org/grpc"
+    "google.golang.org/protobuf/types/known/durationpb"
+    "google.golang.org/protobuf/types/known/emptypb"
+    jraftpb "jraft/jraft-go-proto"
+)
+
+// snapshotAdminTimeout bounds the snapshot admin calls, except TakeSnapshot which waits for the snapshot.
+const snapshotAdminTimeout = 10 * time.Second
+
+// TakeSnapshot asks the node at target to take a snapshot, waiting for at most timeout if positive, and returns it
+// as JSON with the field names of jraft.proto.
+func TakeSnapshot(target string, timeout time.Duration) (string, error) {
+    ctx := context.Background()
+    request := &jraftpb.TakeSnapshotRequest{}
+    if timeout > 0 {
+        var cancel context.CancelFunc
+        ctx, cancel = context.WithTimeout(ctx, timeout)
+        defer cancel()
+        request.Timeout = durationpb.New(timeout)
+    }
+    conn, err := dialAdmin(ctx, target)
+    if err != nil {
+        return "", err
+    }
+    defer conn.Close()
+    info, err := jraftpb.NewJinaRaftAdminClient(conn).TakeSnapshot(ctx, request, grpc.WaitForReady(true))
+    if err != nil {
+        return "", err
+    }
+    return marshalJSON(info)
+}
+
+// ListSnapshots returns the snapshots in the store of the node at target as a JSON array, the most recent first.
+func ListSnapshots(target string) (string, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), snapshotAdminTimeout)
+    defer cancel()
+    conn, err := dialAdmin(ctx, target)
+    if err != nil {
+        return "", err
+    }
+    defer conn.Close()
+    response, err := jraftpb.NewJinaRaftAdminClient(conn).ListSnapshots(ctx, &emptypb.Empty{}, grpc.WaitForReady(true))
+    if err != nil {
+        return "", err
+    }
+    snapshots := make([]string, len(response.Snapshots))
+    for i, info := range response.Snapshots {
+        if snapshots[i], err = marshalJSON(info); err != nil {
+            return "", err
+        }
+    }
+    return "[" + strings.Join(snapshots, ",") + "]", nil
+}
+
+// DeleteSnapshot deletes the snapshot id from the store of the node at target.
+func DeleteSnapshot(target string, id string) error {
+    ctx, cancel := context.WithTimeout(context.Background(), snapshotAdminTimeout)
+    defer cancel()
+    conn, err := dialAdmin(ctx, target)
+    if err != nil {
+        return err
+    }
+    defer conn.Close()
+    _, err = jraftpb.NewJinaRaftAdminClient(conn).DeleteSnapshot(ctx, &jraftpb.SnapshotRequest{Id: id}, grpc.WaitForReady(true))
+    return err
+}
+
+// PinSnapshot pins or unpins the snapshot id in the store of the node at target, and returns it as JSON.
+func PinSnapshot(target string, id string, pinned bool) (string, error) {
+    ctx, cancel := context.WithTimeout(context.Background(), snapshotAdminTimeout)
+    defer cancel()
+    conn, err := dialAdmin(ctx, target)
+    if err != nil {
+        return "", err
+    }
+    defer conn.Close()
+    info, err := jraftpb.NewJinaRaftAdminClient(conn).PinSnapshot(ctx, &jraftpb.PinSnapshotRequest{Id: id, Pinned: pinned}, grpc.WaitForReady(true))
+    if err != nil {
+        return "", err
+    }
+    return marshalJSON(info)
+}
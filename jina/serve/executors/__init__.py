@@ -1382,6 +1382,25 @@ class BaseExecutor(JAMLCompatible, metaclass=ExecutorType):
         """
         raise Exception('Raising an Exception. Snapshot is not enabled by default')
 
+    def capture_snapshot(self) -> Optional['BaseExecutor']:
+        """
+        Capture the state written by the snapshot method. It is called while no write runs, and the writes resume as
+        soon as it returns, while the snapshot method of the returned Executor writes the snapshot file.
+        By default, the returned Executor is a copy whose attributes are deep copies of the attributes of this one,
+        except the attributes that cannot be copied (locks, loggers, clients...), which are shared. Override it to
+        share the parts of the state that writes never modify in place, such as the weights of a model, rather than
+        copying them, or return None to hold the writes until the snapshot method returns.
+        :return: the Executor holding the frozen state to snapshot, or None
+        """
+        frozen = object.__new__(type(self))
+        memo = {}
+        for name, value in vars(self).items():
+            try:
+                frozen.__dict__[name] = copy.deepcopy(value, memo)
+            except Exception:
+                frozen.__dict__[name] = value
+        return frozen
+
     def restore(self, snapshot_file: str):
         """
         Interface to restore the state of the Executor from a snapshot that has been taken by the snapshot method.
@@ -1389,7 +1408,7 @@ class BaseExecutor(JAMLCompatible, metaclass=ExecutorType):
         """
         pass
 
-    def _run_snapshot(self, snapshot_file: str, did_raise_exception):
+    def _run_snapshot(self, snapshot_file: str, did_raise_exception, fenced=None):
         try:
             from pathlib import Path
 
@@ -1397,10 +1416,24 @@ class BaseExecutor(JAMLCompatible, metaclass=ExecutorType):
             p.parent.mkdir(parents=True, exist_ok=True)
             p.touch()
             with self._write_lock:
-                self.snapshot(snapshot_file)
+                # no write is running, the captured state holds the writes received so far
+                frozen = self.capture_snapshot()
+                if frozen is None:
+                    # the writes wait for the whole snapshot
+                    if fenced:
+                        fenced.set()
+                    self.snapshot(snapshot_file)
+            if fenced:
+                fenced.set()
+            if frozen is not None:
+                # the writes resume while the frozen state is written
+                frozen.snapshot(snapshot_file)
         except:
             did_raise_exception.set()
             raise
+        finally:
+            if fenced:
+                fenced.set()
 
     def _run_restore(self, snapshot_file: str, did_raise_exception):
         try:
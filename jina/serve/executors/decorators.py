@@ -1,5 +1,6 @@
 """Decorators and wrappers designed for wrapping :class:`BaseExecutor` functions. """
 
+import asyncio
 import functools
 import inspect
 import os
@@ -169,8 +170,21 @@ def write(
                 async def arg_wrapper(
                     executor_instance, *args, **kwargs
                 ):  # we need to get the summary from the executor, so we need to access the self
-                    with executor_instance._write_lock:
+                    # the lock is acquired out of the event loop, which keeps serving while a snapshot holds it
+                    write_lock = executor_instance._write_lock
+                    acquiring = asyncio.get_running_loop().run_in_executor(
+                        None, write_lock.acquire
+                    )
+                    try:
+                        await asyncio.shield(acquiring)
+                    except asyncio.CancelledError:
+                        # the pool thread still gets the lock, release it as soon as it does
+                        acquiring.add_done_callback(lambda _: write_lock.release())
+                        raise
+                    try:
                         return await fn(executor_instance, *args, **kwargs)
+                    finally:
+                        executor_instance._write_lock.release()
 
                 self.fn = arg_wrapper
             else:
@@ -13,6 +13,52 @@ if TYPE_CHECKING:
     import threading
 
 
+def _raft_executor_socket(args: 'argparse.Namespace') -> Optional[str]:
+    """Return the path of the unix socket where the Executor of a stateful replica serves its RAFT node, when
+    `executor_socket` is set in the `raft_configuration`. The path is formatted with the `replica_id`, `shard_id`
+    and `port` of the replica, so that the replicas running on the same host use their own socket.
+
+    :param args: namespace args from the Pod
+    :return: the path of the unix socket, or None when the RAFT node reaches the Executor over TCP
+    """
+    raft_configuration = args.raft_configuration or {}
+    path = raft_configuration.get(
+        'executor_socket', raft_configuration.get('ExecutorSocket')
+    )
+    if not path:
+        return None
+    port = args.port[0] if isinstance(args.port, list) else args.port
+    return path.format(replica_id=args.replica_id, shard_id=args.shard_id, port=port)
+
+
+def _raft_configured_settings(raft_configuration: Dict) -> set:
+    """Return the settings of the RAFT node set in the `raft_configuration` or in its `config` file, named as the
+    node matches them: lowercase, without underscores.
+
+    :param raft_configuration: the RAFT configuration passed to the node
+    :return: the names of the configured settings
+    """
+
+    def normalize_setting_name(name):
+        return name.replace('_', '').lower()
+
+    configured = {normalize_setting_name(key) for key in raft_configuration}
+    config_file = raft_configuration.get('config')
+    if config_file:
+        import yaml
+
+        try:
+            # JSON is read as a subset of YAML, like the node does
+            with open(config_file) as fp:
+                settings = yaml.safe_load(fp) or {}
+        except (OSError, yaml.YAMLError):
+            # the node reports the unreadable file when it starts
+            settings = {}
+        if isinstance(settings, dict):
+            configured |= {normalize_setting_name(str(key)) for key in settings}
+    return configured
+
+
 def run_raft(
     args: 'argparse.Namespace',
     is_ready: Union['multiprocessing.Event', 'threading.Event'],
@@ -43,22 +89,48 @@ def run_raft(
         workspace=args.workspace, name='raft', shard_id=shard_id
     )
 
+    def join_host_port(host, port):
+        # IPv6 hosts need brackets to be separated from the port
+        if ':' in host and not host.startswith('['):
+            host = f'[{host}]'
+        return f'{host}:{port}'
+
     port = args.port[0] if isinstance(args.port, list) else args.port
-    address = f'{args.host}:{port}'
-    executor_target = f'{args.host}:{port + RAFT_TO_EXECUTOR_PORT}'
+    address = join_host_port(args.host, port)
+    executor_target = join_host_port(args.host, port + RAFT_TO_EXECUTOR_PORT)
 
-    # if the Executor was already persisted, retrieve its port and host configuration
+    # the RAFT node listens on the current address, if it differs from the one persisted in the RAFT
+    # configuration, the node updates its address in the cluster itself once started
     logger = JinaLogger(context=f'RAFT-{args.name}', **vars(args))
     persisted_address = jraft.get_configuration(raft_id, raft_dir)
-    if persisted_address:
-        logger.debug(f'Configuration found on the node: Address {persisted_address}')
-        address = persisted_address
-        executor_host, port = persisted_address.split(':')
-        executor_target = f'{executor_host}:{int(port) + 1}'
+    if persisted_address and persisted_address != address:
+        logger.debug(
+            f'Configuration found on the node with Address {persisted_address}, it will be updated to {address}'
+        )
 
     raft_configuration = pascal_case_dict(args.raft_configuration or {})
-    log_level = raft_configuration.get('LogLevel', os.getenv('JINA_LOG_LEVEL', 'INFO'))
-    raft_configuration['LogLevel'] = log_level
+    executor_socket = _raft_executor_socket(args)
+    if executor_socket:
+        # the Executor listens on the socket as well, see `GRPCServer.setup_server`
+        raft_configuration['ExecutorSocket'] = executor_socket
+    # settings can also come from a YAML/JSON file given as `config` and from JINA_RAFT_* env variables,
+    # only fall back to the Jina log level if the RAFT log level is not configured anywhere, since the keyword
+    # arguments override both
+    if (
+        'JINA_RAFT_LOG_LEVEL' not in os.environ
+        and 'loglevel' not in _raft_configured_settings(raft_configuration)
+    ):
+        raft_configuration['LogLevel'] = os.getenv('JINA_LOG_LEVEL', 'INFO')
+    # export the RAFT spans to the collector of the Executor, so that the writes are traced end to end
+    if (
+        getattr(args, 'tracing', False)
+        and args.traces_exporter_host
+        and 'JINA_RAFT_TRACING_ENDPOINT' not in os.environ
+    ):
+        raft_configuration.setdefault(
+            'TracingEndpoint',
+            join_host_port(args.traces_exporter_host, args.traces_exporter_port),
+        )
     is_ready.wait()
     logger.debug(f'Will run the RAFT node with RAFT configuration {raft_configuration}')
     jraft.run(
@@ -204,6 +276,7 @@ def run_stateful(
 
     from jina.constants import RAFT_TO_EXECUTOR_PORT
 
+    cargs.executor_socket = _raft_executor_socket(args)
     if isinstance(cargs.port, int):
         cargs.port += RAFT_TO_EXECUTOR_PORT
     elif isinstance(cargs.port, list):
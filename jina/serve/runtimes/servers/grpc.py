@@ -93,10 +93,18 @@ class GRPCServer(BaseServer):
             jina_pb2_grpc.add_JinaExecutorSnapshotProgressServicer_to_server(
                 self._request_handler, self.server
             )
+        if hasattr(self._request_handler, 'read_snapshot'):
+            jina_pb2_grpc.add_JinaExecutorSnapshotReadServicer_to_server(
+                self._request_handler, self.server
+            )
         if hasattr(self._request_handler, 'restore'):
             jina_pb2_grpc.add_JinaExecutorRestoreServicer_to_server(
                 self._request_handler, self.server
             )
+        if hasattr(self._request_handler, 'restore_stream'):
+            jina_pb2_grpc.add_JinaExecutorRestoreStreamServicer_to_server(
+                self._request_handler, self.server
+            )
         if hasattr(self._request_handler, 'restore_status'):
             jina_pb2_grpc.add_JinaExecutorRestoreProgressServicer_to_server(
                 self._request_handler, self.server
@@ -124,6 +132,11 @@ class GRPCServer(BaseServer):
         reflection.enable_server_reflection(service_names, self.server)
 
         bind_addr = f'{self.host}:{self.port}'
+        bind_addrs = [bind_addr]
+        # the Executor of a stateful replica also serves its RAFT node on a unix socket, if configured
+        executor_socket = getattr(self.runtime_args, 'executor_socket', None)
+        if executor_socket:
+            bind_addrs.append(f'unix:{executor_socket}')
 
         if self.ssl_keyfile and self.ssl_certfile:
             with open(self.ssl_keyfile, 'rb') as f:
@@ -139,7 +152,8 @@ class GRPCServer(BaseServer):
                     ),
                 )
             )
-            self.server.add_secure_port(bind_addr, server_credentials)
+            for addr in bind_addrs:
+                self.server.add_secure_port(addr, server_credentials)
         elif (
             self.ssl_keyfile != self.ssl_certfile
         ):  # if we have only ssl_keyfile and not ssl_certfile or vice versa
@@ -147,8 +161,9 @@ class GRPCServer(BaseServer):
                 f"you can't pass a ssl_keyfile without a ssl_certfile and vice versa"
             )
         else:
-            self.server.add_insecure_port(bind_addr)
-        self.logger.info(f'start server bound to {bind_addr}')
+            for addr in bind_addrs:
+                self.server.add_insecure_port(addr)
+        self.logger.info(f'start server bound to {", ".join(bind_addrs)}')
         await self.server.start()
         self.logger.debug(f'server bound to {bind_addr} started')
         for service in service_names:
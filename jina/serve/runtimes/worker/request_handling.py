@@ -1,12 +1,15 @@
 import argparse
 import asyncio
 import functools
+import hashlib
 import json
 import os
+import shutil
 import tempfile
 import threading
 import uuid
 import warnings
+import zlib
 from typing import (
     TYPE_CHECKING,
     AsyncIterator,
@@ -44,6 +47,9 @@ if TYPE_CHECKING:  # pragma: no cover
     from jina.logging.logger import JinaLogger
     from jina.types.request import Request
 
+# size of the chunks in which a snapshot file is streamed by `read_snapshot`
+_SNAPSHOT_CHUNK_SIZE = 1024 * 1024
+
 
 class WorkerRequestHandler:
     """Object to encapsulate the code related to handle the data requests passing to executor and its returned values"""
@@ -1230,11 +1236,19 @@ class WorkerRequestHandler:
                 self._snapshot_parent_directory,
             )
             self._did_snapshot_raise_exception = threading.Event()
+            fenced = threading.Event()
             self._snapshot_thread = threading.Thread(
                 target=self._executor._run_snapshot,
-                args=(self._snapshot.snapshot_file, self._did_snapshot_raise_exception),
+                args=(
+                    self._snapshot.snapshot_file,
+                    self._did_snapshot_raise_exception,
+                    fenced,
+                ),
             )
             self._snapshot_thread.start()
+            # answer once the Executor captured its state, so that the snapshot contains exactly the writes
+            # received before this call, and the caller can send the next ones while it is written
+            await asyncio.get_running_loop().run_in_executor(None, fenced.wait)
             return self._snapshot
 
     async def snapshot_status(
@@ -1275,6 +1289,41 @@ class WorkerRequestHandler:
             status=jina_pb2.SnapshotStatusProto.Status.NOT_FOUND,
         )
 
+    async def read_snapshot(
+        self, request: 'jina_pb2.SnapshotId', context
+    ) -> AsyncIterator['jina_pb2.SnapshotChunkProto']:
+        """
+        method to stream the file of a finished snapshot of the Executor, so that the caller
+        does not need to share its file system. The file is removed once fully sent.
+        :param request: the snapshot Id of the file to read
+        :param context: grpc context
+
+        :yield: the chunks of the snapshot file, the last one holding the SHA-256 digest
+        """
+        self.logger.debug(f'Reading snapshot with ID {request.value}')
+        if not self._snapshot or (self._snapshot.id.value != request.value):
+            raise RuntimeError(f'Snapshot with id {request.value} not found.')
+        if self._snapshot_thread and self._snapshot_thread.is_alive():
+            raise RuntimeError(
+                f'Snapshot with id {request.value} is still in progress. Cannot read it.'
+            )
+        snapshot_file = self._snapshot.snapshot_file
+        loop = asyncio.get_running_loop()
+        digest = hashlib.sha256()
+        offset = 0
+        with open(snapshot_file, 'rb') as f:
+            while True:
+                data = await loop.run_in_executor(None, f.read, _SNAPSHOT_CHUNK_SIZE)
+                if not data:
+                    break
+                digest.update(data)
+                yield jina_pb2.SnapshotChunkProto(
+                    data=data, offset=offset, crc32=zlib.crc32(data)
+                )
+                offset += len(data)
+        yield jina_pb2.SnapshotChunkProto(offset=offset, sha256=digest.digest())
+        shutil.rmtree(os.path.dirname(snapshot_file), ignore_errors=True)
+
     async def restore(self, request: 'jina_pb2.RestoreSnapshotCommand', context):
         """
         method to start a restore process of the Executor
@@ -1299,6 +1348,70 @@ class WorkerRequestHandler:
             self._restore_thread.start()
         return self._restore
 
+    async def restore_stream(
+        self, request_iterator, context
+    ) -> 'jina_pb2.RestoreSnapshotStatusProto':
+        """
+        method to start a restore process of the Executor from a snapshot streamed in
+        chunks, so that the caller does not need to share its file system. The chunks are
+        checked and written to a file, restored once the digest of the last chunk matches.
+        :param request_iterator: the chunks of the snapshot, the last one holding the SHA-256 digest
+        :param context: grpc context
+
+        :return: the status of the restore
+        """
+        self.logger.debug(f'Calling restore_stream')
+        if self._restore and self._restore_thread and self._restore_thread.is_alive():
+            raise RuntimeError(
+                f'A restore with id {self._restore.id.value} is currently in progress. Cannot start another.'
+            )
+        # the caller waits for the headers to know that the stream is supported before sending the snapshot
+        await context.send_initial_metadata(())
+        fd, snapshot_file = tempfile.mkstemp(
+            suffix='.bin', dir=self._snapshot_parent_directory
+        )
+        loop = asyncio.get_running_loop()
+        digest = hashlib.sha256()
+        offset = 0
+        try:
+            with os.fdopen(fd, 'wb') as f:
+                async for chunk in request_iterator:
+                    if chunk.offset != offset:
+                        raise RuntimeError(
+                            f'Snapshot chunk at offset {chunk.offset}, expected offset {offset}'
+                        )
+                    if chunk.sha256:
+                        if chunk.sha256 != digest.digest():
+                            raise RuntimeError(
+                                f'Snapshot digest mismatch after {offset} bytes'
+                            )
+                        break
+                    if zlib.crc32(chunk.data) != chunk.crc32:
+                        raise RuntimeError(
+                            f'Snapshot checksum mismatch in the chunk at offset {offset}'
+                        )
+                    await loop.run_in_executor(None, f.write, chunk.data)
+                    digest.update(chunk.data)
+                    offset += len(chunk.data)
+                else:
+                    raise RuntimeError(
+                        f'Snapshot stream ended after {offset} bytes without its digest'
+                    )
+        except BaseException:
+            os.remove(snapshot_file)
+            raise
+        self.logger.debug(f'Received a snapshot of {offset} bytes to restore')
+        self._restore = self._create_restore_status()
+        self._did_restore_raise_exception = threading.Event()
+
+        # the snapshot file is removed by the Executor once restored
+        self._restore_thread = threading.Thread(
+            target=self._executor._run_restore,
+            args=(snapshot_file, self._did_restore_raise_exception),
+        )
+        self._restore_thread.start()
+        return self._restore
+
     async def restore_status(
         self, request, context
     ) -> 'jina_pb2.RestoreSnapshotStatusProto':
@@ -0,0 +1,190 @@
+import time
+from concurrent import futures
+
+import grpc
+import pytest
+from google.protobuf import any_pb2, duration_pb2
+from google.rpc import code_pb2, error_details_pb2, status_pb2
+from grpc_health.v1 import health, health_pb2, health_pb2_grpc
+from grpc_status import rpc_status
+
+from jina.helper import random_port
+from jina.proto import jina_pb2, jina_pb2_grpc
+from jina.serve.consensus.client import LEADER_SERVICE, RaftClient, _retry_delay
+
+
+def _node_status(code, reason, retry_after=None, domain='jraft.jina.ai'):
+    details = []
+    info = any_pb2.Any()
+    info.Pack(error_details_pb2.ErrorInfo(reason=reason, domain=domain))
+    details.append(info)
+    if retry_after is not None:
+        retry = any_pb2.Any()
+        retry.Pack(
+            error_details_pb2.RetryInfo(
+                retry_delay=duration_pb2.Duration(nanos=int(retry_after * 1e9))
+            )
+        )
+        details.append(retry)
+    return status_pb2.Status(code=code, message=reason, details=details)
+
+
+NOT_LEADER = _node_status(code_pb2.FAILED_PRECONDITION, 'NOT_LEADER', 0)
+NO_LEADER = _node_status(code_pb2.UNAVAILABLE, 'NO_LEADER', 0.01)
+DRAINING = _node_status(code_pb2.UNAVAILABLE, 'DRAINING', 0.01)
+SHUTDOWN = _node_status(code_pb2.UNAVAILABLE, 'SHUTDOWN')
+ENQUEUE_TIMEOUT = _node_status(code_pb2.RESOURCE_EXHAUSTED, 'ENQUEUE_TIMEOUT', 0.01)
+LEADERSHIP_LOST = _node_status(code_pb2.ABORTED, 'LEADERSHIP_LOST')
+OTHER_DOMAIN = _node_status(code_pb2.UNAVAILABLE, 'NO_LEADER', 0, domain='example.com')
+NO_DETAILS = status_pb2.Status(code=code_pb2.UNAVAILABLE, message='connection reset')
+
+
+class ScriptedLeader(jina_pb2_grpc.JinaSingleDataRequestRPCServicer):
+    """Fail the writes with the given statuses, in order, then echo them."""
+
+    def __init__(self, statuses):
+        self.statuses = list(statuses)
+        self.calls = 0
+
+    def process_single_data(self, request, context):
+        self.calls += 1
+        if self.statuses:
+            context.abort_with_status(rpc_status.to_status(self.statuses.pop(0)))
+        return request
+
+
+@pytest.fixture
+def leader():
+    servers = []
+
+    def _start(statuses):
+        port = random_port()
+        servicer = ScriptedLeader(statuses)
+        server = grpc.server(futures.ThreadPoolExecutor(max_workers=4))
+        jina_pb2_grpc.add_JinaSingleDataRequestRPCServicer_to_server(servicer, server)
+        health_servicer = health.HealthServicer()
+        health_servicer.set(LEADER_SERVICE, health_pb2.HealthCheckResponse.SERVING)
+        health_pb2_grpc.add_HealthServicer_to_server(health_servicer, server)
+        server.add_insecure_port(f'localhost:{port}')
+        server.start()
+        servers.append(server)
+        return f'localhost:{port}', servicer
+
+    yield _start
+    for server in servers:
+        server.stop(None)
+
+
+def _write_request():
+    request = jina_pb2.DataRequestProto()
+    request.header.request_id = 'r1'
+    request.header.exec_endpoint = '/index'
+    return request
+
+
+@pytest.mark.asyncio
+@pytest.mark.parametrize(
+    'statuses',
+    [
+        [NOT_LEADER],
+        [NO_LEADER, DRAINING],
+        [SHUTDOWN, ENQUEUE_TIMEOUT, NOT_LEADER, NO_LEADER],
+    ],
+)
+async def test_write_retried_until_appended(leader, statuses):
+    target, servicer = leader(statuses)
+    async with RaftClient(
+        [target], write_endpoints=['/index'], write_attempts=5, write_backoff=0.01
+    ) as client:
+        response = await client.process_single_data(_write_request(), timeout=10)
+    assert response.header.request_id == 'r1'
+    assert servicer.calls == len(statuses) + 1
+
+
+@pytest.mark.asyncio
+@pytest.mark.parametrize(
+    'status, code',
+    [
+        (LEADERSHIP_LOST, grpc.StatusCode.ABORTED),
+        (OTHER_DOMAIN, grpc.StatusCode.UNAVAILABLE),
+        (NO_DETAILS, grpc.StatusCode.UNAVAILABLE),
+    ],
+)
+async def test_write_with_unknown_outcome_not_retried(leader, status, code):
+    target, servicer = leader([status])
+    async with RaftClient(
+        [target], write_endpoints=['/index'], write_attempts=5, write_backoff=0.01
+    ) as client:
+        with pytest.raises(grpc.aio.AioRpcError) as err:
+            await client.process_single_data(_write_request(), timeout=10)
+    assert err.value.code() == code
+    assert servicer.calls == 1
+
+
+@pytest.mark.asyncio
+async def test_write_attempts_exhausted(leader):
+    target, servicer = leader([NO_LEADER] * 3)
+    async with RaftClient(
+        [target], write_endpoints=['/index'], write_attempts=2, write_backoff=0.01
+    ) as client:
+        with pytest.raises(grpc.aio.AioRpcError) as err:
+            await client.process_single_data(_write_request(), timeout=10)
+    assert err.value.code() == grpc.StatusCode.UNAVAILABLE
+    assert servicer.calls == 2
+
+
+@pytest.mark.asyncio
+async def test_write_honors_retry_info(leader):
+    target, servicer = leader([_node_status(code_pb2.UNAVAILABLE, 'NO_LEADER', 0.5)])
+    async with RaftClient(
+        [target], write_endpoints=['/index'], write_attempts=5, write_backoff=0.001
+    ) as client:
+        start = time.monotonic()
+        await client.process_single_data(_write_request(), timeout=10)
+        elapsed = time.monotonic() - start
+    assert servicer.calls == 2
+    assert elapsed >= 0.5
+
+
+@pytest.mark.asyncio
+async def test_write_retries_share_the_deadline(leader):
+    target, servicer = leader([_node_status(code_pb2.UNAVAILABLE, 'NO_LEADER', 5)])
+    async with RaftClient(
+        [target], write_endpoints=['/index'], write_attempts=5, write_backoff=0.01
+    ) as client:
+        start = time.monotonic()
+        with pytest.raises(grpc.aio.AioRpcError) as err:
+            await client.process_single_data(_write_request(), timeout=1)
+        elapsed = time.monotonic() - start
+    # the retry would end after the deadline, the error is raised right away
+    assert err.value.code() == grpc.StatusCode.UNAVAILABLE
+    assert servicer.calls == 1
+    assert elapsed < 1
+
+
+@pytest.mark.parametrize(
+    'status, expected',
+    [
+        (NOT_LEADER, (True, 0)),
+        (NO_LEADER, (True, 0.01)),
+        (SHUTDOWN, (True, None)),
+        (LEADERSHIP_LOST, (False, None)),
+        (OTHER_DOMAIN, (False, 0)),
+        (NO_DETAILS, (False, None)),
+    ],
+)
+def test_retry_delay(status, expected):
+    class _Error(grpc.RpcError):
+        # the parts of grpc.Call read by rpc_status.from_call
+        def code(self):
+            return next(c for c in grpc.StatusCode if c.value[0] == status.code)
+
+        def details(self):
+            return status.message
+
+        def trailing_metadata(self):
+            if not status.details:
+                return ()
+            return (('grpc-status-details-bin', status.SerializeToString()),)
+
+    assert _retry_delay(_Error()) == expected
@@ -0,0 +1,221 @@
+import hashlib
+import multiprocessing
+import time
+import zlib
+from multiprocessing import Process
+from threading import Event
+
+import grpc
+import pytest
+from google.protobuf import empty_pb2
+
+from jina import Executor
+from jina.proto import jina_pb2, jina_pb2_grpc
+from jina.serve.runtimes.asyncio import AsyncNewLoopRuntime
+from jina.serve.runtimes.servers import BaseServer
+from jina.serve.runtimes.worker.request_handling import WorkerRequestHandler
+from tests.helper import _generate_pod_args
+
+# larger than the chunks of read_snapshot, so that it is streamed in several chunks
+INITIAL_STATE = bytes(range(256)) * 10000
+CHUNK_SIZE = 1024 * 1024
+
+
+class BytesStateExecutor(Executor):
+    def __init__(self, *args, **kwargs):
+        super().__init__(*args, **kwargs)
+        self._state = INITIAL_STATE
+
+    def snapshot(self, snapshot_file):
+        with open(snapshot_file, 'wb') as f:
+            f.write(self._state)
+
+    def restore(self, snapshot_file):
+        with open(snapshot_file, 'rb') as f:
+            self._state = f.read()
+
+
+def _start_runtime(args, cancel_event):
+    with AsyncNewLoopRuntime(
+        args, cancel_event=cancel_event, req_handler_cls=WorkerRequestHandler
+    ) as runtime:
+        runtime.run_forever()
+
+
+@pytest.fixture
+def worker():
+    args = _generate_pod_args(['--uses', 'BytesStateExecutor'])
+    cancel_event = multiprocessing.Event()
+    process = Process(target=_start_runtime, args=(args, cancel_event), daemon=True)
+    process.start()
+    target = f'{args.host}:{args.port[0]}'
+    assert BaseServer.wait_for_ready_or_shutdown(
+        timeout=5.0, ctrl_address=target, ready_or_shutdown_event=Event()
+    )
+    with grpc.insecure_channel(target) as channel:
+        yield channel
+    cancel_event.set()
+    process.join()
+
+
+def _wait(poll, succeeded, failed, timeout=5.0):
+    deadline = time.monotonic() + timeout
+    while time.monotonic() < deadline:
+        status = poll().status
+        if status == succeeded:
+            return
+        assert status not in failed
+        time.sleep(0.05)
+    raise TimeoutError('the operation did not finish in time')
+
+
+def _take_snapshot(channel):
+    status = jina_pb2_grpc.JinaExecutorSnapshotStub(channel).snapshot(
+        empty_pb2.Empty()
+    )
+    progress = jina_pb2_grpc.JinaExecutorSnapshotProgressStub(channel)
+    _wait(
+        lambda: progress.snapshot_status(status.id),
+        jina_pb2.SnapshotStatusProto.Status.SUCCEEDED,
+        (
+            jina_pb2.SnapshotStatusProto.Status.FAILED,
+            jina_pb2.SnapshotStatusProto.Status.NOT_FOUND,
+        ),
+    )
+    return status.id
+
+
+def _read_snapshot(channel, snapshot_id):
+    return list(
+        jina_pb2_grpc.JinaExecutorSnapshotReadStub(channel).read_snapshot(snapshot_id)
+    )
+
+
+@pytest.mark.timeout(30)
+def test_read_snapshot(worker):
+    snapshot_id = _take_snapshot(worker)
+    chunks = _read_snapshot(worker, snapshot_id)
+
+    *data_chunks, last = chunks
+    assert len(data_chunks) == -(-len(INITIAL_STATE) // CHUNK_SIZE)
+    offset = 0
+    for chunk in data_chunks:
+        assert chunk.offset == offset
+        assert chunk.crc32 == zlib.crc32(chunk.data)
+        assert not chunk.sha256
+        offset += len(chunk.data)
+    assert b''.join(chunk.data for chunk in data_chunks) == INITIAL_STATE
+    assert not last.data
+    assert last.offset == len(INITIAL_STATE)
+    assert last.sha256 == hashlib.sha256(INITIAL_STATE).digest()
+
+    # the file is removed once fully sent
+    with pytest.raises(grpc.RpcError):
+        _read_snapshot(worker, snapshot_id)
+
+
+@pytest.mark.timeout(30)
+def test_read_unknown_snapshot(worker):
+    _take_snapshot(worker)
+    with pytest.raises(grpc.RpcError):
+        _read_snapshot(worker, jina_pb2.SnapshotId(value='unknown'))
+
+
+def _state(channel):
+    chunks = _read_snapshot(channel, _take_snapshot(channel))
+    return b''.join(chunk.data for chunk in chunks)
+
+
+def _chunks(data):
+    chunks = []
+    for offset in range(0, len(data), CHUNK_SIZE):
+        chunk = data[offset : offset + CHUNK_SIZE]
+        chunks.append(
+            jina_pb2.SnapshotChunkProto(
+                data=chunk, offset=offset, crc32=zlib.crc32(chunk)
+            )
+        )
+    chunks.append(
+        jina_pb2.SnapshotChunkProto(
+            offset=len(data), sha256=hashlib.sha256(data).digest()
+        )
+    )
+    return chunks
+
+
+def _restore(channel, chunks):
+    status = jina_pb2_grpc.JinaExecutorRestoreStreamStub(channel).restore_stream(
+        iter(chunks)
+    )
+    progress = jina_pb2_grpc.JinaExecutorRestoreProgressStub(channel)
+    _wait(
+        lambda: progress.restore_status(status.id),
+        jina_pb2.RestoreSnapshotStatusProto.Status.SUCCEEDED,
+        (
+            jina_pb2.RestoreSnapshotStatusProto.Status.FAILED,
+            jina_pb2.RestoreSnapshotStatusProto.Status.NOT_FOUND,
+        ),
+    )
+
+
+@pytest.mark.timeout(30)
+def test_restore_stream(worker):
+    new_state = bytes(reversed(INITIAL_STATE))
+    _restore(worker, _chunks(new_state))
+    assert _state(worker) == new_state
+
+    # a snapshot streamed back by read_snapshot is restored as is
+    snapshot_id = _take_snapshot(worker)
+    _restore(worker, _read_snapshot(worker, snapshot_id))
+    assert _state(worker) == new_state
+
+
+def _wrong_offset(chunks):
+    chunks[1].offset += 1
+    return chunks
+
+
+def _wrong_checksum(chunks):
+    chunks[1].crc32 ^= 1
+    return chunks
+
+
+def _wrong_digest(chunks):
+    chunks[-1].sha256 = hashlib.sha256(b'another snapshot').digest()
+    return chunks
+
+
+def _missing_digest(chunks):
+    return chunks[:-1]
+
+
+def _reordered(chunks):
+    chunks[0], chunks[1] = chunks[1], chunks[0]
+    return chunks
+
+
+def _dropped_chunk(chunks):
+    del chunks[1]
+    return chunks
+
+
+@pytest.mark.timeout(30)
+@pytest.mark.parametrize(
+    'corrupt',
+    [
+        _wrong_offset,
+        _wrong_checksum,
+        _wrong_digest,
+        _missing_digest,
+        _reordered,
+        _dropped_chunk,
+    ],
+)
+def test_restore_stream_rejects_corrupted_snapshot(worker, corrupt):
+    chunks = corrupt(_chunks(bytes(reversed(INITIAL_STATE))))
+    with pytest.raises(grpc.RpcError):
+        jina_pb2_grpc.JinaExecutorRestoreStreamStub(worker).restore_stream(
+            iter(chunks)
+        )
+    # the Executor never sees the corrupted snapshot
+    assert _state(worker) == INITIAL_STATE
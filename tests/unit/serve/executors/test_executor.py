@@ -705,3 +705,27 @@ def test_write_decorator():
 
     exec = WriteExecutor()
     assert set(exec.write_endpoints) == {'/index', '/update', '/delete', '/bar'}
+
+
+@pytest.mark.asyncio
+async def test_write_cancelled_while_waiting_for_the_lock():
+    class WriteExecutor(Executor):
+        @requests(on='/index')
+        @write
+        async def index(self, **kwargs):
+            pass
+
+    exec = WriteExecutor()
+    # a snapshot holds the lock
+    exec._write_lock.acquire()
+    task = asyncio.create_task(exec.index())
+    await asyncio.sleep(0.1)
+    task.cancel()
+    with pytest.raises(asyncio.CancelledError):
+        await task
+    exec._write_lock.release()
+
+    # the lock taken for the cancelled write is released, the next write and snapshot go through
+    await asyncio.wait_for(exec.index(), timeout=5)
+    assert exec._write_lock.acquire(timeout=5)
+    exec._write_lock.release()